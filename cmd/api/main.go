@@ -21,6 +21,7 @@ import (
 	"github.com/Neroframe/crypto-tracker/config"
 	"github.com/Neroframe/crypto-tracker/internal/app"
 	httpdelivery "github.com/Neroframe/crypto-tracker/internal/delivery/http"
+	"github.com/Neroframe/crypto-tracker/internal/domain"
 	ext "github.com/Neroframe/crypto-tracker/internal/infra/external"
 	pgrepo "github.com/Neroframe/crypto-tracker/internal/infra/postgres"
 	"github.com/Neroframe/crypto-tracker/pkg/logger"
@@ -72,16 +73,42 @@ func main() {
 		nil,
 		cfg.External.CoinPaprikaURL,
 		cfg.External.RateLimit,
+		cfg.External.Quotes,
 		log,
 	)
 	if err != nil {
 		log.Fatal("failed to init CoinPaprika client", "error", err)
 	}
 
+	// Retention tiers
+	retention := domain.RetentionPolicy{Raw: cfg.Retention.Raw}
+	for _, t := range cfg.Retention.Rollups {
+		retention.Rollups = append(retention.Rollups, domain.RetentionTier{Resolution: t.Resolution, Keep: t.Keep})
+	}
+	if err := retention.Validate(); err != nil {
+		log.Fatal("invalid retention config", "error", err)
+	}
+
 	// Wire up
 	validate := validator.New() // init validator
+	if err := httpdelivery.RegisterValidations(validate); err != nil {
+		log.Fatal("failed to register validations", "error", err)
+	}
 	repo := pgrepo.NewGormRepo(gormDB, log)
-	svc := app.NewCryptoService(repo, cpClient, log)
+	svc := app.NewCryptoService(repo, cpClient, log, app.Config{
+		FetchInterval:    cfg.External.FetchInterval,
+		MaxPriceDistance: cfg.External.MaxPriceDistance,
+		Retention:        retention,
+		Partitions: app.PartitionConfig{
+			Ahead:      cfg.Partitions.Ahead,
+			Retain:     cfg.Partitions.Retain,
+			DetachOnly: cfg.Partitions.DetachOnly,
+		},
+		Backfill: app.BackfillConfig{
+			Lookback: cfg.Backfill.Lookback,
+			Interval: cfg.Backfill.Interval,
+		},
+	})
 	handler := httpdelivery.NewCryptoHandler(validate, log, svc)
 
 	// Build Gin router
@@ -98,8 +125,18 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	// Start worker
-	go startScheduler(ctx, svc, cfg.External.FetchInterval, log)
+	// Currencies from before provider IDs were stored need theirs before the first fetch
+	if err := svc.ResolveLegacyCoins(ctx); err != nil {
+		log.Error("resolve legacy coin IDs failed", "error", err)
+	}
+
+	// Start workers
+	go svc.RunScheduler(ctx, app.SystemClock)
+	go startMaintenance(ctx, svc, cfg.Partitions.Interval, log)
+	go svc.RunBackfills(ctx)
+	if retention.Enabled() {
+		go startRetention(ctx, svc, cfg.Retention.Interval, log)
+	}
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -130,49 +167,52 @@ func main() {
 	}
 }
 
-// Runs a price fetch with configured interval
-func startScheduler(
+// Rolls up and prunes old prices with configured interval
+func startRetention(
 	ctx context.Context,
 	svc app.CryptoService,
 	interval time.Duration,
 	log *logger.Logger,
 ) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
 	for {
-		// Check for shutdown before starting
+		log.Debug("retention: starting ApplyRetention")
+		if err := svc.ApplyRetention(ctx); err != nil {
+			log.Error("retention error", "error", err)
+		}
+
+		// Wait interval before next run or exit if shutting down
 		select {
 		case <-ctx.Done():
-			log.Info("scheduler: received shutdown signal, stopping")
+			log.Info("retention: received shutdown signal, stopping")
 			return
-		default:
+		case <-time.After(interval):
 		}
+	}
+}
 
-		// Run the job with backoff
-		log.Debug("scheduler: starting FetchAndStorePrices")
-		const maxAttempts = 3
-		for attempt := 1; attempt <= maxAttempts; attempt++ {
-			if err := svc.FetchAndStorePrices(ctx); err != nil {
-				log.Error("scheduler fetch error", "attempt", attempt, "error", err)
-				if attempt == maxAttempts {
-					log.Warn("scheduler: max retries reached, giving up this run")
-					break
-				}
-				// exponential backoff: 2s, 4s, 6s…
-				select {
-				case <-ctx.Done():
-					log.Info("scheduler: shutdown during backoff, stopping")
-					return
-				case <-time.After(time.Duration(attempt*2) * time.Second):
-				}
-				continue
-			}
-			log.Info("scheduler: FetchAndStorePrices succeeded")
-			break
+// Keeps the monthly price partitions in place with configured interval
+func startMaintenance(
+	ctx context.Context,
+	svc app.CryptoService,
+	interval time.Duration,
+	log *logger.Logger,
+) {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	for {
+		log.Debug("maintenance: starting MaintainPartitions")
+		if err := svc.MaintainPartitions(ctx); err != nil {
+			log.Error("maintenance error", "error", err)
 		}
 
 		// Wait interval before next run or exit if shutting down
 		select {
 		case <-ctx.Done():
-			log.Info("scheduler: received shutdown signal during wait, stopping")
+			log.Info("maintenance: received shutdown signal, stopping")
 			return
 		case <-time.After(interval):
 		}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// cli attach -month 2024-05
+//
+// Brings back the rows of a month detached by partition upkeep or retention (detachOnly)
+func runAttach(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("attach", flag.ContinueOnError)
+	month := fs.String("month", "", "month to attach, YYYY-MM (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *month == "" {
+		return errors.New("-month is required")
+	}
+	m, err := time.Parse("2006-01", *month)
+	if err != nil {
+		return fmt.Errorf("-month: %w", err)
+	}
+
+	name, err := e.svc.AttachPriceMonth(ctx, m)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Attached %s\n", name)
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// cli coverage [-symbol BTC] [-quote USD] -from 2025-08-01 [-to 2025-08-08] [-gaps]
+func runCoverage(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	symbol := fs.String("symbol", "", "currency symbol, every tracked currency when empty")
+	quote := fs.String("quote", domain.DefaultQuote, "quote currency")
+	fromRaw := fs.String("from", "", "window start (required)")
+	toRaw := fs.String("to", "", "window end, defaults to now")
+	showGaps := fs.Bool("gaps", false, "list every gap")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *fromRaw == "" {
+		return errors.New("-from is required")
+	}
+	from, err := parseTime(*fromRaw)
+	if err != nil {
+		return err
+	}
+	to := time.Now().UTC()
+	if *toRaw != "" {
+		if to, err = parseTime(*toRaw); err != nil {
+			return err
+		}
+	}
+
+	// Labelled by symbol, looked up by provider ID when several currencies share a symbol
+	symbols := []string{strings.ToUpper(*symbol)}
+	refs := symbols
+	if *symbol == "" {
+		if symbols, refs, err = trackedSymbols(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SYMBOL\tQUOTE\tCOUNT\tCOVERAGE\tMISSING\tGAPS\tLONGEST GAP")
+	var reports []*domain.Coverage
+	for i, sym := range symbols {
+		cov, err := e.svc.GetCoverage(ctx, refs[i], *quote, from, to)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sym, err)
+		}
+		reports = append(reports, cov)
+		fmt.Fprintf(w, "%s\t%s\t%d\t%.2f%%\t%d\t%d\t%s\n",
+			sym, strings.ToUpper(*quote), cov.Count, cov.Percent, cov.Missing, len(cov.Gaps), cov.LongestGap)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !*showGaps {
+		return nil
+	}
+	for i, cov := range reports {
+		if len(cov.Gaps) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s gaps:\n", symbols[i])
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "FROM\tTO\tDURATION\tMISSING")
+		for _, g := range cov.Gaps {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", g.From.Format(time.RFC3339), g.To.Format(time.RFC3339), g.Duration(), g.Missing)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Symbols and provider IDs of every tracked currency, in the order they were added
+func trackedSymbols(ctx context.Context, e *env) (symbols, ids []string, err error) {
+	const pageSize = 100
+	for offset := 0; ; offset += pageSize {
+		currs, err := e.repo.ListCurrencies(ctx, pageSize, offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range currs {
+			symbols = append(symbols, c.Symbol)
+			ids = append(ids, c.ProviderID)
+		}
+		if len(currs) < pageSize {
+			return symbols, ids, nil
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/app"
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// cli export -symbols BTC,ETH -from 2025-01-01 [-to 2025-08-01] [-quote USD] [-format parquet] [-gzip] [-o prices.parquet]
+func runExport(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	symbolsRaw := fs.String("symbols", "", "comma separated symbols, every tracked currency when empty")
+	quote := fs.String("quote", domain.DefaultQuote, "quote currency")
+	fromRaw := fs.String("from", "", "range start (required)")
+	toRaw := fs.String("to", "", "range end, defaults to now")
+	formatRaw := fs.String("format", "", "csv, ndjson or parquet, guessed from -o when empty")
+	gzipped := fs.Bool("gzip", false, "compress the output, implied by a .gz file name")
+	outPath := fs.String("o", "-", "output file, - writes to stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *fromRaw == "" {
+		return errors.New("-from is required")
+	}
+	from, err := parseTime(*fromRaw)
+	if err != nil {
+		return err
+	}
+	to := time.Now().UTC()
+	if *toRaw != "" {
+		if to, err = parseTime(*toRaw); err != nil {
+			return err
+		}
+	}
+
+	opts := app.ExportOptions{Quote: *quote, From: from, To: to, Format: app.ExportCSV, Gzip: *gzipped}
+	if f, gz, ok := app.ExportFormatFromName(*outPath); ok {
+		opts.Format = f
+		opts.Gzip = opts.Gzip || gz
+	}
+	if *formatRaw != "" {
+		if opts.Format, err = app.ParseExportFormat(*formatRaw); err != nil {
+			return err
+		}
+	}
+
+	if *symbolsRaw != "" {
+		opts.Symbols = strings.Split(*symbolsRaw, ",")
+	} else if _, opts.Symbols, err = trackedSymbols(ctx, e); err != nil {
+		return err
+	}
+
+	exp, err := e.svc.ExportPrices(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "-" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	rows, err := exp.Write(ctx, out)
+	if err != nil {
+		return err
+	}
+	// Keep stdout for the data
+	fmt.Fprintf(os.Stderr, "exported %d rows\n", rows)
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/app"
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// cli import [-format csv|ndjson] [-on-conflict skip|overwrite|fail] [-batch 1000] <file|->
+func runImport(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatRaw := fs.String("format", "", "csv or ndjson, guessed from the file extension when empty")
+	onConflict := fs.String("on-conflict", string(domain.ConflictSkip), "skip, overwrite or fail on prices already stored")
+	batch := fs.Int("batch", app.DefaultImportBatchSize, "rows per insert")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected one file argument, - reads stdin")
+	}
+	path := fs.Arg(0)
+
+	policy, err := domain.ParseConflictPolicy(*onConflict)
+	if err != nil {
+		return err
+	}
+	opts := app.ImportOptions{OnConflict: policy, BatchSize: *batch}
+	switch f, ok := app.ImportFormatFromName(path); {
+	case *formatRaw != "":
+		if opts.Format, err = app.ParseImportFormat(*formatRaw); err != nil {
+			return err
+		}
+	case ok:
+		opts.Format = f
+	default:
+		return errors.New("-format is required when the file has no .csv or .ndjson extension")
+	}
+
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	report, importErr := e.svc.ImportPrices(ctx, in, opts)
+	if report != nil {
+		if err := printImportReport(report); err != nil {
+			return err
+		}
+	}
+	return importErr
+}
+
+func printImportReport(report *app.ImportReport) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "rows\t%d\n", report.Rows)
+	fmt.Fprintf(w, "accepted\t%d\n", report.Accepted)
+	fmt.Fprintf(w, "  written\t%d\n", report.Written)
+	fmt.Fprintf(w, "  skipped\t%d\n", report.Skipped)
+	fmt.Fprintf(w, "rejected\t%d\n", report.Rejected)
+	if !report.From.IsZero() {
+		fmt.Fprintf(w, "range\t%s - %s\n", report.From.Format(time.RFC3339), report.To.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Errors) == 0 {
+		return nil
+	}
+	fmt.Println("\nrejected rows:")
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tREASON")
+	for _, e := range report.Errors {
+		fmt.Fprintf(w, "%d\t%s\n", e.Line, e.Reason)
+	}
+	if hidden := report.Rejected - len(report.Errors); hidden > 0 {
+		fmt.Fprintf(w, "...\t%d more\n", hidden)
+	}
+	return w.Flush()
+}
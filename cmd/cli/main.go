@@ -0,0 +1,168 @@
+// Command line tools working directly against the tracker database.
+//
+// Usage:
+//
+//	cli [-config path] <command> [flags]
+//
+// Commands:
+//
+//	attach     attach a detached month of prices again
+//	coverage   report gaps in stored prices against the fetch interval
+//	export     write stored prices as CSV, NDJSON or Parquet
+//	import     load historical prices from a CSV or NDJSON file
+//	purge      delete an archived currency and its stored data
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/Neroframe/crypto-tracker/config"
+	"github.com/Neroframe/crypto-tracker/internal/app"
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	pgrepo "github.com/Neroframe/crypto-tracker/internal/infra/postgres"
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+)
+
+// One subcommand, args exclude the command name
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+// Shared dependencies of the subcommands
+type env struct {
+	cfg  *config.Config
+	log  *logger.Logger
+	repo *pgrepo.GormRepo
+	svc  app.CryptoService
+}
+
+var commands = []command{
+	{name: "attach", usage: "attach a detached month of prices again", run: runAttach},
+	{name: "coverage", usage: "report gaps in stored prices against the fetch interval", run: runCoverage},
+	{name: "export", usage: "write stored prices as CSV, NDJSON or Parquet", run: runExport},
+	{name: "import", usage: "load historical prices from a CSV or NDJSON file", run: runImport},
+	{name: "purge", usage: "delete an archived currency and its stored data", run: runPurge},
+}
+
+func main() {
+	configPath := flag.String("config", "config/dev.yaml", "path to the config file")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	e, err := newEnv(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	err = cmd.run(ctx, e, flag.Args()[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: cli [-config path] <command> [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'cli <command> -h' for the flags of a command\n")
+}
+
+// Connects to Postgres and builds the service. The provider client isn't created,
+// subcommands only work with stored data
+func newEnv(configPath string) (*env, error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	// Keep stdout for command output
+	logCfg := logger.Config(cfg.Log)
+	logCfg.Level = "warn"
+	log := logger.New(logCfg)
+
+	pgCfg := cfg.Postgres
+	dsn := fmt.Sprintf(
+		"postgres://%s:%s@%s:%d/%s?sslmode=disable",
+		pgCfg.User,
+		pgCfg.Password,
+		pgCfg.Host,
+		pgCfg.Port,
+		pgCfg.DBName,
+	)
+	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("connect to Postgres: %w", err)
+	}
+
+	// Same tiers as the server so reads pick the right tables
+	retention := domain.RetentionPolicy{Raw: cfg.Retention.Raw}
+	for _, t := range cfg.Retention.Rollups {
+		retention.Rollups = append(retention.Rollups, domain.RetentionTier{Resolution: t.Resolution, Keep: t.Keep})
+	}
+	if err := retention.Validate(); err != nil {
+		return nil, fmt.Errorf("retention config: %w", err)
+	}
+
+	repo := pgrepo.NewGormRepo(gormDB, log)
+	svc := app.NewCryptoService(repo, nil, log, app.Config{
+		FetchInterval:    cfg.External.FetchInterval,
+		MaxPriceDistance: cfg.External.MaxPriceDistance,
+		Retention:        retention,
+		Partitions: app.PartitionConfig{
+			Ahead:      cfg.Partitions.Ahead,
+			Retain:     cfg.Partitions.Retain,
+			DetachOnly: cfg.Partitions.DetachOnly,
+		},
+	})
+	return &env{cfg: cfg, log: log, repo: repo, svc: svc}, nil
+}
+
+// Accepts Unix seconds, RFC 3339 or a YYYY-MM-DD date in UTC
+func parseTime(raw string) (time.Time, error) {
+	if sec, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, want Unix seconds, RFC 3339 or YYYY-MM-DD", raw)
+}
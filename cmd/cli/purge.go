@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// cli purge -symbol BTC [-token 9f86...]
+//
+// Without -token prints what would be deleted and a token, running again with it purges
+func runPurge(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	symbol := fs.String("symbol", "", "archived currency to delete, symbol or coin ID (required)")
+	token := fs.String("token", "", "confirmation token from a previous run")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *symbol == "" {
+		return errors.New("-symbol is required")
+	}
+
+	if *token == "" {
+		conf, err := e.svc.RequestPurge(ctx, *symbol)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Purging %s deletes %d prices, %d rollups and %d backfill jobs.\n",
+			conf.Symbol, conf.Counts.Prices, conf.Counts.Rollups, conf.Counts.BackfillJobs)
+		fmt.Printf("To confirm, run before %s:\n\n  cli purge -symbol %s -token %s\n",
+			conf.ExpiresAt.Format(time.RFC3339), *symbol, conf.Token)
+		return nil
+	}
+
+	counts, err := e.svc.PurgeCurrency(ctx, *symbol, *token)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Purged %s: %d prices, %d rollups, %d backfill jobs\n",
+		*symbol, counts.Prices, counts.Rollups, counts.BackfillJobs)
+	return nil
+}
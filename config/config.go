@@ -11,10 +11,13 @@ type (
 	Config struct {
 		Version string `yaml:"version"`
 
-		HTTP     HTTP     `yaml:"http"`
-		Postgres Postgres `yaml:"postgres"`
-		Log      Log      `yaml:"log"`
-		External External `yaml:"external"`
+		HTTP       HTTP       `yaml:"http"`
+		Postgres   Postgres   `yaml:"postgres"`
+		Log        Log        `yaml:"log"`
+		External   External   `yaml:"external"`
+		Retention  Retention  `yaml:"retention"`
+		Partitions Partitions `yaml:"partitions"`
+		Backfill   Backfill   `yaml:"backfill"`
 	}
 
 	HTTP struct {
@@ -43,9 +46,37 @@ type (
 	}
 
 	External struct {
-		CoinPaprikaURL string        `yaml:"coinpaprikaUrl"`
-		RateLimit      float64       `yaml:"rateLimit"`
-		FetchInterval  time.Duration `yaml:"fetchInterval"`
+		CoinPaprikaURL   string        `yaml:"coinpaprikaUrl"`
+		RateLimit        float64       `yaml:"rateLimit"`
+		FetchInterval    time.Duration `yaml:"fetchInterval"`
+		MaxPriceDistance time.Duration `yaml:"maxPriceDistance"` // default lookup tolerance, 0 disables
+		Quotes           []string      `yaml:"quotes"`           // quote currencies fetched per ticker, defaults to USD
+	}
+
+	// Raw prices are kept for Raw (0 = forever), then downsampled through Rollups, finest first
+	Retention struct {
+		Interval time.Duration   `yaml:"interval"` // how often rollup and pruning run
+		Raw      time.Duration   `yaml:"raw"`
+		Rollups  []RetentionTier `yaml:"rollups"`
+	}
+
+	// Monthly partitions of the price table, maintained at runtime
+	Partitions struct {
+		Interval   time.Duration `yaml:"interval"`   // how often partitions are checked
+		Ahead      int           `yaml:"ahead"`      // upcoming months created in advance
+		Retain     time.Duration `yaml:"retain"`     // older months are removed, 0 keeps them
+		DetachOnly bool          `yaml:"detachOnly"` // detach old months instead of dropping them
+	}
+
+	// Historical imports from the price provider
+	Backfill struct {
+		Lookback time.Duration `yaml:"lookback"` // loaded for newly added currencies, 0 disables
+		Interval time.Duration `yaml:"interval"` // spacing of the requested ticks
+	}
+
+	RetentionTier struct {
+		Resolution time.Duration `yaml:"resolution"`
+		Keep       time.Duration `yaml:"keep"` // 0 keeps the tier forever
 	}
 )
 
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/currency/add": {
+        "/backfill": {
             "post": {
-                "description": "Adds a cryptocurrency by symbol to start tracking",
+                "description": "Queues an import of provider history for a tracked currency over [from, to]. The job runs in the background and resumes after restarts, poll GET /backfill/{id} for progress",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,27 +25,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Currency"
+                    "Backfill"
                 ],
-                "summary": "Add a new currency",
+                "summary": "Start a historical backfill",
                 "parameters": [
                     {
-                        "description": "Currency Symbol",
+                        "description": "Backfill range",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/httpdto.AddCurrencyRequest"
+                            "$ref": "#/definitions/httpdto.BackfillRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "$ref": "#/definitions/httpdto.AddCurrencyResponse"
+                                "$ref": "#/definitions/httpdto.BackfillJobResponse"
                             }
                         }
                     },
@@ -58,6 +58,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {
@@ -75,32 +84,36 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/currency/price": {
-            "post": {
-                "description": "Returns the closest price snapshot at or before the given timestamp",
-                "consumes": [
-                    "application/json"
-                ],
+        "/backfill/{id}": {
+            "get": {
+                "description": "Returns the status, cursor and number of stored prices of a backfill job",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Price"
+                    "Backfill"
                 ],
-                "summary": "Get historical price snapshot",
+                "summary": "Get backfill progress",
                 "parameters": [
                     {
-                        "description": "Symbol and Unix Timestamp",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/httpdto.PriceQueryRequest"
-                        }
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -109,7 +122,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "$ref": "#/definitions/httpdto.PriceQueryResponse"
+                                "$ref": "#/definitions/httpdto.BackfillJobResponse"
                             }
                         }
                     },
@@ -143,37 +156,32 @@ const docTemplate = `{
                 }
             }
         },
-        "/currency/remove": {
+        "/backfill/{id}/resume": {
             "post": {
-                "description": "Removes a tracked cryptocurrency by symbol",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Requeues a failed backfill job, it continues from its cursor. Finished jobs are returned unchanged",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Currency"
+                    "Backfill"
                 ],
-                "summary": "Remove a tracked currency",
+                "summary": "Resume a failed backfill",
                 "parameters": [
                     {
-                        "description": "Currency Symbol",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/httpdto.RemoveCurrencyRequest"
-                        }
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
-                                "$ref": "#/definitions/httpdto.RemoveCurrencyResponse"
+                                "$ref": "#/definitions/httpdto.BackfillJobResponse"
                             }
                         }
                     },
@@ -195,6 +203,15 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -206,77 +223,2853 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "httpdto.AddCurrencyRequest": {
-            "type": "object",
-            "required": [
-                "symbol"
-            ],
-            "properties": {
-                "symbol": {
-                    "type": "string",
-                    "maxLength": 10,
-                    "minLength": 1,
-                    "example": "BTC"
+        },
+        "/coins/search": {
+            "get": {
+                "description": "Autocomplete over the symbols and names of Coinpaprika coins. Prefix matches come before fuzzy ones (substrings, letters in order, one typo), each ordered by market cap rank. Coins already tracked are marked with the status of their currency",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Coins"
+                ],
+                "summary": "Search the provider coin catalog",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Symbol or name fragment",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Maximum results",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.CoinSearchResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "httpdto.AddCurrencyResponse": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string",
-                    "example": "2025-08-08T18:00:00Z"
-                },
-                "id": {
-                    "type": "string",
-                    "example": "3fa85f64-5717-4562-b3fc-2c963f66afa6"
-                },
-                "symbol": {
-                    "type": "string",
-                    "example": "BTC"
+        "/currencies": {
+            "get": {
+                "description": "Tracked currencies with their status, fetch schedule and latest stored price, filtered by symbol prefix and status. Pages are sorted by symbol or creation time, pass next_cursor as cursor for the next one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "List tracked currencies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Symbol prefix",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "paused",
+                            "archived"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "symbol",
+                            "created"
+                        ],
+                        "type": "string",
+                        "default": "symbol",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "USD",
+                        "description": "Quote currency of the latest price",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 500,
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.CurrencyListResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "httpdto.PriceQueryRequest": {
-            "type": "object",
-            "required": [
-                "symbol",
-                "timestamp"
-            ],
-            "properties": {
-                "symbol": {
-                    "type": "string",
-                    "maxLength": 10,
-                    "minLength": 1,
-                    "example": "BTC"
-                },
-                "timestamp": {
-                    "type": "integer",
-                    "example": 1723123200
+        "/currency/add": {
+            "post": {
+                "description": "Adds a cryptocurrency by symbol or provider coin ID to start tracking. A symbol shared by several provider coins is rejected with the candidates, add one of them by provider_id. Adding a paused or archived currency makes it active again with its history intact",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Add a new currency",
+                "parameters": [
+                    {
+                        "description": "Currency Symbol",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.AddCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.AddCurrencyResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.AmbiguousSymbolResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "httpdto.PriceQueryResponse": {
-            "type": "object",
-            "properties": {
-                "price": {
+        "/currency/price": {
+            "post": {
+                "description": "Returns the price at the given timestamp. mode selects the nearest snapshot (default), the last one at or before, the first one at or after, or a linear interpolation between both neighbours. Returns 422 when the nearest snapshot is further than max_distance seconds away. stale does not depend on max_distance: it is set when distance_seconds exceeds the expected spacing of stored prices, which is the fetch interval of the currency, its deadband heartbeat, or the bucket of the rollup the price was read from, whichever is longest",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price"
+                ],
+                "summary": "Get historical price snapshot",
+                "parameters": [
+                    {
+                        "description": "Symbol and Unix Timestamp",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.PriceQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.PriceQueryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/prices": {
+            "post": {
+                "description": "Resolves up to 10000 (symbol, timestamp) pairs in one call. Items are answered in request order, each with either a result or an error",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price"
+                ],
+                "summary": "Get many historical prices",
+                "parameters": [
+                    {
+                        "description": "Pairs, lookup mode and tolerance",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.BatchPriceQueryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.BatchPriceQueryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/remove": {
+            "post": {
+                "description": "Archives a tracked cryptocurrency: its prices are no longer fetched, stored history stays queryable. Deleting the history takes an explicit purge",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Remove a tracked currency",
+                "parameters": [
+                    {
+                        "description": "Currency Symbol",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.RemoveCurrencyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.RemoveCurrencyResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/candles": {
+            "get": {
+                "description": "Aggregates stored prices into open/high/low/close candles aligned to the interval",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price"
+                ],
+                "summary": "Get OHLC candles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency, defaults to USD",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "1m",
+                            "5m",
+                            "1h",
+                            "1d"
+                        ],
+                        "type": "string",
+                        "description": "Bucket size",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window start (Unix seconds)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window end (Unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.CandlesResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/coverage": {
+            "get": {
+                "description": "Compares stored prices within [from, to] with the fetch interval and lists the gaps, the number of missing fetches, the covered share of the window and the longest gap. The window ends at the current time at the latest",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price"
+                ],
+                "summary": "Get data coverage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency, defaults to USD",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window start (Unix seconds)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window end (Unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.CoverageResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/deadband": {
+            "put": {
+                "description": "Stores fetched prices of the currency only when they move by more than threshold_percent from the last stored price, or when heartbeat_seconds have passed since it. Lookups of such currencies return the last stored price at or before the requested time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Enable deadband storage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Deadband settings",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.DeadbandRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.StorageModeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stores every fetched price of the currency again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Disable deadband storage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.StorageModeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/history": {
+            "get": {
+                "description": "Returns the ordered price snapshots within [from, to]. Use next_cursor to fetch the following page",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price"
+                ],
+                "summary": "Get price history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency, defaults to USD",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window start (Unix seconds)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window end (Unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 500, max 5000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.PriceHistoryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/indicators/{name}": {
+            "get": {
+                "description": "Computes sma, ema, rsi, bollinger or macd over stored prices resampled to the interval. Unset parameters take the usual defaults (period 20, rsi 14, k 2, macd 12/26/9)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price"
+                ],
+                "summary": "Get a technical indicator",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency, defaults to USD",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "sma",
+                            "ema",
+                            "rsi",
+                            "bollinger",
+                            "macd"
+                        ],
+                        "type": "string",
+                        "description": "Indicator",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "1m",
+                            "5m",
+                            "1h",
+                            "1d"
+                        ],
+                        "type": "string",
+                        "description": "Resampling interval",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window start (Unix seconds)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window end (Unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lookback period (sma, ema, rsi, bollinger)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Band width in standard deviations (bollinger)",
+                        "name": "k",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Fast EMA period (macd)",
+                        "name": "fast",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Slow EMA period (macd)",
+                        "name": "slow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Signal EMA period (macd)",
+                        "name": "signal",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.IndicatorResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/purge": {
+            "post": {
+                "description": "Irreversibly deletes an archived currency with its prices, rollups and backfill jobs. Needs the token from /currency/{symbol}/purge/token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Purge an archived currency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Purge token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.PurgeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.PurgeResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/purge/token": {
+            "post": {
+                "description": "First step of deleting an archived currency and all of its stored data. Returns a token valid for 10 minutes and the number of rows the purge would delete",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Request a purge token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.PurgeTokenResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/schedule": {
+            "put": {
+                "description": "Fetches the currency every interval_seconds instead of the server fetch interval. When more fetches are due than the provider rate limit allows, higher priorities (0-100) go first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Set the fetch schedule of a currency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fetch schedule",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.FetchScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.FetchScheduleResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/stats": {
+            "get": {
+                "description": "Summarises stored prices within [from, to]: change, min and max with their timestamps, mean, annualised volatility of log returns and max drawdown",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Price"
+                ],
+                "summary": "Get window statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency, defaults to USD",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window start (Unix seconds)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window end (Unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.WindowStatsResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/currency/{symbol}/status": {
+            "put": {
+                "description": "Only active currencies are fetched. Paused currencies stay tracked, archived ones are removed from tracking. Stored prices are kept in every status",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Currency"
+                ],
+                "summary": "Change the status of a currency",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol or provider coin ID",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/httpdto.CurrencyStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.CurrencyStatusResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/export": {
+            "get": {
+                "description": "Streams the stored raw prices of one or more symbols over [from, to] as CSV, NDJSON or Parquet. Rows are written as they are read from the database, symbol by symbol and ordered by timestamp, and carry the symbol and currency ID. gzip compresses the whole file for CSV and NDJSON and the pages for Parquet",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet",
+                    "application/gzip"
+                ],
+                "tags": [
+                    "Export"
+                ],
+                "summary": "Export price history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "BTC,ETH",
+                        "description": "Comma separated symbols",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote currency, defaults to USD",
+                        "name": "quote",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Range start (Unix seconds)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Range end (Unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "description": "File format, defaults to csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compress the output",
+                        "name": "gzip",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/import": {
+            "post": {
+                "description": "Streams a multipart CSV or NDJSON file of (symbol, timestamp, price[, quote]) rows into storage in batches. Timestamps are Unix seconds or RFC 3339, symbols must be tracked. Invalid rows are rejected and listed in the report, on_conflict decides what happens to prices already stored for the same timestamp. Rows in a month whose partition was detached stop the import with 409",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Import"
+                ],
+                "summary": "Import historical prices",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV or NDJSON file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "File format, guessed from the file name when empty",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "overwrite",
+                            "fail"
+                        ],
+                        "type": "string",
+                        "description": "Conflict policy, defaults to skip",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.ImportReportResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pair/{base}/{quote}/history": {
+            "get": {
+                "description": "Pairs every base snapshot within [from, to] with the nearest quote snapshot. Points without a quote snapshot within the lookup tolerance are left out",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pair"
+                ],
+                "summary": "Get cross rate history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base symbol or provider coin ID",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote symbol or provider coin ID",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window start (Unix seconds)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Window end (Unix seconds), defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size of the base series (default 500, max 5000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.PairHistoryResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/pair/{base}/{quote}/price": {
+            "get": {
+                "description": "Prices base in units of quote at the given time, derived from both USD series. alignment_error_seconds is the gap between the two observations",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pair"
+                ],
+                "summary": "Get a cross rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base symbol or provider coin ID",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote symbol or provider coin ID",
+                        "name": "quote",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Unix Timestamp",
+                        "name": "at",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "nearest",
+                            "before",
+                            "after",
+                            "interpolate"
+                        ],
+                        "type": "string",
+                        "description": "Lookup mode",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tolerance in seconds, defaults to the server setting",
+                        "name": "max_distance",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "$ref": "#/definitions/httpdto.PairPriceResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "httpdto.AddCurrencyRequest": {
+            "type": "object",
+            "properties": {
+                "provider_id": {
+                    "description": "picks one coin of an ambiguous symbol",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "btc-bitcoin"
+                },
+                "symbol": {
+                    "type": "string",
+                    "maxLength": 10,
+                    "minLength": 1,
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.AddCurrencyResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-08-08T18:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+                },
+                "provider_id": {
+                    "type": "string",
+                    "example": "btc-bitcoin"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.AmbiguousSymbolResponse": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.CoinResponse"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "symbol matches several coins, pick one by provider_id"
+                }
+            }
+        },
+        "httpdto.BackfillJobResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-08-08T18:00:00Z"
+                },
+                "cursor": {
+                    "type": "integer",
+                    "example": 1721000000
+                },
+                "error": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1720444800
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+                },
+                "inserted": {
+                    "type": "integer",
+                    "example": 154
+                },
+                "interval_seconds": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "progress_percent": {
+                    "type": "number",
+                    "example": 20.7
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "running",
+                        "done",
+                        "failed"
+                    ],
+                    "example": "running"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723123200
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-08-08T18:02:00Z"
+                }
+            }
+        },
+        "httpdto.BackfillRequest": {
+            "type": "object",
+            "required": [
+                "from",
+                "symbol"
+            ],
+            "properties": {
+                "from": {
+                    "type": "integer",
+                    "example": 1720444800
+                },
+                "quote": {
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "USD"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723123200
+                }
+            }
+        },
+        "httpdto.BatchPriceItemResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "price not found in the database"
+                },
+                "requested_timestamp": {
+                    "type": "integer",
+                    "example": 1723123200
+                },
+                "result": {
+                    "$ref": "#/definitions/httpdto.PriceQueryResponse"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.BatchPriceQueryItem": {
+            "type": "object",
+            "required": [
+                "symbol",
+                "timestamp"
+            ],
+            "properties": {
+                "quote": {
+                    "description": "defaults to the request quote",
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "EUR"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1723123200
+                }
+            }
+        },
+        "httpdto.BatchPriceQueryRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "maxItems": 10000,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/httpdto.BatchPriceQueryItem"
+                    }
+                },
+                "max_distance": {
+                    "description": "seconds, defaults to the server setting",
+                    "type": "integer",
+                    "example": 300
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "nearest",
+                        "before",
+                        "after",
+                        "interpolate"
+                    ],
+                    "example": "nearest"
+                },
+                "quote": {
+                    "description": "defaults to USD",
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "USD"
+                }
+            }
+        },
+        "httpdto.BatchPriceQueryResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "items": {
+                    "description": "same order as the request",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.BatchPriceItemResponse"
+                    }
+                }
+            }
+        },
+        "httpdto.CandleResponse": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "string",
+                    "example": "29753.55"
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "high": {
+                    "type": "string",
+                    "example": "29810.00"
+                },
+                "low": {
+                    "type": "string",
+                    "example": "29650.25"
+                },
+                "open": {
+                    "type": "string",
+                    "example": "29700.10"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1723122000
+                }
+            }
+        },
+        "httpdto.CandlesResponse": {
+            "type": "object",
+            "properties": {
+                "candles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.CandleResponse"
+                    }
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "1h"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.CoinMatchResponse": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Uniswap"
+                },
+                "provider_id": {
+                    "type": "string",
+                    "example": "uni-uniswap"
+                },
+                "rank": {
+                    "description": "0 when unranked",
+                    "type": "integer",
+                    "example": 22
+                },
+                "status": {
+                    "description": "status of the tracked currency",
+                    "type": "string",
+                    "example": "active"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "UNI"
+                },
+                "tracked": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "coin",
+                        "token"
+                    ],
+                    "example": "token"
+                }
+            }
+        },
+        "httpdto.CoinResponse": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Uniswap"
+                },
+                "provider_id": {
+                    "type": "string",
+                    "example": "uni-uniswap"
+                },
+                "rank": {
+                    "description": "0 when unranked",
+                    "type": "integer",
+                    "example": 22
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "UNI"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "coin",
+                        "token"
+                    ],
+                    "example": "token"
+                }
+            }
+        },
+        "httpdto.CoinSearchResponse": {
+            "type": "object",
+            "properties": {
+                "coins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.CoinMatchResponse"
+                    }
+                },
+                "query": {
+                    "type": "string",
+                    "example": "uni"
+                }
+            }
+        },
+        "httpdto.CoverageGapResponse": {
+            "type": "object",
+            "properties": {
+                "duration_seconds": {
+                    "type": "integer",
+                    "example": 630
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1723051200
+                },
+                "missing": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723051830
+                }
+            }
+        },
+        "httpdto.CoverageResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 2850
+                },
+                "coverage_percent": {
+                    "type": "number",
+                    "example": 98.96
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1723036800
+                },
+                "gaps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.CoverageGapResponse"
+                    }
+                },
+                "interval_seconds": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "longest_gap_seconds": {
+                    "type": "integer",
+                    "example": 630
+                },
+                "missing_intervals": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723123200
+                }
+            }
+        },
+        "httpdto.CurrencyEntryResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2025-08-08T18:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+                },
+                "interval_seconds": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "latest_price": {
+                    "description": "omitted when nothing is stored",
+                    "type": "string",
+                    "example": "29753.55"
+                },
+                "latest_timestamp": {
+                    "type": "integer",
+                    "example": 1723123199
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "provider_id": {
+                    "description": "\"legacy:\u003csymbol\u003e\" while a currency added before IDs were stored is unresolved",
+                    "type": "string",
+                    "example": "btc-bitcoin"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "active"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.CurrencyListResponse": {
+            "type": "object",
+            "properties": {
+                "currencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.CurrencyEntryResponse"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "c3ltYm9sOkVUSA"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                }
+            }
+        },
+        "httpdto.CurrencyStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "paused",
+                        "archived"
+                    ],
+                    "example": "paused"
+                }
+            }
+        },
+        "httpdto.CurrencyStatusResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "paused"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2025-08-08T18:00:00Z"
+                }
+            }
+        },
+        "httpdto.DeadbandRequest": {
+            "type": "object",
+            "required": [
+                "heartbeat_seconds",
+                "threshold_percent"
+            ],
+            "properties": {
+                "heartbeat_seconds": {
+                    "type": "integer",
+                    "maximum": 86400,
+                    "minimum": 1,
+                    "example": 900
+                },
+                "threshold_percent": {
+                    "type": "number",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 0.1
+                }
+            }
+        },
+        "httpdto.FetchScheduleRequest": {
+            "type": "object",
+            "properties": {
+                "interval_seconds": {
+                    "description": "omitted uses the server fetch interval",
+                    "type": "integer",
+                    "maximum": 86400,
+                    "minimum": 1,
+                    "example": 10
+                },
+                "priority": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 90
+                }
+            }
+        },
+        "httpdto.FetchScheduleResponse": {
+            "type": "object",
+            "properties": {
+                "interval_seconds": {
+                    "description": "omitted when the server fetch interval applies",
+                    "type": "integer",
+                    "example": 10
+                },
+                "priority": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.ImportReportResponse": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer",
+                    "example": 1190
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.ImportRowErrorResponse"
+                    }
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1546300800
+                },
+                "rejected": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723123200
+                },
+                "written": {
+                    "type": "integer",
+                    "example": 1100
+                }
+            }
+        },
+        "httpdto.ImportRowErrorResponse": {
+            "type": "object",
+            "properties": {
+                "line": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "invalid timestamp \"yesterday\", want Unix seconds or RFC 3339"
+                }
+            }
+        },
+        "httpdto.IndicatorParamsResponse": {
+            "type": "object",
+            "properties": {
+                "fast": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "k": {
+                    "type": "number",
+                    "example": 2
+                },
+                "period": {
+                    "type": "integer",
+                    "example": 20
+                },
+                "signal": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "slow": {
+                    "type": "integer",
+                    "example": 26
+                }
+            }
+        },
+        "httpdto.IndicatorPointResponse": {
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1723122000
+                },
+                "values": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number",
+                        "format": "float64"
+                    }
+                }
+            }
+        },
+        "httpdto.IndicatorResponse": {
+            "type": "object",
+            "properties": {
+                "indicator": {
+                    "type": "string",
+                    "example": "bollinger"
+                },
+                "interval": {
+                    "type": "string",
+                    "example": "1h"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "middle",
+                        "upper",
+                        "lower"
+                    ]
+                },
+                "params": {
+                    "$ref": "#/definitions/httpdto.IndicatorParamsResponse"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.IndicatorPointResponse"
+                    }
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.PairHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "base": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1723120000
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MTcyMzEyMzE5OQ"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.PairPointResponse"
+                    }
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723123200
+                }
+            }
+        },
+        "httpdto.PairPointResponse": {
+            "type": "object",
+            "properties": {
+                "alignment_error_seconds": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "base_price": {
+                    "type": "string",
+                    "example": "29753.55"
+                },
+                "quote_price": {
+                    "type": "string",
+                    "example": "1615.30"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "18.42"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1723123199
+                }
+            }
+        },
+        "httpdto.PairPriceResponse": {
+            "type": "object",
+            "properties": {
+                "alignment_error_seconds": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "base": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "base_price": {
+                    "$ref": "#/definitions/httpdto.PriceQueryResponse"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "ETH"
+                },
+                "quote_price": {
+                    "$ref": "#/definitions/httpdto.PriceQueryResponse"
+                },
+                "rate": {
+                    "type": "string",
+                    "example": "18.42"
+                },
+                "requested_timestamp": {
+                    "type": "integer",
+                    "example": 1723123200
+                }
+            }
+        },
+        "httpdto.PriceHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "integer",
+                    "example": 1723120000
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "MTcyMzEyMzE5OQ"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.PricePointResponse"
+                    }
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723123200
+                }
+            }
+        },
+        "httpdto.PricePointResponse": {
+            "type": "object",
+            "properties": {
+                "circulating_supply": {
+                    "type": "number",
+                    "example": 19700000
+                },
+                "market_cap": {
+                    "type": "number",
+                    "example": 586000000000
+                },
+                "percent_change_1h": {
+                    "type": "number",
+                    "example": 0.12
+                },
+                "percent_change_24h": {
+                    "type": "number",
+                    "example": -1.4
+                },
+                "percent_change_7d": {
+                    "type": "number",
+                    "example": 3.2
+                },
+                "price": {
+                    "type": "string",
+                    "example": "29753.55"
+                },
+                "rank": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "resolution_seconds": {
+                    "description": "set for downsampled prices",
+                    "type": "integer",
+                    "example": 300
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1723123199
+                },
+                "volume_24h": {
                     "type": "number",
-                    "example": 29753.55
+                    "example": 18250331472.5
+                }
+            }
+        },
+        "httpdto.PriceQueryRequest": {
+            "type": "object",
+            "required": [
+                "symbol",
+                "timestamp"
+            ],
+            "properties": {
+                "max_distance": {
+                    "description": "seconds, defaults to the server setting",
+                    "type": "integer",
+                    "example": 300
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "nearest",
+                        "before",
+                        "after",
+                        "interpolate"
+                    ],
+                    "example": "nearest"
+                },
+                "quote": {
+                    "description": "defaults to USD",
+                    "type": "string",
+                    "maxLength": 10,
+                    "example": "USD"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "timestamp": {
+                    "type": "integer",
+                    "example": 1723123200
+                }
+            }
+        },
+        "httpdto.PriceQueryResponse": {
+            "type": "object",
+            "properties": {
+                "distance_seconds": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "nearest"
+                },
+                "price": {
+                    "type": "string",
+                    "example": "29753.55"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
                 },
                 "requested_timestamp": {
                     "type": "integer",
                     "example": 1723123200
                 },
                 "returned_timestamp": {
+                    "description": "requested timestamp for interpolated prices",
                     "type": "integer",
                     "example": 1723123199
                 },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/httpdto.PricePointResponse"
+                    }
+                },
+                "stale": {
+                    "description": "distance_seconds exceeds the expected spacing of stored prices, see GetPrice",
+                    "type": "boolean",
+                    "example": false
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.PurgeCountsResponse": {
+            "type": "object",
+            "properties": {
+                "backfill_jobs": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "prices": {
+                    "type": "integer",
+                    "example": 52560
+                },
+                "rollups": {
+                    "type": "integer",
+                    "example": 4380
+                }
+            }
+        },
+        "httpdto.PurgeRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                }
+            }
+        },
+        "httpdto.PurgeResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "$ref": "#/definitions/httpdto.PurgeCountsResponse"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                }
+            }
+        },
+        "httpdto.PurgeTokenResponse": {
+            "type": "object",
+            "properties": {
+                "deletes": {
+                    "$ref": "#/definitions/httpdto.PurgeCountsResponse"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2025-08-08T18:10:00Z"
+                },
                 "symbol": {
                     "type": "string",
                     "example": "BTC"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
                 }
             }
         },
@@ -288,8 +3081,6 @@ const docTemplate = `{
             "properties": {
                 "symbol": {
                     "type": "string",
-                    "maxLength": 10,
-                    "minLength": 1,
                     "example": "BTC"
                 }
             }
@@ -299,7 +3090,109 @@ const docTemplate = `{
             "properties": {
                 "message": {
                     "type": "string",
-                    "example": "removed BTC"
+                    "example": "archived BTC"
+                }
+            }
+        },
+        "httpdto.StorageModeResponse": {
+            "type": "object",
+            "properties": {
+                "heartbeat_seconds": {
+                    "type": "integer",
+                    "example": 900
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "all",
+                        "deadband"
+                    ],
+                    "example": "deadband"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "threshold_percent": {
+                    "type": "number",
+                    "example": 0.1
+                }
+            }
+        },
+        "httpdto.WindowStatsResponse": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "string",
+                    "example": "853.55"
+                },
+                "change_percent": {
+                    "type": "number",
+                    "example": 2.95
+                },
+                "close": {
+                    "type": "string",
+                    "example": "29753.55"
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 2880
+                },
+                "first_timestamp": {
+                    "type": "integer",
+                    "example": 1723036815
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1723036800
+                },
+                "last_timestamp": {
+                    "type": "integer",
+                    "example": 1723123185
+                },
+                "max": {
+                    "type": "string",
+                    "example": "29810.00"
+                },
+                "max_drawdown_percent": {
+                    "type": "number",
+                    "example": 1.87
+                },
+                "max_timestamp": {
+                    "type": "integer",
+                    "example": 1723118400
+                },
+                "mean": {
+                    "type": "string",
+                    "example": "29310.44"
+                },
+                "min": {
+                    "type": "string",
+                    "example": "28710.12"
+                },
+                "min_timestamp": {
+                    "type": "integer",
+                    "example": 1723051200
+                },
+                "open": {
+                    "type": "string",
+                    "example": "28900.00"
+                },
+                "quote": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BTC"
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 1723123200
+                },
+                "volatility_annualized": {
+                    "type": "number",
+                    "example": 0.54
                 }
             }
         }
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultBackfillInterval = time.Hour
+
+	// How often RunBackfills looks for queued jobs without being signalled
+	backfillPollInterval = time.Minute
+)
+
+// Historical imports
+type BackfillConfig struct {
+	Lookback time.Duration // history loaded for newly added currencies, 0 disables
+	Interval time.Duration // spacing of the requested ticks, DefaultBackfillInterval when 0
+}
+
+// Queues a backfill of [from, to] for a tracked currency. The job runs in the
+// background, its progress is persisted and survives restarts
+func (s *cryptoService) StartBackfill(ctx context.Context, symbol, quote string, from, to time.Time) (*domain.BackfillJob, error) {
+	if _, ok := s.api.(HistoricalPriceAPI); !ok {
+		return nil, domain.ErrBackfillUnsupported
+	}
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return s.queueBackfill(ctx, cur, quote, from, to)
+}
+
+func (s *cryptoService) GetBackfillJob(ctx context.Context, id uuid.UUID) (*domain.BackfillJob, error) {
+	return s.repo.GetBackfillJob(ctx, id)
+}
+
+// Requeues a failed job, it continues from its cursor
+func (s *cryptoService) ResumeBackfill(ctx context.Context, id uuid.UUID) (*domain.BackfillJob, error) {
+	job, err := s.repo.GetBackfillJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch job.Status {
+	case domain.BackfillDone:
+		return job, nil
+	case domain.BackfillPending, domain.BackfillRunning:
+		return nil, domain.ErrBackfillActive
+	}
+
+	job.Status = domain.BackfillPending
+	job.Error = ""
+	if err := s.repo.UpdateBackfillJob(ctx, job); err != nil {
+		return nil, err
+	}
+	s.wakeBackfills()
+	return job, nil
+}
+
+// Works through queued jobs one at a time until ctx is cancelled. Jobs left
+// running by a previous process are picked up again from their cursor
+func (s *cryptoService) RunBackfills(ctx context.Context) {
+	for {
+		jobs, err := s.repo.ListUnfinishedBackfillJobs(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.log.Error("list backfill jobs failed", "error", err)
+		}
+		for _, job := range jobs {
+			if ctx.Err() != nil {
+				return
+			}
+			s.runBackfill(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.backfillWake:
+		case <-time.After(backfillPollInterval):
+		}
+	}
+}
+
+// Loads the configured look-back for a currency that was just added.
+// Failing to queue it doesn't fail the add
+func (s *cryptoService) backfillNewCurrency(ctx context.Context, cur *domain.Currency) {
+	if s.cfg.Backfill.Lookback <= 0 {
+		return
+	}
+	if _, ok := s.api.(HistoricalPriceAPI); !ok {
+		return
+	}
+	now := time.Now().UTC()
+	from := now.Add(-s.cfg.Backfill.Lookback)
+	// Detached months refuse writes, stay within the months retention keeps attached
+	if cutoff := s.partitionCutoff(now); s.cfg.Partitions.DetachOnly && cutoff.After(from) {
+		from = cutoff
+	}
+	if _, err := s.queueBackfill(ctx, cur, domain.DefaultQuote, from, now); err != nil {
+		s.log.Error("queue backfill failed", "symbol", cur.Symbol, "error", err)
+	}
+}
+
+func (s *cryptoService) queueBackfill(ctx context.Context, cur *domain.Currency, quote string, from, to time.Time) (*domain.BackfillJob, error) {
+	interval := s.cfg.Backfill.Interval
+	if interval <= 0 {
+		interval = DefaultBackfillInterval
+	}
+	job, err := domain.NewBackfillJob(cur, quote, from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateBackfillJob(ctx, job); err != nil {
+		return nil, err
+	}
+	s.log.Info("backfill queued", "job", job.ID, "symbol", job.Symbol, "quote", job.Quote, "from", job.Start, "to", job.End)
+	s.wakeBackfills()
+	return job, nil
+}
+
+func (s *cryptoService) wakeBackfills() {
+	select {
+	case s.backfillWake <- struct{}{}:
+	default: // a wake-up is already pending
+	}
+}
+
+// Pages through the provider history from the job cursor and stores every page in bulk.
+// The cursor is saved after each page, so an interrupted job loses at most one page
+func (s *cryptoService) runBackfill(ctx context.Context, job *domain.BackfillJob) {
+	log := s.log.With("job", job.ID, "symbol", job.Symbol, "quote", job.Quote)
+
+	hist, ok := s.api.(HistoricalPriceAPI)
+	if !ok {
+		s.failBackfill(ctx, job, domain.ErrBackfillUnsupported)
+		return
+	}
+	if strings.HasPrefix(job.ProviderID, domain.LegacyProviderPrefix) {
+		s.failBackfill(ctx, job, fmt.Errorf("%w: %s has no provider coin ID yet", ErrUnknownCoin, job.Symbol))
+		return
+	}
+
+	job.Status = domain.BackfillRunning
+	job.Error = ""
+	if err := s.repo.UpdateBackfillJob(ctx, job); err != nil {
+		log.Error("start backfill failed", "error", err)
+		return
+	}
+
+	// Old months may not have a partition yet
+	if repo, ok := s.repo.(domain.PartitionRepository); ok {
+		if _, err := repo.EnsurePricePartitions(ctx, job.Cursor, job.End); err != nil {
+			if ctx.Err() == nil {
+				s.failBackfill(ctx, job, fmt.Errorf("create partitions: %w", err))
+			}
+			return
+		}
+	}
+
+	for job.Cursor.Before(job.End) {
+		ticks, err := hist.FetchHistory(ctx, job.ProviderID, job.Quote, job.Cursor, job.End, job.Interval)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.failBackfill(ctx, job, fmt.Errorf("fetch history: %w", err))
+			}
+			return
+		}
+		if len(ticks) == 0 || ticks[len(ticks)-1].Timestamp.Before(job.Cursor) {
+			break // nothing left in the range
+		}
+
+		fetchedAt := time.Now().UTC()
+		snaps := make([]*domain.PriceSnapshot, 0, len(ticks))
+		for _, t := range ticks {
+			if t.Timestamp.Before(job.Cursor) || t.Timestamp.After(job.End) {
+				continue
+			}
+			snap, err := domain.NewPriceSnapshot(job.CurrencyID, job.Quote, t.Timestamp, t.Price)
+			if err != nil {
+				log.Warn("invalid historical tick", "timestamp", t.Timestamp, "error", err)
+				continue
+			}
+			snap.Market = t.Market
+			snap.FetchedAt = fetchedAt
+			snaps = append(snaps, snap)
+		}
+
+		n, err := s.repo.SavePriceSnapshots(ctx, snaps, domain.ConflictSkip)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.failBackfill(ctx, job, fmt.Errorf("save history: %w", err))
+			}
+			return
+		}
+		job.Inserted += n
+		job.Cursor = ticks[len(ticks)-1].Timestamp.Add(time.Second)
+		if err := s.repo.UpdateBackfillJob(ctx, job); err != nil {
+			log.Error("save backfill progress failed", "error", err)
+			return
+		}
+		log.Debug("backfill page stored", "cursor", job.Cursor, "inserted", n)
+	}
+
+	// Expired raw data would be pruned before the regular rollup reaches it
+	if err := s.rollupRange(ctx, job.Start, job.End); err != nil {
+		s.failBackfill(ctx, job, fmt.Errorf("roll up history: %w", err))
+		return
+	}
+
+	job.Status = domain.BackfillDone
+	job.Cursor = job.End
+	if err := s.repo.UpdateBackfillJob(ctx, job); err != nil {
+		log.Error("finish backfill failed", "error", err)
+		return
+	}
+	log.Info("backfill done", "inserted", job.Inserted)
+}
+
+func (s *cryptoService) failBackfill(ctx context.Context, job *domain.BackfillJob, cause error) {
+	s.log.Error("backfill failed", "job", job.ID, "symbol", job.Symbol, "error", cause)
+	job.Status = domain.BackfillFailed
+	job.Error = cause.Error()
+	if err := s.repo.UpdateBackfillJob(ctx, job); err != nil && !errors.Is(err, context.Canceled) {
+		s.log.Error("save backfill failure failed", "job", job.ID, "error", err)
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// Upper bound of buckets a single candles request may span
+const MaxCandles = 5000
+
+// Candles over [from, to]. Each retention tier serves its own part of the range: raw prices are
+// aggregated into candles, older parts are merged from their rollups. Buckets split across two
+// tiers are joined, so both repository backends return the same candles
+func (s *cryptoService) GetCandles(
+	ctx context.Context,
+	symbol, quote string,
+	from, to time.Time,
+	bucket time.Duration,
+) ([]*domain.Candle, error) {
+	if to.Before(from) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+	if bucket <= 0 {
+		return nil, domain.ErrInvalidInterval
+	}
+	if to.Sub(from)/bucket >= MaxCandles {
+		return nil, domain.ErrRangeTooLarge
+	}
+	quote, err := domain.NormalizeQuote(quote)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []*domain.Candle
+	for _, seg := range s.cfg.Retention.Segments(from, to, time.Now().UTC()) {
+		var candles []*domain.Candle
+		if seg.Resolution == 0 {
+			candles, err = s.rawCandles(ctx, cur, quote, seg.From, seg.To, bucket)
+		} else {
+			candles, err = s.repo.ListRollups(ctx, cur.ID, quote, seg.Resolution, seg.From, seg.To, 0)
+		}
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, candles...)
+	}
+	return domain.MergeCandles(parts, bucket), nil
+}
+
+// Candles of raw snapshots within [from, to]
+func (s *cryptoService) rawCandles(
+	ctx context.Context,
+	cur *domain.Currency,
+	quote string,
+	from, to time.Time,
+	bucket time.Duration,
+) ([]*domain.Candle, error) {
+	// Prefer aggregation in the database when the backend supports it
+	if cr, ok := s.repo.(domain.CandleRepository); ok {
+		return cr.ListCandles(ctx, cur.ID, quote, from, to, bucket)
+	}
+
+	var snaps []*domain.PriceSnapshot
+	for start := from; ; {
+		page, err := s.repo.ListPriceSnapshots(ctx, cur.ID, quote, start, to, MaxHistoryPageSize)
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, page...)
+		if len(page) < MaxHistoryPageSize {
+			break
+		}
+		start = page[len(page)-1].Timestamp.Add(time.Second)
+	}
+	return domain.BuildCandles(snaps, bucket), nil
+}
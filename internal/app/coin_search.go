@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+const (
+	DefaultCoinSearchLimit = 20
+	MaxCoinSearchLimit     = 100
+
+	// Shorter queries only match by prefix, fuzzy matches would be noise
+	minFuzzyQuery = 3
+)
+
+// Catalog coin matching a search, with the currency tracking it
+type CoinMatch struct {
+	Coin
+	Tracked bool
+	Status  string // status of the tracking currency, empty when untracked
+}
+
+// How well a coin matches a query, lower is better
+type matchTier int
+
+const (
+	matchPrefix matchTier = iota // symbol, name or a word of the name starts with the query
+	matchFuzzy                   // a substring, the query letters in order or one typo away
+	noMatch
+)
+
+// Searches the provider catalog by symbol and name for autocomplete. Prefix matches come
+// before fuzzy ones, each ordered by market cap rank with unranked coins last
+func (s *cryptoService) SearchCoins(ctx context.Context, query string, limit int) ([]CoinMatch, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = DefaultCoinSearchLimit
+	}
+	limit = min(limit, MaxCoinSearchLimit)
+
+	coins, err := s.api.ListCoins()
+	if err != nil {
+		return nil, err
+	}
+
+	// The catalog is ordered by rank, so each tier fills up in rank order
+	var tiers [noMatch][]Coin
+	for _, coin := range coins {
+		tier := matchCoin(q, coin)
+		if tier == noMatch || len(tiers[tier]) == limit {
+			continue
+		}
+		tiers[tier] = append(tiers[tier], coin)
+		if tier == matchPrefix && len(tiers[matchPrefix]) == limit {
+			break // fuzzy matches can't make it into the result anymore
+		}
+	}
+	found := slices.Concat(tiers[:]...)
+	found = found[:min(len(found), limit)]
+	if len(found) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(found))
+	for i, coin := range found {
+		ids[i] = coin.ID
+	}
+	currs, err := s.repo.GetCurrencies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]CoinMatch, len(found))
+	for i, coin := range found {
+		matches[i].Coin = coin
+		for _, cur := range currs {
+			if cur.ProviderID == coin.ID {
+				matches[i].Tracked = true
+				matches[i].Status = string(cur.Status)
+			}
+		}
+	}
+	return matches, nil
+}
+
+func matchCoin(q string, coin Coin) matchTier {
+	symbol := strings.ToLower(coin.Symbol)
+	name := strings.ToLower(coin.Name)
+	if strings.HasPrefix(symbol, q) || strings.HasPrefix(name, q) {
+		return matchPrefix
+	}
+	for _, word := range strings.Fields(name) {
+		if strings.HasPrefix(word, q) {
+			return matchPrefix
+		}
+	}
+
+	if len(q) < minFuzzyQuery {
+		return noMatch
+	}
+	if strings.Contains(name, q) || isSubsequence(q, name) || isSubsequence(q, symbol) {
+		return matchFuzzy
+	}
+	// Typos in the first letters of the symbol or name
+	if editDistance(q, symbol) <= 1 || editDistance(q, name[:min(len(name), len(q))]) <= 1 {
+		return matchFuzzy
+	}
+	return noMatch
+}
+
+// Whether all bytes of q appear in s in order
+func isSubsequence(q, s string) bool {
+	i := 0
+	for j := 0; i < len(q) && j < len(s); j++ {
+		if q[i] == s[j] {
+			i++
+		}
+	}
+	return i == len(q)
+}
+
+// Levenshtein distance over bytes
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// Coin or token listed by the price provider
+type Coin struct {
+	ID     string // provider coin ID, e.g. "btc-bitcoin"
+	Symbol string
+	Name   string
+	Rank   int    // market cap rank, 0 when unranked
+	Type   string // "coin" or "token"
+}
+
+// Returned when a symbol matches several provider coins. Candidates are ordered by rank,
+// adding one of them takes its ID
+type AmbiguousSymbolError struct {
+	Symbol     string
+	Candidates []Coin
+}
+
+func (e *AmbiguousSymbolError) Error() string {
+	return fmt.Sprintf("%s: %s matches %d coins", ErrAmbiguousSymbol, e.Symbol, len(e.Candidates))
+}
+
+func (e *AmbiguousSymbolError) Is(target error) bool {
+	return target == ErrAmbiguousSymbol
+}
+
+// Finds the provider coin to track. An explicit ID wins, otherwise the symbol must name exactly one coin
+func (s *cryptoService) resolveCoin(symbol, coinID string) (*Coin, error) {
+	if coinID != "" {
+		coin, err := s.api.CoinByID(coinID)
+		if err != nil {
+			return nil, err
+		}
+		if symbol != "" && !strings.EqualFold(symbol, coin.Symbol) {
+			return nil, domain.ErrInvalidSymbol // the ID belongs to another symbol
+		}
+		return coin, nil
+	}
+
+	coins, err := s.api.CoinsBySymbol(symbol)
+	if err != nil {
+		s.log.Error("checking symbol failed", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("failed to verify symbol: %w", err)
+	}
+	if len(coins) == 0 {
+		return nil, domain.ErrInvalidSymbol
+	}
+	if coin := soleCoin(coins); coin != nil {
+		return coin, nil
+	}
+	return nil, &AmbiguousSymbolError{Symbol: strings.ToUpper(symbol), Candidates: coins}
+}
+
+// Coin a symbol names on its own: its only entry of type coin, tokens sharing the symbol are
+// ignored like symbols always resolved. A symbol listing no coin names its only token.
+// Nil when several coins, or several tokens and no coin, share the symbol
+func soleCoin(coins []Coin) *Coin {
+	var found *Coin
+	for i := range coins {
+		if coins[i].Type != "coin" {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = &coins[i]
+	}
+	if found == nil && len(coins) == 1 {
+		found = &coins[0]
+	}
+	return found
+}
+
+// Replaces the placeholder IDs of currencies added before provider IDs were stored with the coin
+// their symbol used to resolve to, the best ranked one of type coin. Covers every status, so
+// paused and archived currencies come back with the right coin. Currencies whose symbol is no
+// longer listed keep the placeholder until a later start
+func (s *cryptoService) ResolveLegacyCoins(ctx context.Context) error {
+	const pageSize = 100
+	for offset := 0; ; offset += pageSize {
+		page, err := s.repo.ListCurrencies(ctx, pageSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, cur := range page {
+			if cur.HasLegacyProviderID() {
+				s.resolveLegacyCoin(ctx, cur)
+			}
+		}
+		if len(page) < pageSize {
+			return nil
+		}
+	}
+}
+
+func (s *cryptoService) resolveLegacyCoin(ctx context.Context, cur *domain.Currency) {
+	log := s.log.With("symbol", cur.Symbol, "currency_id", cur.ID)
+	coins, err := s.api.CoinsBySymbol(cur.Symbol)
+	if err != nil {
+		log.Error("resolve legacy coin failed", "error", err)
+		return
+	}
+	if len(coins) == 0 {
+		log.Warn("symbol is no longer listed, keeping the placeholder coin ID", "id", cur.ProviderID)
+		return
+	}
+	coin := coins[0]
+	for _, c := range coins {
+		if c.Type == "coin" {
+			coin = c
+			break
+		}
+	}
+
+	cur.ProviderID = coin.ID
+	if err := s.repo.UpdateCurrency(ctx, cur); err != nil {
+		// ErrDuplicateCurrency when the coin was added again under its ID
+		log.Error("store legacy coin ID failed", "id", coin.ID, "error", err)
+		return
+	}
+	log.Info("resolved legacy coin ID", "id", coin.ID, "candidates", len(coins))
+}
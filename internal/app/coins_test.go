@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+)
+
+// Provider catalog without prices
+type fakeCatalog struct {
+	coins []Coin
+}
+
+func (f *fakeCatalog) FetchPrice(ctx context.Context, coinID string) (*PricePoint, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (f *fakeCatalog) CoinsBySymbol(symbol string) ([]Coin, error) {
+	var found []Coin
+	for _, c := range f.coins {
+		if strings.EqualFold(c.Symbol, symbol) {
+			found = append(found, c)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeCatalog) CoinByID(id string) (*Coin, error) {
+	for _, c := range f.coins {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, ErrUnknownCoin
+}
+
+func (f *fakeCatalog) ListCoins() ([]Coin, error) { return f.coins, nil }
+
+func TestResolveCoin(t *testing.T) {
+	catalog := &fakeCatalog{coins: []Coin{
+		{ID: "btc-bitcoin", Symbol: "BTC", Rank: 1, Type: "coin"},
+		{ID: "btc-bitcoin-token", Symbol: "BTC", Rank: 900, Type: "token"},
+		{ID: "uni-uniswap", Symbol: "UNI", Rank: 22, Type: "token"},
+		{ID: "uni-universe", Symbol: "UNI", Rank: 800, Type: "token"},
+		{ID: "ens-ethereum-name-service", Symbol: "ENS", Rank: 90, Type: "token"},
+		{ID: "hot-holo", Symbol: "HOT", Rank: 150, Type: "coin"},
+		{ID: "hot-hydro-protocol", Symbol: "HOT", Rank: 2000, Type: "coin"},
+	}}
+	s := &cryptoService{api: catalog, log: logger.New(logger.Config{Level: "error"})}
+
+	tests := []struct {
+		name       string
+		symbol, id string
+		want       string // resolved coin ID
+		candidates int    // > 0 when ambiguous
+		err        error
+	}{
+		{name: "single coin", symbol: "BTC", want: "btc-bitcoin"},
+		// Tokens sharing the symbol of a coin are ignored, as when symbols mapped to coins only
+		{name: "coin among tokens", symbol: "btc", want: "btc-bitcoin"},
+		{name: "single token", symbol: "ENS", want: "ens-ethereum-name-service"},
+		{name: "several tokens", symbol: "UNI", candidates: 2},
+		// Used to track the best ranked coin, now the caller has to pick one
+		{name: "several coins", symbol: "HOT", candidates: 2},
+		{name: "unknown symbol", symbol: "NOPE", err: domain.ErrInvalidSymbol},
+		{name: "explicit ID", id: "uni-universe", want: "uni-universe"},
+		{name: "explicit ID with its symbol", symbol: "UNI", id: "uni-uniswap", want: "uni-uniswap"},
+		{name: "explicit ID of another symbol", symbol: "BTC", id: "uni-uniswap", err: domain.ErrInvalidSymbol},
+		{name: "unknown ID", id: "nope-nope", err: ErrUnknownCoin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coin, err := s.resolveCoin(tt.symbol, tt.id)
+			var ambiguous *AmbiguousSymbolError
+			switch {
+			case tt.candidates > 0:
+				if !errors.As(err, &ambiguous) || !errors.Is(err, ErrAmbiguousSymbol) {
+					t.Fatalf("error %v, want an ambiguous symbol", err)
+				}
+				if len(ambiguous.Candidates) != tt.candidates {
+					t.Errorf("%d candidates, want %d", len(ambiguous.Candidates), tt.candidates)
+				}
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("error %v, want %v", err, tt.err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case coin.ID != tt.want:
+				t.Errorf("resolved %s, want %s", coin.ID, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// Reports missing fetches of a symbol against the configured fetch interval, or against
+// the heartbeat for deadband currencies. The window is cut at the current time, the future
+// can't be covered yet
+func (s *cryptoService) GetCoverage(ctx context.Context, symbol, quote string, from, to time.Time) (*domain.Coverage, error) {
+	if now := time.Now().UTC(); to.After(now) {
+		to = now
+	}
+	if to.Before(from) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+	quote, err := domain.NormalizeQuote(quote)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := s.fetchInterval(cur)
+	if cur.Deadband != nil {
+		interval = max(interval, cur.Deadband.Heartbeat)
+	}
+	// Streamed, only the gaps are kept while the window is read page by page
+	acc, err := domain.NewCoverageAccumulator(from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.eachSnapshot(ctx, cur, quote, from, to, acc.Add); err != nil {
+		return nil, err
+	}
+	return acc.Coverage(), nil
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetCoverageStreamsPages(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := newFakeSeriesRepo(start, time.Minute, 3*MaxHistoryPageSize)
+	// An hour missing in the second page and the tail of the window without prices
+	repo.snaps = append(repo.snaps[:MaxHistoryPageSize+100], repo.snaps[MaxHistoryPageSize+160:]...)
+	s := &cryptoService{repo: repo, cfg: Config{FetchInterval: time.Minute}}
+
+	end := start.Add(time.Duration(3*MaxHistoryPageSize+30) * time.Minute)
+	cov, err := s.GetCoverage(context.Background(), "btc-bitcoin", "USD", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cov.Count != len(repo.snaps) || len(cov.Gaps) != 2 {
+		t.Fatalf("count %d, %d gaps, want %d and 2: %+v", cov.Count, len(cov.Gaps), len(repo.snaps), cov.Gaps)
+	}
+	hole := cov.Gaps[0]
+	if hole.Missing != 60 || hole.Duration() != 61*time.Minute {
+		t.Errorf("hole = %+v, want 60 missing over 61m", hole)
+	}
+	if tail := cov.Gaps[1]; tail.Missing != 31 || !tail.To.Equal(end) {
+		t.Errorf("tail = %+v, want 31 missing up to the window end", tail)
+	}
+	if cov.Missing != 91 || cov.LongestGap != 61*time.Minute {
+		t.Errorf("missing %d, longest %s, want 91 and 1h1m", cov.Missing, cov.LongestGap)
+	}
+	if repo.largest > MaxHistoryPageSize {
+		t.Errorf("loaded %d snapshots at once, want at most %d", repo.largest, MaxHistoryPageSize)
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// One page of a cross-rate series
+type CrossRatePage struct {
+	Points     []*domain.CrossRatePoint
+	NextCursor string // empty when the window is exhausted
+}
+
+// Prices `base` in units of `quote` at `at` from their USD series, resolved with one batch lookup
+func (s *cryptoService) GetCrossRate(
+	ctx context.Context,
+	base, quote string,
+	at time.Time,
+	opts LookupOptions,
+) (*domain.CrossRate, error) {
+	opts.Quote = domain.DefaultQuote
+	results, err := s.GetPrices(ctx, []domain.PriceQuery{
+		{Symbol: base, At: at},
+		{Symbol: quote, At: at},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range results {
+		if res.Err != nil {
+			return nil, res.Err
+		}
+	}
+	return domain.NewCrossRate(results[0].Lookup, results[1].Lookup)
+}
+
+// Pages through the base series and pairs each point with the nearest quote snapshot.
+// Points without a quote snapshot within the lookup tolerance are left out
+func (s *cryptoService) GetCrossRateHistory(
+	ctx context.Context,
+	base, quote string,
+	from, to time.Time,
+	cursor string,
+	limit int,
+) (*CrossRatePage, error) {
+	quoteCur, err := s.repo.GetCurrency(ctx, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := s.GetPriceHistory(ctx, base, domain.DefaultQuote, from, to, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := &CrossRatePage{NextCursor: page.NextCursor}
+	if len(page.Snapshots) == 0 {
+		return out, nil
+	}
+
+	tolerance := s.cfg.MaxPriceDistance
+	if tolerance <= 0 {
+		tolerance = s.fetchInterval(quoteCur)
+	}
+
+	// A deadband quote holds its price until the next stored one
+	mode := quoteCur.Deadband.StepMode(domain.LookupNearest)
+	if quoteCur.Deadband != nil {
+		tolerance = max(tolerance, quoteCur.Deadband.MaxGap(s.fetchInterval(quoteCur)))
+	}
+
+	first := page.Snapshots[0].Timestamp.Add(-tolerance)
+	last := page.Snapshots[len(page.Snapshots)-1].Timestamp.Add(tolerance)
+	quotes, err := s.loadSnapshots(ctx, quoteCur, domain.DefaultQuote, first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	out.Points = domain.AlignCrossRates(page.Snapshots, quotes, tolerance, mode)
+	return out, nil
+}
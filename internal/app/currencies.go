@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultCurrencyPageSize = 50
+	MaxCurrencyPageSize     = 500
+)
+
+type CurrencyListOptions struct {
+	Prefix string
+	Status domain.CurrencyStatus // empty lists every status
+	Sort   domain.CurrencySort
+	Desc   bool
+	Quote  string // quote currency of the latest prices, defaults to domain.DefaultQuote
+	Cursor string
+	Limit  int
+}
+
+// Tracked currency with its most recent stored price
+type CurrencyListing struct {
+	Currency *domain.Currency
+	Latest   *domain.PriceSnapshot // nil when nothing is stored in the quote currency
+}
+
+type CurrencyPage struct {
+	Quote      string // quote currency of the latest prices
+	Currencies []CurrencyListing
+	NextCursor string // empty on the last page
+}
+
+// Lists tracked currencies in keyset pages. The latest prices of a page are read in one batch lookup
+func (s *cryptoService) ListCurrencies(ctx context.Context, opts CurrencyListOptions) (*CurrencyPage, error) {
+	quote, err := domain.NormalizeQuote(opts.Quote)
+	if err != nil {
+		return nil, err
+	}
+	sort, err := domain.ParseCurrencySort(string(opts.Sort))
+	if err != nil {
+		return nil, err
+	}
+	if opts.Status != "" {
+		if _, err := domain.ParseCurrencyStatus(string(opts.Status)); err != nil {
+			return nil, err
+		}
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultCurrencyPageSize
+	}
+	limit = min(limit, MaxCurrencyPageSize)
+
+	q := domain.CurrencyQuery{
+		Prefix: strings.ToUpper(strings.TrimSpace(opts.Prefix)),
+		Status: opts.Status,
+		Sort:   sort,
+		Desc:   opts.Desc,
+		Limit:  limit + 1, // one extra row tells whether another page exists
+	}
+	if opts.Cursor != "" {
+		if q.After, err = decodeCurrencyCursor(opts.Cursor, sort); err != nil {
+			return nil, err
+		}
+	}
+
+	currs, err := s.repo.FindCurrencies(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	page := &CurrencyPage{Quote: quote}
+	if len(currs) > limit {
+		currs = currs[:limit]
+		page.NextCursor = encodeCurrencyCursor(currs[limit-1], sort)
+	}
+	if len(currs) == 0 {
+		return page, nil
+	}
+
+	now := time.Now().UTC()
+	queries := make([]domain.PriceQuery, len(currs))
+	for i, cur := range currs {
+		queries[i] = domain.PriceQuery{Symbol: cur.ProviderID, Quote: quote, At: now}
+	}
+	found, err := s.repo.GetPriceSnapshots(ctx, queries, domain.LookupBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Currencies = make([]CurrencyListing, len(currs))
+	for i, cur := range currs {
+		page.Currencies[i].Currency = cur
+		switch res := found[i]; {
+		case res.Err == nil:
+			page.Currencies[i].Latest = res.Lookup.Sources[0]
+		case errors.Is(res.Err, domain.ErrPriceNotFound), errors.Is(res.Err, domain.ErrNotTracked):
+			// Nothing stored yet, or purged since the page was read
+		default:
+			return nil, res.Err
+		}
+	}
+	return page, nil
+}
+
+// Cursors wrap the sort order with the key of the last returned row,
+// so a cursor can't be reused with another order
+func encodeCurrencyCursor(cur *domain.Currency, sort domain.CurrencySort) string {
+	raw := string(sort) + ":" + cur.Symbol + ":" + cur.ProviderID
+	if sort == domain.SortByCreated {
+		raw = string(sort) + ":" + strconv.FormatInt(cur.CreatedAt.UnixMicro(), 10) + ":" + cur.ID.String()
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCurrencyCursor(cursor string, sort domain.CurrencySort) (*domain.CurrencyKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// Placeholder provider IDs contain a colon themselves
+	parts := strings.SplitN(string(raw), ":", 3)
+	if domain.CurrencySort(parts[0]) != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	switch {
+	case sort == domain.SortBySymbol && len(parts) == 3 && parts[1] != "" && parts[2] != "":
+		return &domain.CurrencyKey{Symbol: parts[1], ProviderID: parts[2]}, nil
+	case sort == domain.SortByCreated && len(parts) == 3:
+		micros, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		id, err := uuid.Parse(parts[2])
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return &domain.CurrencyKey{CreatedAt: time.UnixMicro(micros).UTC(), ID: id}, nil
+	default:
+		return nil, ErrInvalidCursor
+	}
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Serves GetCurrencies from memory, every other method panics
+type fakeCurrencyRepo struct {
+	domain.CryptoRepository
+	currs []*domain.Currency
+}
+
+func (f *fakeCurrencyRepo) GetCurrencies(ctx context.Context, refs []string) ([]*domain.Currency, error) {
+	var found []*domain.Currency
+	for _, cur := range f.currs {
+		for _, ref := range refs {
+			if cur.Matches(ref) {
+				found = append(found, cur)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func TestCurrencyCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	tests := []struct {
+		name string
+		cur  *domain.Currency
+		sort domain.CurrencySort
+		want domain.CurrencyKey
+	}{
+		{
+			"symbol", &domain.Currency{Symbol: "UNI", ProviderID: "uni-uniswap"},
+			domain.SortBySymbol, domain.CurrencyKey{Symbol: "UNI", ProviderID: "uni-uniswap"},
+		},
+		{
+			"legacy placeholder", &domain.Currency{Symbol: "BTC", ProviderID: "legacy:btc"},
+			domain.SortBySymbol, domain.CurrencyKey{Symbol: "BTC", ProviderID: "legacy:btc"},
+		},
+		{
+			"created", &domain.Currency{ID: uuid.MustParse("6f1c1f9e-8d43-4a56-9a3e-2f0b7c1d5e42"), CreatedAt: created},
+			domain.SortByCreated, domain.CurrencyKey{ID: uuid.MustParse("6f1c1f9e-8d43-4a56-9a3e-2f0b7c1d5e42"), CreatedAt: created},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := decodeCurrencyCursor(encodeCurrencyCursor(tt.cur, tt.sort), tt.sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.Symbol != tt.want.Symbol || key.ProviderID != tt.want.ProviderID ||
+				key.ID != tt.want.ID || !key.CreatedAt.Equal(tt.want.CreatedAt) {
+				t.Fatalf("key = %+v, want %+v", *key, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeCurrencyCursorRejects(t *testing.T) {
+	symbolCursor := encodeCurrencyCursor(&domain.Currency{Symbol: "UNI", ProviderID: "uni-uniswap"}, domain.SortBySymbol)
+	tests := []struct {
+		name   string
+		cursor string
+		sort   domain.CurrencySort
+	}{
+		{"not base64", "%%%", domain.SortBySymbol},
+		{"other sort", symbolCursor, domain.SortByCreated},
+		{"symbol only", encodeRaw("symbol:UNI"), domain.SortBySymbol},
+		{"empty provider ID", encodeRaw("symbol:UNI:"), domain.SortBySymbol},
+		{"bad timestamp", encodeRaw("created:x:" + uuid.NewString()), domain.SortByCreated},
+		{"bad ID", encodeRaw("created:1:x"), domain.SortByCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCurrencyCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func encodeRaw(raw string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func TestLookupCurrencies(t *testing.T) {
+	uniswap := &domain.Currency{Symbol: "UNI", ProviderID: "uni-uniswap"}
+	universe := &domain.Currency{Symbol: "UNI", ProviderID: "uni-universe"}
+	btc := &domain.Currency{Symbol: "BTC", ProviderID: "btc-bitcoin"}
+	s := &cryptoService{repo: &fakeCurrencyRepo{currs: []*domain.Currency{uniswap, universe, btc}}}
+
+	got, err := s.lookupCurrencies(context.Background(), []string{"BTC", "UNI", "uni-universe", "UNI-UNISWAP", "ETH", "BTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]*domain.Currency{
+		"BTC":          btc,
+		"uni-universe": universe,
+		"UNI-UNISWAP":  uniswap,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d currencies, want %d: %v", len(got), len(want), got)
+	}
+	for ref, cur := range want {
+		if got[ref] != cur {
+			t.Errorf("%s: got %v, want %s", ref, got[ref], cur.ProviderID)
+		}
+	}
+	if _, ok := got["UNI"]; ok {
+		t.Error("ambiguous symbol resolved")
+	}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Latest stored price per (currency, quote), deadband decisions compare against it
+type storedPrices struct {
+	mu   sync.Mutex
+	last map[storedKey]*domain.PriceSnapshot
+}
+
+type storedKey struct {
+	currency uuid.UUID
+	quote    string
+}
+
+func newStoredPrices() *storedPrices {
+	return &storedPrices{last: make(map[storedKey]*domain.PriceSnapshot)}
+}
+
+func (p *storedPrices) get(curID uuid.UUID, quote string) (*domain.PriceSnapshot, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	snap, ok := p.last[storedKey{curID, quote}]
+	return snap, ok
+}
+
+// Keeps the newest snapshot of the series
+func (p *storedPrices) put(snap *domain.PriceSnapshot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	key := storedKey{snap.CurrencyID, snap.Quote}
+	if prev, ok := p.last[key]; !ok || prev == nil || snap.Timestamp.After(prev.Timestamp) {
+		p.last[key] = snap
+	}
+}
+
+// Drops every series of a purged currency
+func (p *storedPrices) forget(curID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key := range p.last {
+		if key.currency == curID {
+			delete(p.last, key)
+		}
+	}
+}
+
+// Turns deadband storage on for a currency, nil stores every fetched price again
+func (s *cryptoService) SetDeadband(ctx context.Context, symbol string, band *domain.Deadband) (*domain.Currency, error) {
+	if band != nil {
+		if err := band.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	cur.Deadband = band
+	if err := s.repo.UpdateCurrency(ctx, cur); err != nil {
+		return nil, err
+	}
+	s.wakeScheduler()
+	return cur, nil
+}
+
+// Checks a fetched price against the last stored one of its series. The last price
+// is read once from storage and then tracked in memory. Errors store the price
+func (s *cryptoService) withinDeadband(ctx context.Context, cur *domain.Currency, snap *domain.PriceSnapshot) bool {
+	if cur.Deadband == nil {
+		return false
+	}
+	last, ok := s.stored.get(cur.ID, snap.Quote)
+	if !ok {
+		lookup, err := s.repo.GetPriceSnapshot(ctx, cur.ProviderID, snap.Quote, snap.Timestamp, domain.LookupBefore)
+		switch {
+		case err == nil:
+			last = lookup.Sources[0]
+			s.stored.put(last)
+		case errors.Is(err, domain.ErrPriceNotFound):
+		default:
+			s.log.Warn("read last stored price failed", "symbol", cur.Symbol, "quote", snap.Quote, "error", err)
+			return false
+		}
+	}
+	return !cur.Deadband.ShouldStore(last, snap)
+}
+
+// Tracked currencies named by `refs`, symbols or provider IDs, keyed by the reference.
+// Their deadband and interval shape lookups
+func (s *cryptoService) lookupCurrencies(ctx context.Context, refs []string) (map[string]*domain.Currency, error) {
+	unique := make([]string, 0, len(refs))
+	seen := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		if !seen[ref] {
+			seen[ref] = true
+			unique = append(unique, ref)
+		}
+	}
+
+	currs, err := s.repo.GetCurrencies(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	// Untracked and ambiguous references are reported by the lookups themselves
+	byRef := make(map[string]*domain.Currency, len(unique))
+	for _, ref := range unique {
+		var matches []*domain.Currency
+		for _, cur := range currs {
+			if strings.EqualFold(ref, cur.ProviderID) {
+				matches = []*domain.Currency{cur}
+				break
+			}
+			if cur.Matches(ref) {
+				matches = append(matches, cur)
+			}
+		}
+		if len(matches) == 1 {
+			byRef[ref] = matches[0]
+		}
+	}
+	return byRef, nil
+}
+
+// Deadband of a currency that may be untracked
+func deadbandOf(cur *domain.Currency) *domain.Deadband {
+	if cur == nil {
+		return nil
+	}
+	return cur.Deadband
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// One stored currency, records the references it is looked up by. Every other method panics
+type fakeCurrencyStore struct {
+	domain.CryptoRepository
+	cur     *domain.Currency
+	refs    []string
+	updated int
+}
+
+func (f *fakeCurrencyStore) GetCurrency(ctx context.Context, ref string) (*domain.Currency, error) {
+	f.refs = append(f.refs, ref)
+	if !f.cur.Matches(ref) {
+		return nil, domain.ErrNotTracked
+	}
+	return f.cur, nil
+}
+
+func (f *fakeCurrencyStore) UpdateCurrency(ctx context.Context, cur *domain.Currency) error {
+	f.updated++
+	return nil
+}
+
+func newCurrencyStoreService() (*cryptoService, *fakeCurrencyStore) {
+	repo := &fakeCurrencyStore{cur: &domain.Currency{
+		ID: uuid.New(), Symbol: "UNI", ProviderID: "uni-uniswap", Status: domain.CurrencyActive,
+	}}
+	return &cryptoService{
+		repo:         repo,
+		log:          logger.New(logger.Config{Level: "error"}),
+		stored:       newStoredPrices(),
+		scheduleWake: make(chan struct{}, 1),
+	}, repo
+}
+
+// The reference reaches the repository as given, it decides how symbols and coin IDs match
+func TestSetDeadbandCurrencyRef(t *testing.T) {
+	band := &domain.Deadband{ThresholdPercent: 0.5, Heartbeat: time.Hour}
+	for _, ref := range []string{"uni-uniswap", "UNI"} {
+		s, repo := newCurrencyStoreService()
+		cur, err := s.SetDeadband(context.Background(), ref, band)
+		if err != nil {
+			t.Fatalf("%s: %v", ref, err)
+		}
+		if len(repo.refs) != 1 || repo.refs[0] != ref {
+			t.Errorf("looked up %v, want [%s]", repo.refs, ref)
+		}
+		if cur.Deadband != band || repo.updated != 1 {
+			t.Errorf("%s: deadband %v stored %d times", ref, cur.Deadband, repo.updated)
+		}
+	}
+
+	s, _ := newCurrencyStoreService()
+	if _, err := s.SetDeadband(context.Background(), "uni-other", band); !errors.Is(err, domain.ErrNotTracked) {
+		t.Errorf("unknown coin: err = %v, want ErrNotTracked", err)
+	}
+}
@@ -3,5 +3,12 @@ package app
 import "errors"
 
 var (
-	ErrFetchFailed = errors.New("fetching prices failed")
+	ErrFetchFailed   = errors.New("fetching prices failed")
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+	ErrAmbiguousSymbol = errors.New("symbol matches several coins, pick one by provider_id")
+	ErrUnknownCoin     = errors.New("unknown provider coin")
+
+	ErrInvalidImportFormat = errors.New("invalid import format")
+	ErrInvalidExportFormat = errors.New("invalid export format")
 )
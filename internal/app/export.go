@@ -0,0 +1,398 @@
+package app
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/parquet-go/parquet-go"
+	"github.com/shopspring/decimal"
+)
+
+// File formats written by PriceExport
+type ExportFormat string
+
+const (
+	ExportCSV     ExportFormat = "csv"
+	ExportNDJSON  ExportFormat = "ndjson"
+	ExportParquet ExportFormat = "parquet"
+)
+
+const (
+	// Precision of the NUMERIC(40, 20) price column, so every stored price fits the Parquet decimal
+	exportPricePrecision = 40
+	// Rows buffered before a Parquet row group is written out
+	parquetRowGroupSize = 50_000
+)
+
+func ParseExportFormat(raw string) (ExportFormat, error) {
+	switch f := ExportFormat(strings.ToLower(strings.TrimSpace(raw))); f {
+	case ExportCSV, ExportNDJSON, ExportParquet:
+		return f, nil
+	case "jsonl":
+		return ExportNDJSON, nil
+	default:
+		return "", ErrInvalidExportFormat
+	}
+}
+
+// Guesses the format and compression from a file name like prices.csv.gz
+func ExportFormatFromName(name string) (format ExportFormat, gzipped bool, ok bool) {
+	if ext := filepath.Ext(name); ext == ".gz" {
+		gzipped = true
+		name = strings.TrimSuffix(name, ext)
+	}
+	f, err := ParseExportFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+	return f, gzipped, err == nil
+}
+
+// File name suffix, Parquet compresses its pages instead of the whole file
+func (f ExportFormat) Extension(gzipped bool) string {
+	ext := "." + string(f)
+	if gzipped && f != ExportParquet {
+		ext += ".gz"
+	}
+	return ext
+}
+
+func (f ExportFormat) ContentType(gzipped bool) string {
+	switch {
+	case f == ExportParquet:
+		return "application/vnd.apache.parquet"
+	case gzipped:
+		return "application/gzip"
+	case f == ExportNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "text/csv"
+	}
+}
+
+type ExportOptions struct {
+	Symbols []string
+	Quote   string // defaults to domain.DefaultQuote
+	From    time.Time
+	To      time.Time
+	Format  ExportFormat
+	Gzip    bool
+}
+
+// Export whose symbols are resolved, ready to be streamed with Write
+type PriceExport struct {
+	repo       domain.CryptoRepository
+	opts       ExportOptions
+	currencies []*domain.Currency
+}
+
+// Validates the options and resolves the symbols before anything is written,
+// so callers can still report errors properly
+func (s *cryptoService) ExportPrices(ctx context.Context, opts ExportOptions) (*PriceExport, error) {
+	if opts.To.Before(opts.From) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+	switch opts.Format {
+	case ExportCSV, ExportNDJSON, ExportParquet:
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+	quote, err := domain.NormalizeQuote(opts.Quote)
+	if err != nil {
+		return nil, err
+	}
+	opts.Quote = quote
+
+	exp := &PriceExport{repo: s.repo, opts: opts}
+	seen := make(map[string]bool, len(opts.Symbols))
+	for _, raw := range opts.Symbols {
+		symbol := strings.ToUpper(strings.TrimSpace(raw))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		cur, err := s.repo.GetCurrency(ctx, symbol)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", symbol, err)
+		}
+		exp.currencies = append(exp.currencies, cur)
+	}
+	if len(exp.currencies) == 0 {
+		return nil, domain.ErrInvalidSymbol
+	}
+	return exp, nil
+}
+
+// Streams the raw prices of every symbol in turn, each ordered by timestamp, and
+// returns the number of rows written. Rollups of pruned history are not included
+func (e *PriceExport) Write(ctx context.Context, w io.Writer) (int64, error) {
+	bw := bufio.NewWriterSize(w, 64*1024)
+	out := io.Writer(bw)
+	var zw *gzip.Writer
+	if e.opts.Gzip && e.opts.Format != ExportParquet {
+		zw = gzip.NewWriter(bw)
+		out = zw
+	}
+
+	enc, err := e.newEncoder(out)
+	if err != nil {
+		return 0, err
+	}
+	var rows int64
+	for _, cur := range e.currencies {
+		err := e.repo.StreamPriceSnapshots(ctx, cur.ID, e.opts.Quote, e.opts.From, e.opts.To, func(snap *domain.PriceSnapshot) error {
+			if err := enc.write(cur, snap); err != nil {
+				return err
+			}
+			rows++
+			return nil
+		})
+		if err != nil {
+			return rows, fmt.Errorf("export %s: %w", cur.Symbol, err)
+		}
+	}
+
+	if err := enc.close(); err != nil {
+		return rows, err
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return rows, err
+		}
+	}
+	return rows, bw.Flush()
+}
+
+// Row encoder of one export format
+type exportEncoder interface {
+	write(cur *domain.Currency, snap *domain.PriceSnapshot) error
+	close() error
+}
+
+func (e *PriceExport) newEncoder(w io.Writer) (exportEncoder, error) {
+	switch e.opts.Format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportColumns); err != nil {
+			return nil, err
+		}
+		return &csvExportEncoder{w: cw}, nil
+	case ExportNDJSON:
+		return &ndjsonExportEncoder{enc: json.NewEncoder(w)}, nil
+	case ExportParquet:
+		opts, err := e.parquetOptions()
+		if err != nil {
+			return nil, err
+		}
+		return &parquetExportEncoder{w: parquet.NewGenericWriter[parquetExportRow](w, opts...)}, nil
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+}
+
+// Column order shared by every format
+var exportColumns = []string{
+	"symbol", "currency_id", "quote", "timestamp", "price", "fetched_at",
+	"volume_24h", "market_cap", "percent_change_1h", "percent_change_24h", "percent_change_7d",
+	"circulating_supply", "rank",
+}
+
+// Parquet row, timestamps are UTC milliseconds and the price an exact decimal.
+// Nil pointers are written as null, and so is an unknown fetch time
+type parquetExportRow struct {
+	Symbol            string   `parquet:"symbol"`
+	CurrencyID        string   `parquet:"currency_id"`
+	Quote             string   `parquet:"quote"`
+	Timestamp         int64    `parquet:"timestamp,timestamp(millisecond)"`
+	Price             [17]byte `parquet:"price,decimal(20:40)"`
+	FetchedAt         int64    `parquet:"fetched_at,optional,timestamp(millisecond)"` // 0 when unknown
+	Volume24h         *float64 `parquet:"volume_24h,optional"`
+	MarketCap         *float64 `parquet:"market_cap,optional"`
+	PercentChange1h   *float64 `parquet:"percent_change_1h,optional"`
+	PercentChange24h  *float64 `parquet:"percent_change_24h,optional"`
+	PercentChange7d   *float64 `parquet:"percent_change_7d,optional"`
+	CirculatingSupply *float64 `parquet:"circulating_supply,optional"`
+	Rank              *int64   `parquet:"rank,optional"`
+}
+
+// Row group size, page compression and the symbols and requested range stored as JSON in the footer
+func (e *PriceExport) parquetOptions() ([]parquet.WriterOption, error) {
+	type symbolMeta struct {
+		Symbol       string `json:"symbol"`
+		CurrencyID   string `json:"currency_id"`
+		TrackedSince string `json:"tracked_since"`
+	}
+	symbols := make([]symbolMeta, len(e.currencies))
+	for i, cur := range e.currencies {
+		symbols[i] = symbolMeta{Symbol: cur.Symbol, CurrencyID: cur.ID.String(), TrackedSince: cur.CreatedAt.UTC().Format(time.RFC3339)}
+	}
+	symbolsJSON, err := json.Marshal(symbols)
+	if err != nil {
+		return nil, err
+	}
+	opts := []parquet.WriterOption{
+		parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		parquet.KeyValueMetadata("crypto_tracker.symbols", string(symbolsJSON)),
+		parquet.KeyValueMetadata("crypto_tracker.quote", e.opts.Quote),
+		parquet.KeyValueMetadata("crypto_tracker.from", e.opts.From.UTC().Format(time.RFC3339)),
+		parquet.KeyValueMetadata("crypto_tracker.to", e.opts.To.UTC().Format(time.RFC3339)),
+	}
+	if e.opts.Gzip {
+		opts = append(opts, parquet.Compression(&parquet.Gzip))
+	}
+	return opts, nil
+}
+
+type csvExportEncoder struct {
+	w      *csv.Writer
+	record []string
+}
+
+func (c *csvExportEncoder) write(cur *domain.Currency, snap *domain.PriceSnapshot) error {
+	m := snap.Market
+	c.record = append(c.record[:0],
+		cur.Symbol,
+		cur.ID.String(),
+		snap.Quote,
+		snap.Timestamp.UTC().Format(time.RFC3339),
+		snap.Price.String(),
+		formatExportTime(snap.FetchedAt),
+		formatExportFloat(m.Volume24h),
+		formatExportFloat(m.MarketCap),
+		formatExportFloat(m.PercentChange1h),
+		formatExportFloat(m.PercentChange24h),
+		formatExportFloat(m.PercentChange7d),
+		formatExportFloat(m.CirculatingSupply),
+		"",
+	)
+	if m.Rank != nil {
+		c.record[len(c.record)-1] = strconv.Itoa(*m.Rank)
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvExportEncoder) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatExportFloat(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*v, 'f', -1, 64)
+}
+
+type ndjsonExportEncoder struct {
+	enc *json.Encoder
+}
+
+type ndjsonExportRow struct {
+	Symbol            string          `json:"symbol"`
+	CurrencyID        string          `json:"currency_id"`
+	Quote             string          `json:"quote"`
+	Timestamp         string          `json:"timestamp"`
+	Price             decimal.Decimal `json:"price"`
+	FetchedAt         string          `json:"fetched_at,omitempty"`
+	Volume24h         *float64        `json:"volume_24h,omitempty"`
+	MarketCap         *float64        `json:"market_cap,omitempty"`
+	PercentChange1h   *float64        `json:"percent_change_1h,omitempty"`
+	PercentChange24h  *float64        `json:"percent_change_24h,omitempty"`
+	PercentChange7d   *float64        `json:"percent_change_7d,omitempty"`
+	CirculatingSupply *float64        `json:"circulating_supply,omitempty"`
+	Rank              *int            `json:"rank,omitempty"`
+}
+
+func (n *ndjsonExportEncoder) write(cur *domain.Currency, snap *domain.PriceSnapshot) error {
+	m := snap.Market
+	return n.enc.Encode(ndjsonExportRow{
+		Symbol:            cur.Symbol,
+		CurrencyID:        cur.ID.String(),
+		Quote:             snap.Quote,
+		Timestamp:         snap.Timestamp.UTC().Format(time.RFC3339),
+		Price:             snap.Price,
+		FetchedAt:         formatExportTime(snap.FetchedAt),
+		Volume24h:         m.Volume24h,
+		MarketCap:         m.MarketCap,
+		PercentChange1h:   m.PercentChange1h,
+		PercentChange24h:  m.PercentChange24h,
+		PercentChange7d:   m.PercentChange7d,
+		CirculatingSupply: m.CirculatingSupply,
+		Rank:              m.Rank,
+	})
+}
+
+func (n *ndjsonExportEncoder) close() error {
+	return nil
+}
+
+type parquetExportEncoder struct {
+	w   *parquet.GenericWriter[parquetExportRow]
+	row [1]parquetExportRow
+}
+
+func (p *parquetExportEncoder) write(cur *domain.Currency, snap *domain.PriceSnapshot) error {
+	price, err := parquetDecimal(snap.Price)
+	if err != nil {
+		return err
+	}
+	m := snap.Market
+	row := parquetExportRow{
+		Symbol:            cur.Symbol,
+		CurrencyID:        cur.ID.String(),
+		Quote:             snap.Quote,
+		Timestamp:         snap.Timestamp.UnixMilli(),
+		Price:             price,
+		Volume24h:         m.Volume24h,
+		MarketCap:         m.MarketCap,
+		PercentChange1h:   m.PercentChange1h,
+		PercentChange24h:  m.PercentChange24h,
+		PercentChange7d:   m.PercentChange7d,
+		CirculatingSupply: m.CirculatingSupply,
+	}
+	if !snap.FetchedAt.IsZero() {
+		row.FetchedAt = snap.FetchedAt.UnixMilli()
+	}
+	if m.Rank != nil {
+		rank := int64(*m.Rank)
+		row.Rank = &rank
+	}
+	p.row[0] = row
+	_, err = p.w.Write(p.row[:])
+	return err
+}
+
+func (p *parquetExportEncoder) close() error {
+	return p.w.Close()
+}
+
+// Unscaled price as the big-endian two's complement the decimal(40, 20) column stores,
+// rounded to the column scale. Prices with more integer digits than the column holds are rejected
+func parquetDecimal(d decimal.Decimal) ([17]byte, error) {
+	var out [17]byte
+	unscaled := d.Round(domain.PriceScale).Shift(domain.PriceScale).BigInt()
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(exportPricePrecision), nil)
+	if new(big.Int).Abs(unscaled).Cmp(limit) >= 0 {
+		return out, fmt.Errorf("price %s overflows decimal(%d, %d)", d, exportPricePrecision, domain.PriceScale)
+	}
+	if unscaled.Sign() < 0 {
+		unscaled.Add(unscaled, new(big.Int).Lsh(big.NewInt(1), 8*uint(len(out))))
+	}
+	unscaled.FillBytes(out[:])
+	return out, nil
+}
@@ -0,0 +1,260 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+	"github.com/shopspring/decimal"
+)
+
+// Streams fixed snapshots per currency, every other method panics
+type fakeExportRepo struct {
+	domain.CryptoRepository
+	snaps map[uuid.UUID][]*domain.PriceSnapshot
+}
+
+func (f *fakeExportRepo) StreamPriceSnapshots(ctx context.Context, currencyID uuid.UUID, quote string, start, end time.Time, fn func(*domain.PriceSnapshot) error) error {
+	for _, snap := range f.snaps[currencyID] {
+		if err := fn(snap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func exportSnapshot(cur *domain.Currency, ts time.Time, price string) *domain.PriceSnapshot {
+	return &domain.PriceSnapshot{CurrencyID: cur.ID, Quote: "USD", Timestamp: ts, Price: decimal.RequireFromString(price)}
+}
+
+func TestParquetExportRoundTrip(t *testing.T) {
+	ts := time.Date(2025, 1, 2, 3, 4, 5, 678_000_000, time.UTC)
+	btc := &domain.Currency{ID: uuid.New(), Symbol: "BTC", ProviderID: "btc-bitcoin", CreatedAt: ts.AddDate(-1, 0, 0)}
+	eth := &domain.Currency{ID: uuid.New(), Symbol: "ETH", ProviderID: "eth-ethereum", CreatedAt: ts.AddDate(0, -1, 0)}
+
+	full := exportSnapshot(btc, ts, "97123.45678901234567890123")
+	full.FetchedAt = ts.Add(time.Second)
+	full.Market = domain.MarketData{
+		Volume24h:         ptr(1.25e9),
+		MarketCap:         ptr(1.9e12),
+		PercentChange1h:   ptr(-0.5),
+		PercentChange24h:  ptr(0.0),
+		PercentChange7d:   ptr(3.25),
+		CirculatingSupply: ptr(19.8e6),
+		Rank:              ptr(1),
+	}
+	repo := &fakeExportRepo{snaps: map[uuid.UUID][]*domain.PriceSnapshot{
+		btc.ID: {
+			full,
+			exportSnapshot(btc, ts.Add(time.Minute), "0.00000000000000000001"),
+			// 20 integer digits, more than a 16 byte decimal(38, 20) holds
+			exportSnapshot(btc, ts.Add(2*time.Minute), "99999999999999999999.99999999999999999999"),
+		},
+		eth.ID: {
+			exportSnapshot(eth, ts, "0"),
+			exportSnapshot(eth, ts.Add(time.Minute), "1.123456789012345678905"), // rounded to the scale
+		},
+	}}
+	wantPrices := []string{
+		"97123.45678901234567890123", "0.00000000000000000001", "99999999999999999999.99999999999999999999",
+		"0", "1.12345678901234567891",
+	}
+
+	for _, gz := range []bool{false, true} {
+		exp := &PriceExport{
+			repo:       repo,
+			opts:       ExportOptions{Quote: "USD", From: ts, To: ts.Add(time.Hour), Format: ExportParquet, Gzip: gz},
+			currencies: []*domain.Currency{btc, eth},
+		}
+		var buf bytes.Buffer
+		n, err := exp.Write(context.Background(), &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(wantPrices)) {
+			t.Fatalf("gzip %v: wrote %d rows, want %d", gz, n, len(wantPrices))
+		}
+
+		rows, err := parquet.Read[parquetExportRow](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != len(wantPrices) {
+			t.Fatalf("gzip %v: read %d rows, want %d", gz, len(rows), len(wantPrices))
+		}
+		for i, row := range rows {
+			got := decodeParquetDecimal(row.Price[:])
+			if want := decimal.RequireFromString(wantPrices[i]); !got.Equal(want) {
+				t.Errorf("gzip %v: row %d price = %s, want %s", gz, i, got, want)
+			}
+		}
+
+		first := rows[0]
+		if first.Symbol != "BTC" || first.CurrencyID != btc.ID.String() || first.Quote != "USD" || first.Timestamp != ts.UnixMilli() {
+			t.Errorf("first row = %+v", first)
+		}
+		if first.FetchedAt != full.FetchedAt.UnixMilli() {
+			t.Errorf("fetched_at = %d, want %d", first.FetchedAt, full.FetchedAt.UnixMilli())
+		}
+		if first.Rank == nil || *first.Rank != 1 || first.Volume24h == nil || *first.Volume24h != 1.25e9 ||
+			first.PercentChange24h == nil || *first.PercentChange24h != 0 {
+			t.Errorf("market data = %+v", first)
+		}
+		last := rows[len(rows)-1]
+		if last.Symbol != "ETH" || last.FetchedAt != 0 || last.Rank != nil || last.Volume24h != nil || last.CirculatingSupply != nil {
+			t.Errorf("missing values not null: %+v", last)
+		}
+	}
+}
+
+func TestParquetExportSchema(t *testing.T) {
+	cur := &domain.Currency{ID: uuid.New(), Symbol: "BTC", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	exp := &PriceExport{
+		repo: &fakeExportRepo{snaps: map[uuid.UUID][]*domain.PriceSnapshot{
+			cur.ID: {exportSnapshot(cur, from, "1.5")},
+		}},
+		opts:       ExportOptions{Quote: "EUR", From: from, To: from.Add(time.Hour), Format: ExportParquet, Gzip: true},
+		currencies: []*domain.Currency{cur},
+	}
+	var buf bytes.Buffer
+	if _, err := exp.Write(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, field := range f.Schema().Fields() {
+		names = append(names, field.Name())
+	}
+	if strings.Join(names, ",") != strings.Join(exportColumns, ",") {
+		t.Errorf("columns = %v, want %v", names, exportColumns)
+	}
+
+	price, ok := f.Schema().Lookup("price")
+	if !ok {
+		t.Fatal("no price column")
+	}
+	typ := price.Node.Type()
+	if typ.Kind() != parquet.FixedLenByteArray || typ.Length() != 17 {
+		t.Errorf("price stored as %s(%d), want a 17 byte FIXED_LEN_BYTE_ARRAY", typ.Kind(), typ.Length())
+	}
+	if lt := typ.LogicalType(); lt == nil || lt.Decimal == nil || lt.Decimal.Precision != 40 || lt.Decimal.Scale != 20 {
+		t.Errorf("price logical type = %v, want decimal(40, 20)", lt)
+	}
+	ts, _ := f.Schema().Lookup("timestamp")
+	if lt := ts.Node.Type().LogicalType(); lt == nil || lt.Timestamp == nil || !lt.Timestamp.IsAdjustedToUTC || lt.Timestamp.Unit.Millis == nil {
+		t.Errorf("timestamp logical type = %v, want UTC milliseconds", lt)
+	}
+	// The snapshot has no fetch time or market data, their columns hold nulls
+	rows := make([]parquet.Row, 1)
+	if n, _ := f.RowGroups()[0].Rows().ReadRows(rows); n != 1 {
+		t.Fatalf("read %d rows, want 1", n)
+	}
+	for _, v := range rows[0] {
+		if optional := f.Schema().Fields()[v.Column()].Optional(); optional != v.IsNull() {
+			t.Errorf("column %s: null %v, want %v", exportColumns[v.Column()], v.IsNull(), optional)
+		}
+	}
+	for _, col := range f.Metadata().RowGroups[0].Columns {
+		if col.MetaData.Codec != format.Gzip {
+			t.Errorf("column %v compressed with %s, want gzip", col.MetaData.PathInSchema, col.MetaData.Codec)
+		}
+	}
+
+	meta := map[string]string{}
+	for _, kv := range f.Metadata().KeyValueMetadata {
+		meta[kv.Key] = kv.Value
+	}
+	if meta["crypto_tracker.quote"] != "EUR" || meta["crypto_tracker.from"] != "2025-01-01T00:00:00Z" || meta["crypto_tracker.to"] != "2025-01-01T01:00:00Z" {
+		t.Errorf("metadata = %v", meta)
+	}
+	var symbols []map[string]string
+	if err := json.Unmarshal([]byte(meta["crypto_tracker.symbols"]), &symbols); err != nil {
+		t.Fatal(err)
+	}
+	if len(symbols) != 1 || symbols[0]["symbol"] != "BTC" || symbols[0]["currency_id"] != cur.ID.String() ||
+		symbols[0]["tracked_since"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("symbols metadata = %v", symbols)
+	}
+}
+
+func TestParquetDecimal(t *testing.T) {
+	tests := []struct {
+		price string
+		ok    bool
+	}{
+		{"0", true},
+		{"1.5", true},
+		{"-42", true},
+		{"99999999999999999999.99999999999999999999", true},
+		{"-99999999999999999999.99999999999999999999", true},
+		{"99999999999999999999.999999999999999999995", false}, // rounds up past the precision
+		{"100000000000000000000", false},
+	}
+	for _, tt := range tests {
+		d := decimal.RequireFromString(tt.price)
+		b, err := parquetDecimal(d)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok %v", tt.price, err, tt.ok)
+			continue
+		}
+		if tt.ok {
+			if got := decodeParquetDecimal(b[:]); !got.Equal(d) {
+				t.Errorf("%s: decoded %s", tt.price, got)
+			}
+		}
+	}
+}
+
+// Decodes a big-endian two's complement decimal(40, 20)
+func decodeParquetDecimal(b []byte) decimal.Decimal {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return decimal.NewFromBigInt(n, -domain.PriceScale)
+}
+
+// Row groups are written out as they fill, an export never buffers more than one
+func TestParquetExportRowGroups(t *testing.T) {
+	cur := &domain.Currency{ID: uuid.New(), Symbol: "BTC"}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	snaps := make([]*domain.PriceSnapshot, 2*parquetRowGroupSize+1)
+	for i := range snaps {
+		snaps[i] = exportSnapshot(cur, from.Add(time.Duration(i)*time.Second), "1")
+	}
+	exp := &PriceExport{
+		repo:       &fakeExportRepo{snaps: map[uuid.UUID][]*domain.PriceSnapshot{cur.ID: snaps}},
+		opts:       ExportOptions{Quote: "USD", From: from, To: from.AddDate(0, 0, 7), Format: ExportParquet},
+		currencies: []*domain.Currency{cur},
+	}
+	var buf bytes.Buffer
+	if _, err := exp.Write(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sizes []int64
+	for _, rg := range f.RowGroups() {
+		sizes = append(sizes, rg.NumRows())
+	}
+	if len(sizes) != 3 || sizes[0] != parquetRowGroupSize || sizes[2] != 1 {
+		t.Errorf("row groups of %v rows, want %d, %d, 1", sizes, parquetRowGroupSize, parquetRowGroupSize)
+	}
+}
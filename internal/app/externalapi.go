@@ -2,61 +2,87 @@ package app
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/shopspring/decimal"
 )
 
 // Holds the fetched data
 type PricePoint struct {
-	Symbol    string  // e.g. "BTC"
-	Price     float64 // USD
-	Timestamp int64   // Unix seconds when fetched
+	Symbol    string           // e.g. "BTC"
+	Quotes    map[string]Quote // keyed by quote currency, e.g. "USD", "EUR"
+	Timestamp int64            // Unix seconds when the provider last updated the ticker
+	FetchedAt int64            // Unix seconds when fetched
+}
+
+// Ticker data in one quote currency
+type Quote struct {
+	Price  decimal.Decimal
+	Market domain.MarketData
 }
 
 type ExternalPriceAPI interface {
-	FetchPrice(ctx context.Context, symbol string) (*PricePoint, error)
-	SymbolExists(symbol string) (bool, error)
+	FetchPrice(ctx context.Context, coinID string) (*PricePoint, error)
+	// Active coins listed under the symbol, best ranked first
+	CoinsBySymbol(symbol string) ([]Coin, error)
+	// ErrUnknownCoin when the ID isn't listed
+	CoinByID(id string) (*Coin, error)
+	// Every active coin, best ranked first. The slice is shared and must not be modified
+	ListCoins() ([]Coin, error)
+}
+
+// Past price in a single quote currency
+type HistoricalTick struct {
+	Timestamp time.Time
+	Price     decimal.Decimal
+	Market    domain.MarketData // only volume and market cap are reported
 }
 
-func (s *cryptoService) FetchAndStorePrices(ctx context.Context) error {
-	const pageSize = 100
-	offset := 0
+// Optional capability of providers that serve historical ticks
+type HistoricalPriceAPI interface {
+	// Returns one page of ticks in [start, end] spaced by interval, ordered by time
+	FetchHistory(ctx context.Context, coinID, quote string, start, end time.Time, interval time.Duration) ([]HistoricalTick, error)
+}
 
-	for {
-		currs, err := s.repo.ListCurrencies(ctx, pageSize, offset)
+// Fetches the current ticker of one currency and stores a snapshot per quote currency.
+// Only a failed fetch is returned, snapshots that can't be stored are logged and skipped
+func (s *cryptoService) fetchAndStore(ctx context.Context, cur *domain.Currency) error {
+	pt, err := s.api.FetchPrice(ctx, cur.ProviderID)
+	if err != nil {
+		return err
+	}
+
+	ts := time.Unix(pt.Timestamp, 0).UTC()
+	fetchedAt := time.Unix(pt.FetchedAt, 0).UTC()
+	for quote, q := range pt.Quotes {
+		snap, err := domain.NewPriceSnapshot(cur.ID, quote, ts, q.Price)
 		if err != nil {
-			return fmt.Errorf("FetchAndStorePrices: list currencies: %w", err)
+			s.log.Error("invalid price snapshot", "symbol", cur.Symbol, "quote", quote, "error", err)
+			continue
 		}
-		if len(currs) == 0 {
-			break // no more rows
+		snap.Market = q.Market
+		snap.FetchedAt = fetchedAt
+
+		if s.withinDeadband(ctx, cur, snap) {
+			s.log.Debug("price within deadband", "symbol", cur.Symbol, "quote", quote, "price", snap.Price)
+			continue
 		}
 
-		for _, cur := range currs {
-			pt, err := s.api.FetchPrice(ctx, cur.Symbol)
-			if err != nil {
-				s.log.Error("fetch price failed", "symbol", cur.Symbol, "error", err)
-				continue
-			}
-
-			ts := time.Unix(pt.Timestamp, 0).UTC()
-			snap, err := domain.NewPriceSnapshot(cur.ID, ts, pt.Price)
-			if err != nil {
-				s.log.Error("invalid price snapshot", "symbol", cur.Symbol, "error", err)
-				continue
-			}
-
-			if err := s.repo.SavePriceSnapshot(ctx, snap); err != nil {
-				s.log.Error("save snapshot failed", "symbol", cur.Symbol, "timestamp", snap.Timestamp, "error", err)
-				continue
-			}
-
-			s.log.Debug("saved snapshot", "symbol", cur.Symbol, "price", snap.Price, "timestamp", snap.Timestamp)
+		err = s.repo.SavePriceSnapshot(ctx, snap)
+		if errors.Is(err, domain.ErrDuplicatePrice) {
+			// Provider hasn't updated the ticker since the last fetch
+			s.log.Debug("snapshot already stored", "symbol", cur.Symbol, "quote", quote, "timestamp", snap.Timestamp)
+			continue
+		}
+		if err != nil {
+			s.log.Error("save snapshot failed", "symbol", cur.Symbol, "quote", quote, "timestamp", snap.Timestamp, "error", err)
+			continue
 		}
 
-		offset += pageSize
+		s.stored.put(snap)
+		s.log.Debug("saved snapshot", "symbol", cur.Symbol, "quote", quote, "price", snap.Price, "timestamp", snap.Timestamp)
 	}
-
 	return nil
 }
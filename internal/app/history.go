@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+const (
+	DefaultHistoryPageSize = 500
+	MaxHistoryPageSize     = 5000
+)
+
+// One page of ordered snapshots for a time window
+type HistoryPage struct {
+	Snapshots  []*domain.PriceSnapshot
+	NextCursor string // empty when the window is exhausted
+}
+
+func (s *cryptoService) GetPriceHistory(
+	ctx context.Context,
+	symbol, quote string,
+	from, to time.Time,
+	cursor string,
+	limit int,
+) (*HistoryPage, error) {
+	if to.Before(from) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+	quote, err := domain.NormalizeQuote(quote)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultHistoryPageSize
+	}
+	if limit > MaxHistoryPageSize {
+		limit = MaxHistoryPageSize
+	}
+
+	// Keyset pagination: timestamps are unique per currency,
+	// so the next page starts right after the last returned second
+	start := from
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		if after.Before(from) || after.After(to) {
+			return nil, ErrInvalidCursor
+		}
+		start = after.Add(time.Second)
+	}
+
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether another page exists
+	snaps, err := s.listSnapshots(ctx, cur, quote, start, to, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &HistoryPage{Snapshots: snaps}
+	if len(snaps) > limit {
+		page.Snapshots = snaps[:limit]
+		page.NextCursor = encodeCursor(snaps[limit-1].Timestamp)
+	}
+	return page, nil
+}
+
+// Cursor is an opaque token wrapping the Unix timestamp of the last returned row
+func encodeCursor(ts time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(ts.Unix(), 10)))
+}
+
+func decodeCursor(cursor string) (time.Time, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	sec, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidCursor
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// Loads every snapshot within [from, to], paging through the repository
+func (s *cryptoService) loadSnapshots(ctx context.Context, cur *domain.Currency, quote string, from, to time.Time) ([]*domain.PriceSnapshot, error) {
+	var all []*domain.PriceSnapshot
+	err := s.eachSnapshot(ctx, cur, quote, from, to, func(snap *domain.PriceSnapshot) {
+		all = append(all, snap)
+	})
+	return all, err
+}
+
+// Calls fn for every snapshot within [from, to] in timestamp order.
+// Only one page of MaxHistoryPageSize snapshots is held at a time
+func (s *cryptoService) eachSnapshot(ctx context.Context, cur *domain.Currency, quote string, from, to time.Time, fn func(*domain.PriceSnapshot)) error {
+	start := from
+	for {
+		snaps, err := s.listSnapshots(ctx, cur, quote, start, to, MaxHistoryPageSize)
+		if err != nil {
+			return err
+		}
+		for _, snap := range snaps {
+			fn(snap)
+		}
+		if len(snaps) < MaxHistoryPageSize {
+			return nil
+		}
+		start = snaps[len(snaps)-1].Timestamp.Add(time.Second)
+	}
+}
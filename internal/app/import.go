@@ -0,0 +1,446 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// File formats accepted by ImportPrices
+type ImportFormat string
+
+const (
+	ImportCSV    ImportFormat = "csv"    // symbol,timestamp,price[,quote] with an optional header row
+	ImportNDJSON ImportFormat = "ndjson" // one {"symbol","timestamp","price","quote"} object per line
+)
+
+const (
+	DefaultImportBatchSize = 1000
+	MaxImportSize          = 256 << 20 // largest file accepted over HTTP
+	// Longest NDJSON line buffered, longer lines are skipped and rejected
+	maxImportLine   = 64 << 10
+	maxImportErrors = 100 // rejected rows listed in a report, the rest are only counted
+)
+
+func ParseImportFormat(raw string) (ImportFormat, error) {
+	switch f := ImportFormat(strings.ToLower(strings.TrimSpace(raw))); f {
+	case ImportCSV, ImportNDJSON:
+		return f, nil
+	case "jsonl":
+		return ImportNDJSON, nil
+	default:
+		return "", ErrInvalidImportFormat
+	}
+}
+
+// Guesses the format from a file extension, false when it isn't a known one
+func ImportFormatFromName(name string) (ImportFormat, bool) {
+	f, err := ParseImportFormat(strings.TrimPrefix(filepath.Ext(name), "."))
+	return f, err == nil
+}
+
+type ImportOptions struct {
+	Format     ImportFormat
+	OnConflict domain.ConflictPolicy // defaults to domain.ConflictSkip
+	BatchSize  int                   // defaults to DefaultImportBatchSize
+}
+
+// Rejected input row, Line is 1-based and counts the CSV header
+type ImportRowError struct {
+	Line   int
+	Reason string
+}
+
+// Outcome of an import. Rows = Accepted + Rejected once the whole input was read,
+// rows of a batch that failed to store are in neither
+type ImportReport struct {
+	Rows     int   // data rows read
+	Accepted int   // valid rows handed to storage
+	Rejected int   // rows failing to parse or validate
+	Written  int64 // prices inserted or overwritten
+	Skipped  int64 // accepted rows left alone, already stored or repeated in the input
+	From     time.Time
+	To       time.Time
+	Errors   []ImportRowError // first rejections, up to 100
+}
+
+func (r *ImportReport) reject(line int, reason error) {
+	r.Rejected++
+	if len(r.Errors) < maxImportErrors {
+		r.Errors = append(r.Errors, ImportRowError{Line: line, Reason: reason.Error()})
+	}
+}
+
+// Reads prices from CSV or NDJSON, validates every row as a snapshot of a tracked currency
+// and stores them in batches. Invalid rows are rejected and reported without stopping the import.
+// With domain.ConflictFail the import stops at the first batch holding an already stored price,
+// batches stored before it are kept
+func (s *cryptoService) ImportPrices(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.OnConflict == "" {
+		opts.OnConflict = domain.ConflictSkip
+	}
+	if _, err := domain.ParseConflictPolicy(string(opts.OnConflict)); err != nil {
+		return nil, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultImportBatchSize
+	}
+	next, err := newImportReader(r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{}
+	batch := newImportBatch(opts.OnConflict)
+	currencies := make(map[string]importCurrency)
+	importedAt := time.Now().UTC()
+
+	flush := func() error {
+		if len(batch.snaps) == 0 {
+			return nil
+		}
+		n, err := s.storeImportBatch(ctx, batch)
+		if err != nil {
+			return fmt.Errorf("lines %d-%d: %w", batch.firstLine, batch.lastLine, err)
+		}
+		report.Accepted += batch.rows
+		report.Written += n
+		report.Skipped += int64(batch.rows) - n
+		batch.reset()
+		return nil
+	}
+
+	for {
+		row, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return report, fmt.Errorf("read line %d: %w", row.line, err)
+		}
+		report.Rows++
+		if row.err != nil {
+			report.reject(row.line, row.err)
+			continue
+		}
+
+		res, ok := currencies[row.symbol]
+		if !ok {
+			res.cur, res.err = s.repo.GetCurrency(ctx, row.symbol)
+			if res.err != nil && !errors.Is(res.err, domain.ErrNotTracked) && !errors.Is(res.err, domain.ErrAmbiguousCurrency) {
+				return report, fmt.Errorf("line %d: %w", row.line, res.err)
+			}
+			currencies[row.symbol] = res
+		}
+		if res.err != nil {
+			report.reject(row.line, fmt.Errorf("%s: %w", row.symbol, res.err))
+			continue
+		}
+		cur := res.cur
+
+		snap, err := domain.NewPriceSnapshot(cur.ID, row.quote, row.timestamp, row.price)
+		if err != nil {
+			report.reject(row.line, err)
+			continue
+		}
+		snap.FetchedAt = importedAt
+		if err := batch.add(snap, row.line); err != nil {
+			return report, err
+		}
+		if report.From.IsZero() || snap.Timestamp.Before(report.From) {
+			report.From = snap.Timestamp
+		}
+		if snap.Timestamp.After(report.To) {
+			report.To = snap.Timestamp
+		}
+
+		if batch.rows >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return report, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return report, err
+	}
+
+	// Imported history may already be past raw retention
+	if report.Written > 0 {
+		if err := s.rollupRange(ctx, report.From, report.To); err != nil {
+			return report, fmt.Errorf("roll up imported prices: %w", err)
+		}
+	}
+	s.log.Info("prices imported",
+		"rows", report.Rows, "accepted", report.Accepted, "rejected", report.Rejected,
+		"written", report.Written, "on_conflict", opts.OnConflict)
+	return report, nil
+}
+
+// Makes sure the months of the batch have partitions, old history would land in the default one
+func (s *cryptoService) storeImportBatch(ctx context.Context, b *importBatch) (int64, error) {
+	if repo, ok := s.repo.(domain.PartitionRepository); ok {
+		if _, err := repo.EnsurePricePartitions(ctx, b.from, b.to); err != nil {
+			return 0, fmt.Errorf("create partitions: %w", err)
+		}
+	}
+	return s.repo.SavePriceSnapshots(ctx, b.snaps, b.onConflict)
+}
+
+// Snapshots waiting to be stored. A key may only appear once per insert statement,
+// so repeats inside the input are resolved here by the conflict policy
+type importBatch struct {
+	onConflict domain.ConflictPolicy
+	snaps      []*domain.PriceSnapshot
+	seen       map[importKey]importSlot
+	rows       int
+	firstLine  int
+	lastLine   int
+	from, to   time.Time
+}
+
+type importKey struct {
+	currency uuid.UUID
+	quote    string
+	ts       int64
+}
+
+type importSlot struct {
+	index int // position in snaps
+	line  int // input line of the kept row
+}
+
+func newImportBatch(onConflict domain.ConflictPolicy) *importBatch {
+	b := &importBatch{onConflict: onConflict}
+	b.reset()
+	return b
+}
+
+func (b *importBatch) reset() {
+	b.snaps = b.snaps[:0]
+	b.seen = make(map[importKey]importSlot)
+	b.rows = 0
+	b.firstLine, b.lastLine = 0, 0
+	b.from, b.to = time.Time{}, time.Time{}
+}
+
+func (b *importBatch) add(snap *domain.PriceSnapshot, line int) error {
+	key := importKey{currency: snap.CurrencyID, quote: snap.Quote, ts: snap.Timestamp.Unix()}
+	if slot, ok := b.seen[key]; ok {
+		switch b.onConflict {
+		case domain.ConflictFail:
+			return fmt.Errorf("line %d repeats line %d: %w", line, slot.line, domain.ErrDuplicatePrice)
+		case domain.ConflictOverwrite:
+			b.snaps[slot.index] = snap
+			b.seen[key] = importSlot{index: slot.index, line: line}
+		}
+	} else {
+		b.seen[key] = importSlot{index: len(b.snaps), line: line}
+		b.snaps = append(b.snaps, snap)
+	}
+
+	b.rows++
+	if b.firstLine == 0 {
+		b.firstLine = line
+	}
+	b.lastLine = line
+	if b.from.IsZero() || snap.Timestamp.Before(b.from) {
+		b.from = snap.Timestamp
+	}
+	if snap.Timestamp.After(b.to) {
+		b.to = snap.Timestamp
+	}
+	return nil
+}
+
+// Currency a symbol of the input resolved to, err is set for untracked and ambiguous symbols
+type importCurrency struct {
+	cur *domain.Currency
+	err error
+}
+
+// One parsed input row. err is set when the row itself is malformed,
+// the reader returns an error only when the input can't be read any further
+type importRow struct {
+	line      int
+	symbol    string
+	quote     string
+	timestamp time.Time
+	price     decimal.Decimal
+	err       error
+}
+
+type importReader func() (importRow, error)
+
+func newImportReader(r io.Reader, format ImportFormat) (importReader, error) {
+	switch format {
+	case ImportCSV:
+		return newCSVImportReader(r), nil
+	case ImportNDJSON:
+		return newNDJSONImportReader(r), nil
+	default:
+		return nil, ErrInvalidImportFormat
+	}
+}
+
+// Columns are matched by name when the first row is a header, otherwise they are
+// read in the order symbol, timestamp, price, quote
+func newCSVImportReader(r io.Reader) importReader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	cr.ReuseRecord = true
+
+	cols := map[string]int{"symbol": 0, "timestamp": 1, "price": 2, "quote": 3}
+	first := true
+	return func() (importRow, error) {
+		for {
+			rec, err := cr.Read()
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return importRow{line: parseErr.Line, err: parseErr.Err}, nil
+			}
+			if err != nil {
+				return importRow{}, err
+			}
+			line, _ := cr.FieldPos(0)
+
+			if first {
+				first = false
+				if header, ok := csvHeader(rec); ok {
+					cols = header
+					continue
+				}
+			}
+			if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+				continue // blank line
+			}
+
+			field := func(name string) string {
+				if i, ok := cols[name]; ok && i < len(rec) {
+					return strings.TrimSpace(rec[i])
+				}
+				return ""
+			}
+			return parseImportRow(line, field("symbol"), field("quote"), field("timestamp"), field("price")), nil
+		}
+	}
+}
+
+// Column positions when the record is a header naming at least symbol, timestamp and price
+func csvHeader(rec []string) (map[string]int, bool) {
+	cols := make(map[string]int, len(rec))
+	for i, name := range rec {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"symbol", "timestamp", "price"} {
+		if _, ok := cols[required]; !ok {
+			return nil, false
+		}
+	}
+	return cols, true
+}
+
+func newNDJSONImportReader(r io.Reader) importReader {
+	br := bufio.NewReaderSize(r, 64*1024)
+	line := 0
+	return func() (importRow, error) {
+		for {
+			buf, tooLong, err := readNDJSONLine(br, maxImportLine)
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return importRow{}, io.EOF
+				}
+				return importRow{line: line + 1}, err
+			}
+			line++
+			if tooLong {
+				return importRow{line: line, err: fmt.Errorf("line longer than %d bytes", maxImportLine)}, nil
+			}
+			raw := bytes.TrimSpace(buf)
+			if len(raw) == 0 {
+				continue
+			}
+
+			var obj struct {
+				Symbol    string          `json:"symbol"`
+				Quote     string          `json:"quote"`
+				Timestamp json.RawMessage `json:"timestamp"`
+				Price     json.RawMessage `json:"price"`
+			}
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return importRow{line: line, err: fmt.Errorf("invalid JSON: %w", err)}, nil
+			}
+			// Numbers and strings are both accepted for timestamp and price
+			ts := strings.Trim(string(obj.Timestamp), `"`)
+			price := strings.Trim(string(obj.Price), `"`)
+			return parseImportRow(line, obj.Symbol, obj.Quote, ts, price), nil
+		}
+	}
+}
+
+// Reads one line without its newline. A line over `limit` bytes is skipped up to its end and
+// reported as tooLong, so one bad line doesn't end the import. io.EOF only comes after the last line
+func readNDJSONLine(br *bufio.Reader, limit int) (line []byte, tooLong bool, err error) {
+	read := false
+	for {
+		chunk, err := br.ReadSlice('\n')
+		read = read || len(chunk) > 0
+		if !tooLong {
+			line = append(line, chunk...)
+			if len(bytes.TrimSuffix(line, []byte("\n"))) > limit {
+				tooLong, line = true, nil
+			}
+		}
+		switch {
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case errors.Is(err, io.EOF) && read:
+			return line, tooLong, nil // last line without a trailing newline
+		case err != nil:
+			return nil, false, err
+		}
+		return bytes.TrimSuffix(line, []byte("\n")), tooLong, nil
+	}
+}
+
+func parseImportRow(line int, symbol, quote, ts, price string) importRow {
+	row := importRow{line: line, symbol: strings.ToUpper(strings.TrimSpace(symbol)), quote: quote}
+	if row.symbol == "" {
+		row.err = errors.New("missing symbol")
+		return row
+	}
+	var err error
+	if row.timestamp, err = parseImportTime(ts); err != nil {
+		row.err = err
+		return row
+	}
+	if row.price, err = decimal.NewFromString(strings.TrimSpace(price)); err != nil {
+		row.err = fmt.Errorf("invalid price %q", price)
+	}
+	return row
+}
+
+// Accepts Unix seconds or RFC 3339
+func parseImportTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if sec, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q, want Unix seconds or RFC 3339", raw)
+}
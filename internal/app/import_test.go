@@ -0,0 +1,101 @@
+package app
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadNDJSONLine(t *testing.T) {
+	type result struct {
+		line    string
+		tooLong bool
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []result
+	}{
+		{"lines", "a\nbb\n", []result{{"a", false}, {"bb", false}}},
+		{"no trailing newline", "a\nbb", []result{{"a", false}, {"bb", false}}},
+		{"blank lines kept", "\n\na\n", []result{{"", false}, {"", false}, {"a", false}}},
+		{"exactly the limit", "12345678\n", []result{{"12345678", false}}},
+		// Longer than the 16 byte read buffer, skipped to its end and followed by the next line
+		{"too long", "x" + strings.Repeat("y", 40) + "\nok\n", []result{{"", true}, {"ok", false}}},
+		{"too long at the end", "ok\n123456789", []result{{"ok", false}, {"", true}}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := bufio.NewReaderSize(strings.NewReader(tt.input), 16)
+			for i, w := range tt.want {
+				line, tooLong, err := readNDJSONLine(br, 8)
+				if err != nil {
+					t.Fatalf("line %d: %v", i+1, err)
+				}
+				if string(line) != w.line || tooLong != w.tooLong {
+					t.Errorf("line %d = %q (too long %v), want %q (%v)", i+1, line, tooLong, w.line, w.tooLong)
+				}
+			}
+			if _, _, err := readNDJSONLine(br, 8); !errors.Is(err, io.EOF) {
+				t.Errorf("after the last line: error %v, want EOF", err)
+			}
+		})
+	}
+}
+
+func TestNDJSONImportReader(t *testing.T) {
+	input := `{"symbol":"btc","timestamp":1700000000,"price":"37000.5"}
+
+{"symbol":"ETH","timestamp":"2023-11-14T22:13:20Z","price":2000,"quote":"EUR"}
+not json
+{"symbol":"","timestamp":1700000000,"price":"1"}
+`
+	next := newNDJSONImportReader(strings.NewReader(input))
+	want := []struct {
+		line   int
+		symbol string
+		bad    bool
+	}{
+		{1, "BTC", false},
+		{3, "ETH", false},
+		{4, "", true},
+		{5, "", true},
+	}
+	for _, w := range want {
+		row, err := next()
+		if err != nil {
+			t.Fatalf("line %d: %v", w.line, err)
+		}
+		if row.line != w.line || row.symbol != w.symbol || (row.err != nil) != w.bad {
+			t.Errorf("row = line %d symbol %q err %v, want line %d symbol %q rejected %v",
+				row.line, row.symbol, row.err, w.line, w.symbol, w.bad)
+		}
+	}
+	if _, err := next(); !errors.Is(err, io.EOF) {
+		t.Errorf("after the last row: error %v, want EOF", err)
+	}
+}
+
+func TestNDJSONImportReaderOverlongLine(t *testing.T) {
+	long := `{"symbol":"BTC","timestamp":1700000000,"price":"1","pad":"` + strings.Repeat("x", maxImportLine) + `"}`
+	input := long + "\n" + `{"symbol":"ETH","timestamp":1700000000,"price":"2"}` + "\n"
+	next := newNDJSONImportReader(strings.NewReader(input))
+
+	row, err := next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.line != 1 || row.err == nil {
+		t.Fatalf("row = line %d err %v, want line 1 rejected", row.line, row.err)
+	}
+	row, err = next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.line != 2 || row.symbol != "ETH" || row.err != nil {
+		t.Fatalf("row = line %d symbol %q err %v, want line 2 ETH", row.line, row.symbol, row.err)
+	}
+}
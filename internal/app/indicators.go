@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/internal/indicators"
+)
+
+// Computes an indicator over candle closes resampled to `bucket`.
+// The window is extended backwards by the warm-up so the series starts at `from`
+func (s *cryptoService) GetIndicator(
+	ctx context.Context,
+	symbol, quote, name string,
+	params indicators.Params,
+	from, to time.Time,
+	bucket time.Duration,
+) (*indicators.Series, error) {
+	warmup, err := indicators.Warmup(name, params)
+	if err != nil {
+		return nil, err
+	}
+
+	candles, err := s.GetCandles(ctx, symbol, quote, from.Add(-time.Duration(warmup)*bucket), to, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	times, closes := resampleCloses(candles, bucket)
+	series, err := indicators.Compute(name, params, times, closes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the warm-up rows that precede the requested window
+	size := int64(bucket / time.Second)
+	first := time.Unix(from.Unix()/size*size, 0)
+	i := 0
+	for i < len(series.Points) && series.Points[i].Time.Before(first) {
+		i++
+	}
+	series.Points = series.Points[i:]
+	return series, nil
+}
+
+// Evenly spaced close prices, empty buckets carry the previous close forward.
+// Indicators are statistical, so closes are converted to float64 here.
+func resampleCloses(candles []*domain.Candle, bucket time.Duration) ([]time.Time, []float64) {
+	var times []time.Time
+	var closes []float64
+	for i, cd := range candles {
+		if i > 0 {
+			prev := candles[i-1]
+			for t := prev.Start.Add(bucket); t.Before(cd.Start); t = t.Add(bucket) {
+				times = append(times, t)
+				closes = append(closes, prev.Close.InexactFloat64())
+			}
+		}
+		times = append(times, cd.Start)
+		closes = append(closes, cd.Close.InexactFloat64())
+	}
+	return times, closes
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// How long a purge token can be used to confirm the purge
+const purgeTokenTTL = 10 * time.Minute
+
+// Stops fetching the currency and takes it off the tracked list. Its history stays
+// queryable until PurgeCurrency. Removing an archived currency again is a no-op
+func (s *cryptoService) RemoveCurrency(ctx context.Context, symbol string) error {
+	_, err := s.SetCurrencyStatus(ctx, symbol, domain.CurrencyArchived)
+	return err
+}
+
+// Moves a currency between active, paused and archived, only active currencies are fetched
+func (s *cryptoService) SetCurrencyStatus(ctx context.Context, symbol string, status domain.CurrencyStatus) (*domain.Currency, error) {
+	if _, err := domain.ParseCurrencyStatus(string(status)); err != nil {
+		return nil, err
+	}
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if cur.Status == status {
+		return cur, nil
+	}
+	prev := cur.Status
+	cur.Status = status
+	if err := s.repo.UpdateCurrency(ctx, cur); err != nil {
+		return nil, err
+	}
+	s.log.Info("currency status changed", "symbol", cur.Symbol, "from", prev, "to", status)
+	s.wakeScheduler()
+	return cur, nil
+}
+
+// First step of a purge: returns a short-lived token and what the purge would delete.
+// Only archived currencies can be purged
+func (s *cryptoService) RequestPurge(ctx context.Context, symbol string) (*domain.PurgeConfirmation, error) {
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if cur.Status != domain.CurrencyArchived {
+		return nil, domain.ErrNotArchived
+	}
+	counts, err := s.repo.CountCurrencyData(ctx, cur.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(buf)
+	expiresAt := time.Now().UTC().Add(purgeTokenTTL).Truncate(time.Second)
+	if err := s.repo.SavePurgeToken(ctx, cur.ID, token, expiresAt); err != nil {
+		return nil, err
+	}
+	return &domain.PurgeConfirmation{
+		Symbol:    cur.Symbol,
+		Token:     token,
+		ExpiresAt: expiresAt,
+		Counts:    counts,
+	}, nil
+}
+
+// Deletes an archived currency and everything stored for it. `token` comes from RequestPurge
+func (s *cryptoService) PurgeCurrency(ctx context.Context, symbol, token string) (*domain.PurgeCounts, error) {
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.PurgeCurrency(ctx, cur.ID, strings.TrimSpace(token), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	s.stored.forget(cur.ID)
+	s.log.Warn("currency purged", "symbol", cur.Symbol, "prices", counts.Prices, "rollups", counts.Rollups, "backfill_jobs", counts.BackfillJobs)
+	return &counts, nil
+}
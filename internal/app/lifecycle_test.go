@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Adds the purge steps to fakeCurrencyStore
+type fakePurgeRepo struct {
+	*fakeCurrencyStore
+	token string
+}
+
+func (f *fakePurgeRepo) CountCurrencyData(ctx context.Context, currencyID uuid.UUID) (domain.PurgeCounts, error) {
+	return domain.PurgeCounts{Prices: 3}, nil
+}
+
+func (f *fakePurgeRepo) SavePurgeToken(ctx context.Context, currencyID uuid.UUID, token string, expiresAt time.Time) error {
+	f.token = token
+	return nil
+}
+
+func (f *fakePurgeRepo) PurgeCurrency(ctx context.Context, currencyID uuid.UUID, token string, now time.Time) (domain.PurgeCounts, error) {
+	if token != f.token {
+		return domain.PurgeCounts{}, domain.ErrInvalidPurgeToken
+	}
+	return domain.PurgeCounts{Prices: 3}, nil
+}
+
+// Status changes and both purge steps look the currency up by the reference as given
+func TestLifecycleCurrencyRef(t *testing.T) {
+	ctx := context.Background()
+	for _, ref := range []string{"uni-uniswap", "UNI"} {
+		s, store := newCurrencyStoreService()
+		repo := &fakePurgeRepo{fakeCurrencyStore: store}
+		s.repo = repo
+
+		if _, err := s.SetCurrencyStatus(ctx, ref, domain.CurrencyArchived); err != nil {
+			t.Fatalf("%s: status: %v", ref, err)
+		}
+		conf, err := s.RequestPurge(ctx, ref)
+		if err != nil {
+			t.Fatalf("%s: request purge: %v", ref, err)
+		}
+		counts, err := s.PurgeCurrency(ctx, ref, conf.Token)
+		if err != nil {
+			t.Fatalf("%s: purge: %v", ref, err)
+		}
+		if counts.Prices != 3 {
+			t.Errorf("%s: purged %d prices, want 3", ref, counts.Prices)
+		}
+		for _, got := range store.refs {
+			if got != ref {
+				t.Errorf("looked up %v, want %s every time", store.refs, ref)
+				break
+			}
+		}
+	}
+}
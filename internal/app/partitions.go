@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// Monthly partition upkeep of the price table
+type PartitionConfig struct {
+	Ahead      int           // upcoming months created in advance
+	Retain     time.Duration // months ending before now - Retain are removed, 0 keeps them
+	DetachOnly bool          // detach old months instead of dropping them, e.g. to archive them
+}
+
+// Creates the current and upcoming monthly partitions and removes the expired ones.
+// A no-op for repositories that don't partition prices
+func (s *cryptoService) MaintainPartitions(ctx context.Context) error {
+	repo, ok := s.repo.(domain.PartitionRepository)
+	if !ok {
+		return nil
+	}
+	now := time.Now().UTC()
+
+	created, err := repo.EnsurePricePartitions(ctx, now, now.AddDate(0, s.cfg.Partitions.Ahead, 0))
+	if len(created) > 0 {
+		s.log.Info("created price partitions", "partitions", created)
+	}
+	if err != nil {
+		return fmt.Errorf("MaintainPartitions: create: %w", err)
+	}
+
+	if s.cfg.Partitions.Retain <= 0 {
+		return nil
+	}
+	removed, err := repo.DropPricePartitions(ctx, now.Add(-s.cfg.Partitions.Retain), s.cfg.Partitions.DetachOnly)
+	if len(removed) > 0 {
+		s.log.Info("removed price partitions", "partitions", removed, "detach_only", s.cfg.Partitions.DetachOnly)
+	}
+	if err != nil {
+		return fmt.Errorf("MaintainPartitions: remove: %w", err)
+	}
+	return nil
+}
+
+// Attaches an archived month again so its rows are served by lookups and candles. A month the
+// configured retention would remove again on its next run is refused
+func (s *cryptoService) AttachPriceMonth(ctx context.Context, month time.Time) (string, error) {
+	repo, ok := s.repo.(domain.PartitionRepository)
+	if !ok {
+		return "", domain.ErrPartitionNotFound
+	}
+	month = month.UTC()
+	end := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+	if cutoff := s.partitionCutoff(time.Now().UTC()); !cutoff.IsZero() && !end.After(cutoff) {
+		return "", fmt.Errorf("%s is older than the retention window: %w", month.Format("2006-01"), domain.ErrInvalidTimeRange)
+	}
+	name, err := repo.AttachPricePartition(ctx, month)
+	if err != nil {
+		return "", err
+	}
+	s.log.Info("attached price partition", "partition", name)
+	return name, nil
+}
+
+// Months ending at or before the returned time are removed by MaintainPartitions or ApplyRetention,
+// zero when neither removes any
+func (s *cryptoService) partitionCutoff(now time.Time) time.Time {
+	var cutoff time.Time
+	if s.cfg.Partitions.Retain > 0 {
+		cutoff = now.Add(-s.cfg.Partitions.Retain)
+	}
+	if s.cfg.Retention.Enabled() {
+		if c := now.Add(-s.cfg.Retention.Raw); c.After(cutoff) {
+			cutoff = c
+		}
+	}
+	return cutoff
+}
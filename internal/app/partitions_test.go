@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+)
+
+// Records attached months and queued jobs, every other method panics
+type fakePartitionRepo struct {
+	domain.CryptoRepository
+	attached []time.Time
+	jobs     []*domain.BackfillJob
+}
+
+func (f *fakePartitionRepo) EnsurePricePartitions(ctx context.Context, from, to time.Time) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakePartitionRepo) DropPricePartitions(ctx context.Context, before time.Time, detachOnly bool) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakePartitionRepo) AttachPricePartition(ctx context.Context, month time.Time) (string, error) {
+	f.attached = append(f.attached, month)
+	return "currency_prices_p" + month.Format("200601"), nil
+}
+
+func (f *fakePartitionRepo) CreateBackfillJob(ctx context.Context, job *domain.BackfillJob) error {
+	f.jobs = append(f.jobs, job)
+	return nil
+}
+
+// Provider with history but no prices
+type fakeHistory struct{ fakeCatalog }
+
+func (f *fakeHistory) FetchHistory(ctx context.Context, coinID, quote string, start, end time.Time, interval time.Duration) ([]HistoricalTick, error) {
+	return nil, nil
+}
+
+func newPartitionService(repo *fakePartitionRepo, cfg Config) *cryptoService {
+	return &cryptoService{
+		repo:         repo,
+		api:          &fakeHistory{},
+		log:          logger.New(logger.Config{Level: "error"}),
+		cfg:          cfg,
+		backfillWake: make(chan struct{}, 1),
+	}
+}
+
+func TestAttachPriceMonth(t *testing.T) {
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		cfg   Config
+		month time.Time
+		ok    bool
+	}{
+		{"no retention", Config{}, thisMonth.AddDate(-3, 0, 0), true},
+		{"within retain", Config{Partitions: PartitionConfig{Retain: 400 * 24 * time.Hour}}, thisMonth.AddDate(0, -6, 0), true},
+		{"older than retain", Config{Partitions: PartitionConfig{Retain: 90 * 24 * time.Hour}}, thisMonth.AddDate(0, -6, 0), false},
+		{"older than raw retention", Config{Retention: domain.RetentionPolicy{Raw: 90 * 24 * time.Hour}}, thisMonth.AddDate(0, -6, 0), false},
+		{"raw retention wins over a longer retain", Config{
+			Partitions: PartitionConfig{Retain: 400 * 24 * time.Hour},
+			Retention:  domain.RetentionPolicy{Raw: 30 * 24 * time.Hour},
+		}, thisMonth.AddDate(0, -3, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakePartitionRepo{}
+			_, err := newPartitionService(repo, tt.cfg).AttachPriceMonth(context.Background(), tt.month)
+			if tt.ok {
+				if err != nil || len(repo.attached) != 1 {
+					t.Fatalf("err = %v, attached %v", err, repo.attached)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrInvalidTimeRange) || len(repo.attached) != 0 {
+				t.Fatalf("err = %v, attached %v, want a refusal", err, repo.attached)
+			}
+		})
+	}
+}
+
+func TestBackfillNewCurrencyAvoidsDetachedMonths(t *testing.T) {
+	const day = 24 * time.Hour
+	cur := &domain.Currency{Symbol: "BTC", ProviderID: "btc-bitcoin"}
+	tests := []struct {
+		name     string
+		cfg      Config
+		lookback time.Duration // expected distance of the job start from now
+	}{
+		{"dropped months", Config{
+			Backfill:   BackfillConfig{Lookback: 365 * day},
+			Partitions: PartitionConfig{Retain: 90 * day},
+		}, 365 * day},
+		{"detached months", Config{
+			Backfill:   BackfillConfig{Lookback: 365 * day},
+			Partitions: PartitionConfig{Retain: 90 * day, DetachOnly: true},
+		}, 90 * day},
+		{"detached by retention", Config{
+			Backfill:   BackfillConfig{Lookback: 365 * day},
+			Partitions: PartitionConfig{DetachOnly: true},
+			Retention:  domain.RetentionPolicy{Raw: 30 * day},
+		}, 30 * day},
+		{"look-back within retention", Config{
+			Backfill:   BackfillConfig{Lookback: 7 * day},
+			Partitions: PartitionConfig{Retain: 90 * day, DetachOnly: true},
+		}, 7 * day},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakePartitionRepo{}
+			newPartitionService(repo, tt.cfg).backfillNewCurrency(context.Background(), cur)
+			if len(repo.jobs) != 1 {
+				t.Fatalf("queued %d jobs, want 1", len(repo.jobs))
+			}
+			job := repo.jobs[0]
+			if got := job.End.Sub(job.Start); got < tt.lookback-time.Minute || got > tt.lookback+time.Minute {
+				t.Errorf("job covers %s, want %s", got, tt.lookback)
+			}
+		})
+	}
+}
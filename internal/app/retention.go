@@ -0,0 +1,127 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+// Rolls raw snapshots up into the configured tiers and deletes what has expired.
+// Each tier is built from the previous one before anything is pruned
+func (s *cryptoService) ApplyRetention(ctx context.Context) error {
+	policy := s.cfg.Retention
+	if !policy.Enabled() {
+		return nil
+	}
+	now := time.Now().UTC()
+
+	var source time.Duration // raw snapshots
+	for _, tier := range policy.Rollups {
+		// Recompute the newest bucket so late snapshots are picked up
+		since, err := s.repo.LatestRollup(ctx, tier.Resolution)
+		if err != nil {
+			return fmt.Errorf("ApplyRetention: latest %s rollup: %w", tier.Resolution, err)
+		}
+		size := int64(tier.Resolution / time.Second)
+		until := time.Unix(now.Unix()/size*size, 0).UTC() // epoch aligned, like the buckets
+
+		n, err := s.repo.RollupPrices(ctx, source, tier.Resolution, since, until)
+		if err != nil {
+			return fmt.Errorf("ApplyRetention: roll up %s: %w", tier.Resolution, err)
+		}
+		s.log.Debug("rolled up prices", "resolution", tier.Resolution, "since", since, "until", until, "buckets", n)
+		source = tier.Resolution
+	}
+
+	// Whole expired months go as partitions first, detached or dropped like MaintainPartitions does
+	rawBefore := now.Add(-policy.Raw)
+	if repo, ok := s.repo.(domain.PartitionRepository); ok {
+		removed, err := repo.DropPricePartitions(ctx, rawBefore, s.cfg.Partitions.DetachOnly)
+		if len(removed) > 0 {
+			s.log.Info("removed expired price partitions", "partitions", removed, "detach_only", s.cfg.Partitions.DetachOnly)
+		}
+		if err != nil {
+			return fmt.Errorf("ApplyRetention: remove raw partitions: %w", err)
+		}
+	}
+	n, err := s.repo.PrunePriceSnapshots(ctx, rawBefore)
+	if err != nil {
+		return fmt.Errorf("ApplyRetention: prune raw prices: %w", err)
+	}
+	s.log.Info("pruned raw prices", "before", rawBefore, "rows", n)
+
+	for _, tier := range policy.Rollups {
+		if tier.Keep == 0 {
+			continue
+		}
+		n, err := s.repo.PruneRollups(ctx, tier.Resolution, now.Add(-tier.Keep))
+		if err != nil {
+			return fmt.Errorf("ApplyRetention: prune %s rollups: %w", tier.Resolution, err)
+		}
+		s.log.Info("pruned rollups", "resolution", tier.Resolution, "before", now.Add(-tier.Keep), "rows", n)
+	}
+	return nil
+}
+
+// Rolls up the complete buckets overlapping [from, to] in every tier, used when old
+// prices are written after the retention job has already moved past them
+func (s *cryptoService) rollupRange(ctx context.Context, from, to time.Time) error {
+	policy := s.cfg.Retention
+	if !policy.Enabled() || !from.Before(time.Now().Add(-policy.Raw)) {
+		return nil
+	}
+	now := time.Now().UTC()
+
+	var source time.Duration
+	for _, tier := range policy.Rollups {
+		size := int64(tier.Resolution / time.Second)
+		since := time.Unix(from.Unix()/size*size, 0).UTC()
+		until := time.Unix(min(now.Unix()/size, to.Unix()/size+1)*size, 0).UTC()
+		if _, err := s.repo.RollupPrices(ctx, source, tier.Resolution, since, until); err != nil {
+			return fmt.Errorf("roll up %s: %w", tier.Resolution, err)
+		}
+		source = tier.Resolution
+	}
+	return nil
+}
+
+// Lists prices in [start, end] across retention tiers, ordered by timestamp.
+// Older parts of the window come from rollups, returned as their close snapshots
+func (s *cryptoService) listSnapshots(
+	ctx context.Context,
+	cur *domain.Currency,
+	quote string,
+	start, end time.Time,
+	limit int,
+) ([]*domain.PriceSnapshot, error) {
+	var all []*domain.PriceSnapshot
+	for _, seg := range s.cfg.Retention.Segments(start, end, time.Now().UTC()) {
+		left := 0
+		if limit > 0 {
+			left = limit - len(all)
+		}
+
+		if seg.Resolution == 0 {
+			snaps, err := s.repo.ListPriceSnapshots(ctx, cur.ID, quote, seg.From, seg.To, left)
+			if err != nil {
+				return nil, err
+			}
+			all = append(all, snaps...)
+		} else {
+			candles, err := s.repo.ListRollups(ctx, cur.ID, quote, seg.Resolution, seg.From, seg.To, left)
+			if err != nil {
+				return nil, err
+			}
+			for _, cd := range candles {
+				all = append(all, cd.CloseSnapshot(cur.ID, quote, seg.Resolution))
+			}
+		}
+
+		if limit > 0 && len(all) >= limit {
+			break
+		}
+	}
+	return all, nil
+}
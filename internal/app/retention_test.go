@@ -0,0 +1,220 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+)
+
+// Raw rows and rollup buckets as timestamps, records every call. Rows are only
+// deleted when the bucket of the tier above them exists, anything else is a violation
+type fakeRetentionRepo struct {
+	domain.CryptoRepository
+	raw        []time.Time
+	rollups    map[time.Duration][]time.Time // bucket starts by resolution
+	tiers      []time.Duration               // resolutions, finest first
+	calls      []string
+	violations []string
+	failRollup error
+}
+
+func newFakeRetentionRepo(policy domain.RetentionPolicy, raw []time.Time) *fakeRetentionRepo {
+	f := &fakeRetentionRepo{raw: raw, rollups: make(map[time.Duration][]time.Time)}
+	for _, tier := range policy.Rollups {
+		f.tiers = append(f.tiers, tier.Resolution)
+	}
+	return f
+}
+
+func (f *fakeRetentionRepo) LatestRollup(ctx context.Context, resolution time.Duration) (time.Time, error) {
+	var latest time.Time
+	for _, b := range f.rollups[resolution] {
+		if b.After(latest) {
+			latest = b
+		}
+	}
+	return latest, nil
+}
+
+func (f *fakeRetentionRepo) RollupPrices(ctx context.Context, source, resolution time.Duration, since, until time.Time) (int64, error) {
+	f.calls = append(f.calls, fmt.Sprintf("rollup %s", resolution))
+	if f.failRollup != nil {
+		return 0, f.failRollup
+	}
+	from := f.raw
+	if source > 0 {
+		from = f.rollups[source]
+	}
+	var n int64
+	for _, ts := range from {
+		if ts.Before(since) || !ts.Before(until) {
+			continue
+		}
+		b := ts.Truncate(resolution)
+		if b.Add(resolution).After(until) || slices.ContainsFunc(f.rollups[resolution], b.Equal) {
+			continue // incomplete or already stored
+		}
+		f.rollups[resolution] = append(f.rollups[resolution], b)
+		n++
+	}
+	return n, nil
+}
+
+func (f *fakeRetentionRepo) PrunePriceSnapshots(ctx context.Context, before time.Time) (int64, error) {
+	f.calls = append(f.calls, "prune raw")
+	return f.deleteRaw(before), nil
+}
+
+func (f *fakeRetentionRepo) PruneRollups(ctx context.Context, resolution time.Duration, before time.Time) (int64, error) {
+	f.calls = append(f.calls, fmt.Sprintf("prune %s", resolution))
+	i := slices.Index(f.tiers, resolution)
+	var n int64
+	f.rollups[resolution] = slices.DeleteFunc(f.rollups[resolution], func(b time.Time) bool {
+		if !b.Before(before) {
+			return false
+		}
+		if i+1 < len(f.tiers) {
+			f.checkRolledUp(fmt.Sprintf("%s bucket", resolution), b, f.tiers[i+1])
+		}
+		n++
+		return true
+	})
+	return n, nil
+}
+
+// Deletes the raw rows before `before`, each must be rolled up into the first tier
+func (f *fakeRetentionRepo) deleteRaw(before time.Time) int64 {
+	var n int64
+	f.raw = slices.DeleteFunc(f.raw, func(ts time.Time) bool {
+		if !ts.Before(before) {
+			return false
+		}
+		if len(f.tiers) > 0 {
+			f.checkRolledUp("raw row", ts, f.tiers[0])
+		}
+		n++
+		return true
+	})
+	return n
+}
+
+func (f *fakeRetentionRepo) checkRolledUp(what string, ts time.Time, resolution time.Duration) {
+	if !slices.ContainsFunc(f.rollups[resolution], ts.Truncate(resolution).Equal) {
+		f.violations = append(f.violations, fmt.Sprintf("%s at %s deleted before its %s rollup", what, ts, resolution))
+	}
+}
+
+// Hourly raw rows over the last `days`
+func hourlyRows(days int) []time.Time {
+	end := time.Now().UTC().Truncate(time.Hour)
+	var rows []time.Time
+	for ts := end.AddDate(0, 0, -days); !ts.After(end); ts = ts.Add(time.Hour) {
+		rows = append(rows, ts)
+	}
+	return rows
+}
+
+func newRetentionService(repo domain.CryptoRepository, cfg Config) *cryptoService {
+	return &cryptoService{repo: repo, log: logger.New(logger.Config{Level: "error"}), cfg: cfg}
+}
+
+func TestApplyRetentionRollsUpBeforePruning(t *testing.T) {
+	const day = 24 * time.Hour
+	policy := domain.RetentionPolicy{
+		Raw: 3 * day,
+		Rollups: []domain.RetentionTier{
+			{Resolution: time.Hour, Keep: 7 * day},
+			{Resolution: day},
+		},
+	}
+	repo := newFakeRetentionRepo(policy, hourlyRows(10))
+	s := newRetentionService(repo, Config{Retention: policy})
+
+	if err := s.ApplyRetention(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range repo.violations {
+		t.Error(v)
+	}
+	want := "rollup 1h0m0s, rollup 24h0m0s, prune raw, prune 1h0m0s"
+	if got := strings.Join(repo.calls, ", "); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+	rawBefore := time.Now().Add(-policy.Raw)
+	if len(repo.raw) == 0 || repo.raw[0].Before(rawBefore) {
+		t.Errorf("raw rows from %v kept, want none before %s", repo.raw[:min(1, len(repo.raw))], rawBefore)
+	}
+	if len(repo.rollups[time.Hour]) == 0 || len(repo.rollups[day]) == 0 {
+		t.Errorf("rollups = %d hourly, %d daily, want both tiers filled", len(repo.rollups[time.Hour]), len(repo.rollups[day]))
+	}
+
+	// A second run finds nothing new to roll up or delete
+	raw, hourly := len(repo.raw), len(repo.rollups[time.Hour])
+	if err := s.ApplyRetention(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.raw) != raw || len(repo.rollups[time.Hour]) != hourly || len(repo.violations) > 0 {
+		t.Errorf("second run changed %d raw rows and %d hourly rollups", raw-len(repo.raw), hourly-len(repo.rollups[time.Hour]))
+	}
+}
+
+// Nothing is deleted when a tier could not be rolled up
+func TestApplyRetentionKeepsRowsWhenRollupFails(t *testing.T) {
+	policy := domain.RetentionPolicy{Raw: 24 * time.Hour, Rollups: []domain.RetentionTier{{Resolution: time.Hour}}}
+	repo := newFakeRetentionRepo(policy, hourlyRows(3))
+	repo.failRollup = errors.New("rollup failed")
+	rows := len(repo.raw)
+	s := newRetentionService(repo, Config{Retention: policy})
+
+	if err := s.ApplyRetention(context.Background()); !errors.Is(err, repo.failRollup) {
+		t.Fatalf("err = %v, want the rollup error", err)
+	}
+	if len(repo.raw) != rows || slices.Contains(repo.calls, "prune raw") {
+		t.Errorf("calls %v deleted %d raw rows", repo.calls, rows-len(repo.raw))
+	}
+}
+
+// Adds monthly partitions to fakeRetentionRepo, whole months are removed by dropping them
+type fakePartitionedRetentionRepo struct {
+	*fakeRetentionRepo
+	domain.PartitionRepository
+}
+
+func (f *fakePartitionedRetentionRepo) DropPricePartitions(ctx context.Context, before time.Time, detachOnly bool) ([]string, error) {
+	f.calls = append(f.calls, fmt.Sprintf("drop partitions detach=%v", detachOnly))
+	month := time.Date(before.Year(), before.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if f.deleteRaw(month) == 0 {
+		return nil, nil
+	}
+	return []string{"currency_prices_p" + month.AddDate(0, -1, 0).Format("200601")}, nil
+}
+
+func TestApplyRetentionDropsPartitionsAfterRollup(t *testing.T) {
+	const day = 24 * time.Hour
+	policy := domain.RetentionPolicy{Raw: 30 * day, Rollups: []domain.RetentionTier{{Resolution: day}}}
+	for _, detachOnly := range []bool{false, true} {
+		repo := &fakePartitionedRetentionRepo{fakeRetentionRepo: newFakeRetentionRepo(policy, hourlyRows(90))}
+		s := newRetentionService(repo, Config{Retention: policy, Partitions: PartitionConfig{DetachOnly: detachOnly}})
+
+		if err := s.ApplyRetention(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range repo.violations {
+			t.Error(v)
+		}
+		want := fmt.Sprintf("rollup 24h0m0s, drop partitions detach=%v, prune raw", detachOnly)
+		if got := strings.Join(repo.calls, ", "); got != want {
+			t.Errorf("calls = %s, want %s", got, want)
+		}
+		if rawBefore := time.Now().Add(-policy.Raw); repo.raw[0].Before(rawBefore) {
+			t.Errorf("raw row at %s kept, want none before %s", repo.raw[0], rawBefore)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package app
+
+import (
+	"container/heap"
+	"context"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+const (
+	// How often the schedule is reloaded to pick up changes made by other processes
+	scheduleResync = time.Minute
+	// Longest wait before a failed fetch is retried, never longer than the currency interval
+	fetchRetryMax = time.Minute
+)
+
+// One active currency in the fetch schedule
+type scheduleEntry struct {
+	cur      *domain.Currency
+	interval time.Duration
+	due      time.Time
+	last     time.Time // start of the last successful fetch, zero before the first
+	failures int       // consecutive failed fetches
+
+	ready bool // queued by priority instead of due time
+	index int  // position in its queue, maintained by container/heap
+}
+
+// container/heap of entries ordered by `less`
+type scheduleQueue struct {
+	entries []*scheduleEntry
+	less    func(a, b *scheduleEntry) bool
+}
+
+func (q *scheduleQueue) Len() int           { return len(q.entries) }
+func (q *scheduleQueue) Less(i, j int) bool { return q.less(q.entries[i], q.entries[j]) }
+
+func (q *scheduleQueue) Swap(i, j int) {
+	q.entries[i], q.entries[j] = q.entries[j], q.entries[i]
+	q.entries[i].index = i
+	q.entries[j].index = j
+}
+
+func (q *scheduleQueue) Push(x any) {
+	e := x.(*scheduleEntry)
+	e.index = len(q.entries)
+	q.entries = append(q.entries, e)
+}
+
+func (q *scheduleQueue) Pop() any {
+	n := len(q.entries)
+	e := q.entries[n-1]
+	q.entries[n-1] = nil
+	q.entries = q.entries[:n-1]
+	e.index = -1
+	return e
+}
+
+// Earliest due first, ties broken by priority
+func byDue(a, b *scheduleEntry) bool {
+	if !a.due.Equal(b.due) {
+		return a.due.Before(b.due)
+	}
+	return a.cur.Schedule.Priority > b.cur.Schedule.Priority
+}
+
+// Highest priority first, then the longest overdue
+func byPriority(a, b *scheduleEntry) bool {
+	if a.cur.Schedule.Priority != b.cur.Schedule.Priority {
+		return a.cur.Schedule.Priority > b.cur.Schedule.Priority
+	}
+	return a.due.Before(b.due)
+}
+
+// Due-time queue of the active currencies. Entries wait in `waiting` until they are due and
+// then move to `ready`, where priority decides which one the rate limit lets through next
+type fetchSchedule struct {
+	waiting *scheduleQueue
+	ready   *scheduleQueue
+	entries map[uuid.UUID]*scheduleEntry
+}
+
+func newFetchSchedule() *fetchSchedule {
+	return &fetchSchedule{
+		waiting: &scheduleQueue{less: byDue},
+		ready:   &scheduleQueue{less: byPriority},
+		entries: make(map[uuid.UUID]*scheduleEntry),
+	}
+}
+
+// Replaces the schedule with `currs`. New currencies are due right away, known ones keep
+// their due time, moved earlier or later by a changed interval. Inactive currencies are dropped,
+// as are those whose legacy coin ID is unresolved, since no fetch of them can succeed.
+// Returns how many active currencies were left out for that reason
+func (f *fetchSchedule) sync(currs []*domain.Currency, def time.Duration, now time.Time) (unresolved int) {
+	seen := make(map[uuid.UUID]bool, len(currs))
+	for _, cur := range currs {
+		if cur.Status != domain.CurrencyActive {
+			continue
+		}
+		if cur.HasLegacyProviderID() {
+			unresolved++
+			continue
+		}
+		seen[cur.ID] = true
+		interval := max(cur.Schedule.IntervalOr(def), time.Second)
+
+		e, ok := f.entries[cur.ID]
+		if !ok {
+			e = &scheduleEntry{cur: cur, interval: interval, due: now}
+			f.entries[cur.ID] = e
+			heap.Push(f.waiting, e)
+			continue
+		}
+		e.cur = cur
+		if interval != e.interval && !e.last.IsZero() && !e.ready {
+			e.due = e.last.Add(interval)
+		}
+		e.interval = interval
+		heap.Fix(f.queue(e), e.index)
+	}
+
+	for id, e := range f.entries {
+		if !seen[id] {
+			heap.Remove(f.queue(e), e.index)
+			delete(f.entries, id)
+		}
+	}
+	return unresolved
+}
+
+func (f *fetchSchedule) queue(e *scheduleEntry) *scheduleQueue {
+	if e.ready {
+		return f.ready
+	}
+	return f.waiting
+}
+
+// Takes the next entry to fetch at `now`, nil when none is due. The entry leaves
+// the schedule until it is rescheduled with done
+func (f *fetchSchedule) next(now time.Time) *scheduleEntry {
+	for f.waiting.Len() > 0 && !f.waiting.entries[0].due.After(now) {
+		e := heap.Pop(f.waiting).(*scheduleEntry)
+		e.ready = true
+		heap.Push(f.ready, e)
+	}
+	if f.ready.Len() == 0 {
+		return nil
+	}
+	return heap.Pop(f.ready).(*scheduleEntry)
+}
+
+// Puts a fetched entry back one interval after the fetch started. Failed fetches
+// are retried sooner, backing off exponentially
+func (f *fetchSchedule) done(e *scheduleEntry, started time.Time, err error) {
+	if err != nil {
+		e.failures++
+		retry := min(2*time.Second<<min(e.failures-1, 10), fetchRetryMax, e.interval)
+		e.due = started.Add(retry)
+	} else {
+		e.failures = 0
+		e.last = started
+		e.due = started.Add(e.interval)
+	}
+	e.ready = false
+	heap.Push(f.waiting, e)
+}
+
+// Time until the next entry is due, `fallback` when the schedule is empty
+func (f *fetchSchedule) wait(now time.Time, fallback time.Duration) time.Duration {
+	if f.ready.Len() > 0 {
+		return 0
+	}
+	if f.waiting.Len() == 0 {
+		return fallback
+	}
+	return max(f.waiting.entries[0].due.Sub(now), 0)
+}
+
+// Time source of the scheduler loop, tests drive it by hand
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// The wall clock, in UTC
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now().UTC() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Fetches every active currency at its own interval until ctx is cancelled. Fetches
+// run one at a time behind the provider rate limit, so when more are due than it
+// allows, higher priorities go first and the rest wait
+func (s *cryptoService) RunScheduler(ctx context.Context, clock Clock) {
+	sched := newFetchSchedule()
+	s.syncSchedule(ctx, sched, clock.Now())
+	resyncAt := clock.Now().Add(scheduleResync)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.scheduleWake:
+			s.syncSchedule(ctx, sched, clock.Now())
+		default:
+		}
+
+		now := clock.Now()
+		if !now.Before(resyncAt) {
+			s.syncSchedule(ctx, sched, now)
+			resyncAt = now.Add(scheduleResync)
+		}
+		if e := sched.next(now); e != nil {
+			err := s.fetchAndStore(ctx, e.cur)
+			if err != nil && ctx.Err() == nil {
+				s.log.Error("fetch price failed", "symbol", e.cur.Symbol, "attempt", e.failures+1, "error", err)
+			}
+			sched.done(e, now, err)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.scheduleWake:
+			s.syncSchedule(ctx, sched, clock.Now())
+		case <-clock.After(min(sched.wait(now, scheduleResync), resyncAt.Sub(now))):
+		}
+	}
+}
+
+// Reloads the tracked currencies into the schedule, on error the current one is kept
+func (s *cryptoService) syncSchedule(ctx context.Context, sched *fetchSchedule, now time.Time) {
+	const pageSize = 100
+	var currs []*domain.Currency
+	for offset := 0; ; offset += pageSize {
+		page, err := s.repo.ListCurrencies(ctx, pageSize, offset)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.log.Error("load fetch schedule failed", "error", err)
+			}
+			return
+		}
+		currs = append(currs, page...)
+		if len(page) < pageSize {
+			break
+		}
+	}
+	unresolved := sched.sync(currs, s.cfg.FetchInterval, now)
+	s.log.Debug("fetch schedule loaded", "currencies", len(sched.entries), "unresolved", unresolved)
+}
+
+func (s *cryptoService) wakeScheduler() {
+	select {
+	case s.scheduleWake <- struct{}{}:
+	default: // a wake-up is already pending
+	}
+}
+
+// Sets how often and how urgently a currency is fetched, takes effect on the next fetch
+func (s *cryptoService) SetFetchSchedule(ctx context.Context, symbol string, sched domain.FetchSchedule) (*domain.Currency, error) {
+	if err := sched.Validate(); err != nil {
+		return nil, err
+	}
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	cur.Schedule = sched
+	if err := s.repo.UpdateCurrency(ctx, cur); err != nil {
+		return nil, err
+	}
+	s.wakeScheduler()
+	return cur, nil
+}
+
+// Expected time between stored snapshots of a currency, the global interval for untracked ones
+func (s *cryptoService) fetchInterval(cur *domain.Currency) time.Duration {
+	if cur == nil {
+		return s.cfg.FetchInterval
+	}
+	return cur.Schedule.IntervalOr(s.cfg.FetchInterval)
+}
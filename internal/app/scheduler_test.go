@@ -0,0 +1,428 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+	"github.com/google/uuid"
+)
+
+var errFetch = errors.New("fetch failed")
+
+// Manually advanced clock for the schedule. As a Clock, every After call is
+// reported on `waits` when it is set
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []fakeTimer
+	waits  chan time.Duration
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	t := fakeTimer{at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+	} else {
+		c.timers = append(c.timers, t)
+	}
+	c.mu.Unlock()
+	if c.waits != nil {
+		c.waits <- d
+	}
+	return t.c
+}
+
+// Moves the clock forward and fires the timers that came due
+func (c *fakeClock) advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			pending = append(pending, t)
+		} else {
+			t.c <- c.now
+		}
+	}
+	c.timers = pending
+	return c.now
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func activeCurrency(symbol string, interval time.Duration, priority int) *domain.Currency {
+	return &domain.Currency{
+		ID:       uuid.New(),
+		Symbol:   symbol,
+		Status:   domain.CurrencyActive,
+		Schedule: domain.FetchSchedule{Interval: interval, Priority: priority},
+	}
+}
+
+// Every entry due at `now`, in the order next hands them out
+func drain(f *fetchSchedule, now time.Time) []*scheduleEntry {
+	var out []*scheduleEntry
+	for e := f.next(now); e != nil; e = f.next(now) {
+		out = append(out, e)
+	}
+	return out
+}
+
+func assertOrder(t *testing.T, got []*scheduleEntry, want ...string) {
+	t.Helper()
+	syms := make([]string, len(got))
+	for i, e := range got {
+		syms[i] = e.cur.Symbol
+	}
+	if len(syms) != len(want) {
+		t.Fatalf("fetched %v, want %v", syms, want)
+	}
+	for i := range want {
+		if syms[i] != want[i] {
+			t.Fatalf("fetched %v, want %v", syms, want)
+		}
+	}
+}
+
+func TestFetchScheduleOrdering(t *testing.T) {
+	clock := newClock()
+	f := newFetchSchedule()
+	f.sync([]*domain.Currency{
+		activeCurrency("LOW", 10*time.Second, 0),
+		activeCurrency("HIGH", 30*time.Second, 50),
+		activeCurrency("MID", 20*time.Second, 10),
+	}, time.Minute, clock.now)
+
+	// Everything new is due right away, highest priority first
+	start := clock.now
+	got := drain(f, start)
+	assertOrder(t, got, "HIGH", "MID", "LOW")
+	for _, e := range got {
+		f.done(e, start, nil)
+	}
+	if w := f.wait(start, time.Minute); w != 10*time.Second {
+		t.Errorf("wait = %s, want 10s", w)
+	}
+
+	// Nothing is handed out before it is due
+	if e := f.next(clock.advance(9 * time.Second)); e != nil {
+		t.Fatalf("%s fetched before it was due", e.cur.Symbol)
+	}
+	got = drain(f, clock.advance(time.Second))
+	assertOrder(t, got, "LOW")
+	f.done(got[0], clock.now, nil)
+	if w := f.wait(clock.now, time.Minute); w != 10*time.Second {
+		t.Errorf("wait = %s, want 10s until MID", w)
+	}
+
+	// Due entries that wait behind the rate limit are ordered by priority, not by due time
+	assertOrder(t, drain(f, start.Add(35*time.Second)), "HIGH", "MID", "LOW")
+}
+
+func TestFetchScheduleReadyQueue(t *testing.T) {
+	clock := newClock()
+	f := newFetchSchedule()
+	a, b, c := activeCurrency("A", 0, 5), activeCurrency("B", 0, 5), activeCurrency("C", 0, 1)
+	f.sync([]*domain.Currency{a}, time.Minute, clock.now)
+	f.sync([]*domain.Currency{a, b}, time.Minute, clock.advance(time.Second))
+	f.sync([]*domain.Currency{a, b, c}, time.Minute, clock.advance(time.Second))
+
+	// Only one fetch fits, the rest stay ready and are due immediately
+	if e := f.next(clock.now); e == nil || e.cur != a {
+		t.Fatalf("first fetch %v, want A", e)
+	}
+	if w := f.wait(clock.now, time.Minute); w != 0 {
+		t.Errorf("wait = %s with ready entries, want 0", w)
+	}
+	// Equal priorities go by the longest overdue
+	assertOrder(t, drain(f, clock.now), "B", "C")
+}
+
+func TestFetchScheduleTieBreaks(t *testing.T) {
+	at := newClock().now
+	entry := func(due time.Duration, priority int) *scheduleEntry {
+		return &scheduleEntry{cur: activeCurrency("X", 0, priority), due: at.Add(due)}
+	}
+	tests := []struct {
+		name  string
+		less  func(a, b *scheduleEntry) bool
+		a, b  *scheduleEntry
+		aLess bool
+	}{
+		{"byDue: earlier first", byDue, entry(0, 0), entry(time.Second, 100), true},
+		{"byDue: same due, higher priority first", byDue, entry(0, 10), entry(0, 20), false},
+		{"byDue: same due and priority", byDue, entry(0, 10), entry(0, 10), false},
+		{"byPriority: higher first", byPriority, entry(time.Second, 20), entry(0, 10), true},
+		{"byPriority: same priority, earlier first", byPriority, entry(time.Second, 10), entry(0, 10), false},
+		{"byPriority: same priority and due", byPriority, entry(0, 10), entry(0, 10), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.less(tt.a, tt.b); got != tt.aLess {
+				t.Errorf("less(a, b) = %v, want %v", got, tt.aLess)
+			}
+		})
+	}
+}
+
+func TestFetchScheduleBackoff(t *testing.T) {
+	clock := newClock()
+	f := newFetchSchedule()
+	f.sync([]*domain.Currency{activeCurrency("BTC", 10*time.Minute, 0)}, time.Minute, clock.now)
+
+	// Retries double from 2s up to fetchRetryMax
+	for _, want := range []time.Duration{2, 4, 8, 16, 32, 60, 60} {
+		e := f.next(clock.now)
+		if e == nil {
+			t.Fatal("retry not due")
+		}
+		f.done(e, clock.now, errFetch)
+		if w := f.wait(clock.now, time.Hour); w != want*time.Second {
+			t.Fatalf("retry after %d failures in %s, want %s", e.failures, w, want*time.Second)
+		}
+		clock.advance(want * time.Second)
+	}
+
+	// A success resets the backoff and waits the full interval
+	e := f.next(clock.now)
+	f.done(e, clock.now, nil)
+	if e.failures != 0 || !e.last.Equal(clock.now) {
+		t.Errorf("failures %d last %s after success", e.failures, e.last)
+	}
+	if w := f.wait(clock.now, time.Hour); w != 10*time.Minute {
+		t.Errorf("wait after success = %s, want 10m", w)
+	}
+	e = f.next(clock.advance(10 * time.Minute))
+	f.done(e, clock.now, errFetch)
+	if w := f.wait(clock.now, time.Hour); w != 2*time.Second {
+		t.Errorf("first retry after a success in %s, want 2s", w)
+	}
+}
+
+func TestFetchScheduleBackoffCappedByInterval(t *testing.T) {
+	clock := newClock()
+	f := newFetchSchedule()
+	f.sync([]*domain.Currency{activeCurrency("BTC", 5*time.Second, 0)}, time.Minute, clock.now)
+	for _, want := range []time.Duration{2, 4, 5, 5} {
+		e := f.next(clock.now)
+		f.done(e, clock.now, errFetch)
+		if w := f.wait(clock.now, time.Hour); w != want*time.Second {
+			t.Fatalf("retry in %s, want %s", w, want*time.Second)
+		}
+		clock.advance(want * time.Second)
+	}
+}
+
+func TestFetchScheduleIntervalChange(t *testing.T) {
+	clock := newClock()
+	f := newFetchSchedule()
+	btc := activeCurrency("BTC", 0, 0)
+	f.sync([]*domain.Currency{btc}, time.Minute, clock.now)
+	start := clock.now
+	f.done(f.next(start), start, nil)
+
+	// The global interval applies without one of its own
+	if w := f.wait(start, time.Hour); w != time.Minute {
+		t.Fatalf("wait = %s, want the 1m default", w)
+	}
+
+	// A shorter interval counts from the last fetch
+	clock.advance(10 * time.Second)
+	btc.Schedule.Interval = 30 * time.Second
+	f.sync([]*domain.Currency{btc}, time.Minute, clock.now)
+	if w := f.wait(clock.now, time.Hour); w != 20*time.Second {
+		t.Errorf("wait = %s after shortening, want 20s", w)
+	}
+	// A change to an interval already passed makes it due right away
+	btc.Schedule.Interval = 5 * time.Second
+	f.sync([]*domain.Currency{btc}, time.Minute, clock.now)
+	if e := f.next(clock.now); e == nil {
+		t.Fatal("not due after shortening past the last fetch")
+	} else {
+		f.done(e, clock.now, nil)
+	}
+
+	// A longer one is pushed out
+	btc.Schedule.Interval = time.Hour
+	f.sync([]*domain.Currency{btc}, time.Minute, clock.now)
+	if w := f.wait(clock.now, time.Minute); w != time.Hour {
+		t.Errorf("wait = %s after lengthening, want 1h", w)
+	}
+}
+
+func TestFetchScheduleDropsInactive(t *testing.T) {
+	clock := newClock()
+	f := newFetchSchedule()
+	btc, eth, sol := activeCurrency("BTC", 0, 0), activeCurrency("ETH", 0, 0), activeCurrency("SOL", 0, 0)
+	paused := activeCurrency("DOGE", 0, 0)
+	paused.Status = domain.CurrencyPaused
+	f.sync([]*domain.Currency{btc, eth, sol, paused}, time.Minute, clock.now)
+	if len(f.entries) != 3 {
+		t.Fatalf("%d entries, want 3 active", len(f.entries))
+	}
+
+	// BTC is waiting after its fetch, ETH and SOL are due and ready
+	e := f.next(clock.now)
+	f.done(e, clock.now, nil)
+	f.next(clock.now) // moves the rest to ready
+	f.done(f.entries[eth.ID], clock.now, nil)
+
+	// Archiving, pausing and untracking all leave the schedule from either queue
+	sol.Status = domain.CurrencyArchived
+	f.sync([]*domain.Currency{btc, sol}, time.Minute, clock.now)
+	btc.Status = domain.CurrencyPaused
+	f.sync([]*domain.Currency{btc, sol}, time.Minute, clock.now)
+	if len(f.entries) != 0 || f.waiting.Len() != 0 || f.ready.Len() != 0 {
+		t.Fatalf("entries %d waiting %d ready %d, want an empty schedule", len(f.entries), f.waiting.Len(), f.ready.Len())
+	}
+	if w := f.wait(clock.now, 42*time.Second); w != 42*time.Second {
+		t.Errorf("wait = %s, want the fallback", w)
+	}
+
+	// Resuming starts over as due right away
+	btc.Status = domain.CurrencyActive
+	f.sync([]*domain.Currency{btc}, time.Minute, clock.advance(time.Second))
+	assertOrder(t, drain(f, clock.now), "BTC")
+}
+
+// A currency still holding its legacy placeholder can't be fetched, it joins once resolved
+func TestFetchScheduleSkipsUnresolvedLegacy(t *testing.T) {
+	clock := newClock()
+	f := newFetchSchedule()
+	btc, old := activeCurrency("BTC", 0, 0), activeCurrency("OLD", 0, 0)
+	btc.ProviderID = "btc-bitcoin"
+	old.ProviderID = domain.LegacyProviderPrefix + "old"
+
+	if n := f.sync([]*domain.Currency{btc, old}, time.Minute, clock.now); n != 1 {
+		t.Errorf("unresolved = %d, want 1", n)
+	}
+	assertOrder(t, drain(f, clock.now), "BTC")
+
+	old.ProviderID = "old-oldcoin"
+	if n := f.sync([]*domain.Currency{btc, old}, time.Minute, clock.now); n != 0 {
+		t.Errorf("unresolved = %d after resolving, want 0", n)
+	}
+	assertOrder(t, drain(f, clock.now), "OLD") // BTC is still out being fetched
+}
+
+// Lists fixed currencies, every other method panics
+type fakeScheduleRepo struct {
+	domain.CryptoRepository
+	currs []*domain.Currency
+}
+
+func (f *fakeScheduleRepo) ListCurrencies(ctx context.Context, limit, offset int) ([]*domain.Currency, error) {
+	if offset >= len(f.currs) {
+		return nil, nil
+	}
+	return f.currs[offset:min(offset+limit, len(f.currs))], nil
+}
+
+// Records each fetch with the clock time, coins in `failing` return errFetch
+type fakeTickerAPI struct {
+	ExternalPriceAPI
+	clock   *fakeClock
+	start   time.Time
+	failing map[string]bool
+	fetches []string
+}
+
+func (f *fakeTickerAPI) FetchPrice(ctx context.Context, coinID string) (*PricePoint, error) {
+	f.fetches = append(f.fetches, fmt.Sprintf("%s@%s", coinID, f.clock.Now().Sub(f.start)))
+	if f.failing[coinID] {
+		return nil, errFetch
+	}
+	return &PricePoint{}, nil // no quotes, nothing to store
+}
+
+// The loop fetches at the times the schedule sets, reading time only from its clock
+func TestRunScheduler(t *testing.T) {
+	clock := newClock()
+	clock.waits = make(chan time.Duration)
+	btc, eth, bad := activeCurrency("BTC", 10*time.Second, 2), activeCurrency("ETH", 30*time.Second, 1), activeCurrency("BAD", 30*time.Second, 0)
+	for _, cur := range []*domain.Currency{btc, eth, bad} {
+		cur.ProviderID = strings.ToLower(cur.Symbol)
+	}
+	api := &fakeTickerAPI{clock: clock, start: clock.Now(), failing: map[string]bool{"bad": true}}
+	s := &cryptoService{
+		repo:         &fakeScheduleRepo{currs: []*domain.Currency{btc, eth, bad}},
+		api:          api,
+		log:          &logger.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, // fetch errors are expected
+		cfg:          Config{FetchInterval: time.Minute},
+		stored:       newStoredPrices(),
+		scheduleWake: make(chan struct{}, 1),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.RunScheduler(ctx, clock)
+		close(done)
+	}()
+
+	// Let each wait run out until the next one would end past 30s
+	end := clock.Now().Add(30 * time.Second)
+	for {
+		d := <-clock.waits
+		if clock.Now().Add(d).After(end) {
+			break
+		}
+		clock.advance(d)
+	}
+	cancel()
+	<-done
+
+	// BAD backs off 2s, 4s, 8s and then its interval
+	want := []string{
+		"btc@0s", "eth@0s", "bad@0s",
+		"bad@2s",
+		"bad@6s",
+		"btc@10s",
+		"bad@14s",
+		"btc@20s",
+		"btc@30s", "eth@30s", "bad@30s",
+	}
+	if strings.Join(api.fetches, " ") != strings.Join(want, " ") {
+		t.Errorf("fetches\n got %v\nwant %v", api.fetches, want)
+	}
+}
+
+func TestSetFetchScheduleCurrencyRef(t *testing.T) {
+	for _, ref := range []string{"uni-uniswap", "UNI"} {
+		s, repo := newCurrencyStoreService()
+		cur, err := s.SetFetchSchedule(context.Background(), ref, domain.FetchSchedule{Interval: time.Minute, Priority: 1})
+		if err != nil {
+			t.Fatalf("%s: %v", ref, err)
+		}
+		if len(repo.refs) != 1 || repo.refs[0] != ref {
+			t.Errorf("looked up %v, want [%s]", repo.refs, ref)
+		}
+		if cur.Schedule.Interval != time.Minute || repo.updated != 1 {
+			t.Errorf("%s: schedule %+v stored %d times", ref, cur.Schedule, repo.updated)
+		}
+	}
+}
@@ -2,58 +2,249 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/internal/indicators"
 	"github.com/Neroframe/crypto-tracker/pkg/logger"
+	"github.com/google/uuid"
 )
 
 type CryptoService interface {
-	AddCurrency(ctx context.Context, symbol string) (*domain.Currency, error)
+	AddCurrency(ctx context.Context, symbol, coinID string) (*domain.Currency, error)
+	ResolveLegacyCoins(ctx context.Context) error
 	RemoveCurrency(ctx context.Context, symbol string) error
-	GetPrice(ctx context.Context, symbol string, at time.Time) (*domain.PriceSnapshot, error)
-	FetchAndStorePrices(ctx context.Context) error
+	ListCurrencies(ctx context.Context, opts CurrencyListOptions) (*CurrencyPage, error)
+	GetPrice(ctx context.Context, symbol string, at time.Time, opts LookupOptions) (*domain.PriceLookup, error)
+	GetPrices(ctx context.Context, queries []domain.PriceQuery, opts LookupOptions) ([]domain.PriceLookupResult, error)
+	GetPriceHistory(ctx context.Context, symbol, quote string, from, to time.Time, cursor string, limit int) (*HistoryPage, error)
+	GetCandles(ctx context.Context, symbol, quote string, from, to time.Time, bucket time.Duration) ([]*domain.Candle, error)
+	GetWindowStats(ctx context.Context, symbol, quote string, from, to time.Time) (*domain.WindowStats, error)
+	GetCoverage(ctx context.Context, symbol, quote string, from, to time.Time) (*domain.Coverage, error)
+	GetCrossRate(ctx context.Context, base, quote string, at time.Time, opts LookupOptions) (*domain.CrossRate, error)
+	GetCrossRateHistory(ctx context.Context, base, quote string, from, to time.Time, cursor string, limit int) (*CrossRatePage, error)
+	GetIndicator(ctx context.Context, symbol, quote, name string, params indicators.Params, from, to time.Time, bucket time.Duration) (*indicators.Series, error)
+	RunScheduler(ctx context.Context, clock Clock)
+	ApplyRetention(ctx context.Context) error
+	MaintainPartitions(ctx context.Context) error
+	AttachPriceMonth(ctx context.Context, month time.Time) (string, error)
+	StartBackfill(ctx context.Context, symbol, quote string, from, to time.Time) (*domain.BackfillJob, error)
+	GetBackfillJob(ctx context.Context, id uuid.UUID) (*domain.BackfillJob, error)
+	ResumeBackfill(ctx context.Context, id uuid.UUID) (*domain.BackfillJob, error)
+	RunBackfills(ctx context.Context)
+	ImportPrices(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error)
+	ExportPrices(ctx context.Context, opts ExportOptions) (*PriceExport, error)
+	SetDeadband(ctx context.Context, symbol string, band *domain.Deadband) (*domain.Currency, error)
+	SetCurrencyStatus(ctx context.Context, symbol string, status domain.CurrencyStatus) (*domain.Currency, error)
+	RequestPurge(ctx context.Context, symbol string) (*domain.PurgeConfirmation, error)
+	PurgeCurrency(ctx context.Context, symbol, token string) (*domain.PurgeCounts, error)
+	SetFetchSchedule(ctx context.Context, symbol string, sched domain.FetchSchedule) (*domain.Currency, error)
+	SearchCoins(ctx context.Context, query string, limit int) ([]CoinMatch, error)
+}
+
+type Config struct {
+	FetchInterval    time.Duration // fetch cadence of currencies without their own interval
+	MaxPriceDistance time.Duration // default lookup tolerance, 0 disables
+	Retention        domain.RetentionPolicy
+	Partitions       PartitionConfig
+	Backfill         BackfillConfig
+}
+
+// Per-request price lookup settings
+type LookupOptions struct {
+	Mode        domain.LookupMode
+	Quote       string        // defaults to domain.DefaultQuote
+	MaxDistance time.Duration // overrides Config.MaxPriceDistance when > 0
 }
 
 type cryptoService struct {
 	repo domain.CryptoRepository
 	api  ExternalPriceAPI
 	log  *logger.Logger
+	cfg  Config
+
+	backfillWake chan struct{} // signals RunBackfills that a job was queued
+	scheduleWake chan struct{} // signals RunScheduler that currencies changed
+	stored       *storedPrices
 }
 
-func NewCryptoService(repo domain.CryptoRepository, api ExternalPriceAPI, log *logger.Logger) CryptoService {
-	return &cryptoService{repo: repo, api: api, log: log}
+func NewCryptoService(repo domain.CryptoRepository, api ExternalPriceAPI, log *logger.Logger, cfg Config) CryptoService {
+	return &cryptoService{
+		repo:         repo,
+		api:          api,
+		log:          log,
+		cfg:          cfg,
+		backfillWake: make(chan struct{}, 1),
+		scheduleWake: make(chan struct{}, 1),
+		stored:       newStoredPrices(),
+	}
 }
 
-func (s *cryptoService) AddCurrency(ctx context.Context, symbol string) (*domain.Currency, error) {
-	ok, err := s.api.SymbolExists(symbol)
+// Tracks a provider coin, named by its ID or by a symbol that lists a single coin.
+// Ambiguous symbols fail with an *AmbiguousSymbolError listing the candidates
+func (s *cryptoService) AddCurrency(ctx context.Context, symbol, coinID string) (*domain.Currency, error) {
+	if coinID == "" && symbol != "" {
+		// A symbol tracked before keeps naming its coin, even when others share it
+		if cur, err := s.repo.GetCurrency(ctx, strings.ToUpper(symbol)); err == nil && !cur.HasLegacyProviderID() {
+			coinID = cur.ProviderID
+		}
+	}
+	coin, err := s.resolveCoin(symbol, coinID)
 	if err != nil {
-		s.log.Error("checking symbol failed", "symbol", symbol, "error", err)
-		return nil, fmt.Errorf("failed to verify symbol: %w", err)
+		return nil, err
 	}
-	if !ok {
-		return nil, domain.ErrInvalidSymbol
+
+	cur, err := domain.NewCurrency(coin.Symbol, coin.ID)
+	if err != nil {
+		return nil, err
 	}
+	err = s.repo.AddCurrency(ctx, cur)
+	if errors.Is(err, domain.ErrDuplicateCurrency) {
+		return s.reactivateCurrency(ctx, coin.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.wakeScheduler()
+	s.backfillNewCurrency(ctx, cur)
+	return cur, nil
+}
 
-	cur, err := domain.NewCurrency(symbol)
+// Adding a paused or archived currency resumes fetching it with its history intact.
+// The backfill fills the time it wasn't fetched
+func (s *cryptoService) reactivateCurrency(ctx context.Context, coinID string) (*domain.Currency, error) {
+	cur, err := s.repo.GetCurrency(ctx, coinID)
 	if err != nil {
 		return nil, err
 	}
-	if err := s.repo.AddCurrency(ctx, cur); err != nil {
+	if cur.Status == domain.CurrencyActive {
+		return nil, domain.ErrDuplicateCurrency
+	}
+	if cur, err = s.SetCurrencyStatus(ctx, coinID, domain.CurrencyActive); err != nil {
 		return nil, err
 	}
+	s.backfillNewCurrency(ctx, cur)
 	return cur, nil
 }
 
-func (s *cryptoService) RemoveCurrency(ctx context.Context, symbol string) error {
-	return s.repo.RemoveCurrency(ctx, symbol)
+func (s *cryptoService) GetPrice(ctx context.Context, symbol string, at time.Time, opts LookupOptions) (*domain.PriceLookup, error) {
+	quote, err := domain.NormalizeQuote(opts.Quote)
+	if err != nil {
+		return nil, err
+	}
+	currs, err := s.lookupCurrencies(ctx, []string{symbol})
+	if err != nil {
+		return nil, err
+	}
+	cur := currs[symbol]
+	mode := deadbandOf(cur).StepMode(opts.Mode)
+
+	// Old timestamps are only covered by the rollup tiers
+	var lookup *domain.PriceLookup
+	if res := s.cfg.Retention.ResolutionAt(at, time.Now().UTC()); res > 0 {
+		q := domain.PriceQuery{Symbol: symbol, Quote: quote, At: at, Resolution: res}
+		results, err := s.repo.GetPriceSnapshots(ctx, []domain.PriceQuery{q}, mode)
+		if err != nil {
+			return nil, err
+		}
+		lookup, err = results[0].Lookup, results[0].Err
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		lookup, err = s.repo.GetPriceSnapshot(ctx, symbol, quote, at, mode)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkDistance(lookup, opts.MaxDistance, cur); err != nil {
+		return nil, err
+	}
+	return lookup, nil
 }
 
-func (s *cryptoService) GetPrice(ctx context.Context, symbol string, at time.Time) (*domain.PriceSnapshot, error) {
-	snap, err := s.repo.GetPriceSnapshot(ctx, symbol, at)
+// Resolves many (symbol, quote, time) items at once, failures are reported per item.
+// Items without a quote currency use opts.Quote. Deadband currencies are looked up as step functions
+func (s *cryptoService) GetPrices(ctx context.Context, queries []domain.PriceQuery, opts LookupOptions) ([]domain.PriceLookupResult, error) {
+	now := time.Now().UTC()
+	symbols := make([]string, len(queries))
+	for i := range queries {
+		symbols[i] = queries[i].Symbol
+		if queries[i].Quote == "" {
+			queries[i].Quote = opts.Quote
+		}
+		quote, err := domain.NormalizeQuote(queries[i].Quote)
+		if err != nil {
+			return nil, err
+		}
+		queries[i].Quote = quote
+		queries[i].Resolution = s.cfg.Retention.ResolutionAt(queries[i].At, now)
+	}
+
+	currs, err := s.lookupCurrencies(ctx, symbols)
 	if err != nil {
 		return nil, err
 	}
-	return snap, nil
+
+	// One repository call per effective lookup mode
+	byMode := make(map[domain.LookupMode][]int)
+	for i, q := range queries {
+		mode := deadbandOf(currs[q.Symbol]).StepMode(opts.Mode)
+		byMode[mode] = append(byMode[mode], i)
+	}
+	results := make([]domain.PriceLookupResult, len(queries))
+	for mode, idxs := range byMode {
+		group := make([]domain.PriceQuery, len(idxs))
+		for j, i := range idxs {
+			group[j] = queries[i]
+		}
+		found, err := s.repo.GetPriceSnapshots(ctx, group, mode)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range idxs {
+			results[i] = found[j]
+		}
+	}
+
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		if err := s.checkDistance(results[i].Lookup, opts.MaxDistance, currs[queries[i].Symbol]); err != nil {
+			results[i] = domain.PriceLookupResult{Err: err}
+		}
+	}
+	return results, nil
+}
+
+// Rejects lookups beyond the tolerance and flags the ones off the currency's fetch cadence.
+// Prices read from a rollup tier are tolerated up to one bucket away, deadband
+// prices up to one heartbeat. `cur` is nil for untracked symbols
+func (s *cryptoService) checkDistance(lookup *domain.PriceLookup, maxDistance time.Duration, cur *domain.Currency) error {
+	if maxDistance <= 0 {
+		maxDistance = s.cfg.MaxPriceDistance
+	}
+	cadence := s.fetchInterval(cur)
+	if band := deadbandOf(cur); band != nil {
+		cadence = band.MaxGap(cadence)
+		if maxDistance > 0 {
+			maxDistance = max(maxDistance, cadence)
+		}
+	}
+	if len(lookup.Sources) > 0 {
+		if res := lookup.Sources[0].Resolution; res > 0 {
+			maxDistance = max(maxDistance, res)
+			cadence = max(cadence, res)
+		}
+	}
+	if maxDistance > 0 && lookup.Distance > maxDistance {
+		return fmt.Errorf("%w: nearest snapshot is %s away", domain.ErrStalePrice, lookup.Distance)
+	}
+	lookup.Stale = cadence > 0 && lookup.Distance > cadence
+	return nil
 }
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+)
+
+func (s *cryptoService) GetWindowStats(ctx context.Context, symbol, quote string, from, to time.Time) (*domain.WindowStats, error) {
+	if to.Before(from) {
+		return nil, domain.ErrInvalidTimeRange
+	}
+	quote, err := domain.NormalizeQuote(quote)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := s.repo.GetCurrency(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// Streamed, a window of any length only holds one page of snapshots
+	var acc domain.StatsAccumulator
+	if err := s.eachSnapshot(ctx, cur, quote, from, to, acc.Add); err != nil {
+		return nil, err
+	}
+	return acc.Stats()
+}
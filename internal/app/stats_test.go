@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// One currency with a raw price series, every other method panics
+type fakeSeriesRepo struct {
+	domain.CryptoRepository
+	cur     *domain.Currency
+	snaps   []*domain.PriceSnapshot // ordered by timestamp
+	largest int                     // most snapshots returned by one call
+}
+
+func newFakeSeriesRepo(start time.Time, step time.Duration, n int) *fakeSeriesRepo {
+	cur := &domain.Currency{ID: uuid.New(), Symbol: "BTC", ProviderID: "btc-bitcoin"}
+	snaps := make([]*domain.PriceSnapshot, n)
+	for i := range snaps {
+		snaps[i] = &domain.PriceSnapshot{
+			CurrencyID: cur.ID,
+			Quote:      domain.DefaultQuote,
+			Timestamp:  start.Add(time.Duration(i) * step),
+			Price:      decimal.NewFromInt(int64(100 + i%7)),
+		}
+	}
+	return &fakeSeriesRepo{cur: cur, snaps: snaps}
+}
+
+func (f *fakeSeriesRepo) GetCurrency(ctx context.Context, ref string) (*domain.Currency, error) {
+	if !f.cur.Matches(ref) {
+		return nil, domain.ErrNotTracked
+	}
+	return f.cur, nil
+}
+
+func (f *fakeSeriesRepo) ListPriceSnapshots(ctx context.Context, currencyID uuid.UUID, quote string, start, end time.Time, limit int) ([]*domain.PriceSnapshot, error) {
+	i := sort.Search(len(f.snaps), func(i int) bool { return !f.snaps[i].Timestamp.Before(start) })
+	var page []*domain.PriceSnapshot
+	for ; i < len(f.snaps) && !f.snaps[i].Timestamp.After(end); i++ {
+		if limit > 0 && len(page) == limit {
+			break
+		}
+		page = append(page, f.snaps[i])
+	}
+	f.largest = max(f.largest, len(page))
+	return page, nil
+}
+
+func TestGetWindowStatsStreamsPages(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	n := 2*MaxHistoryPageSize + 123
+	repo := newFakeSeriesRepo(start, 30*time.Second, n)
+	s := &cryptoService{repo: repo}
+
+	end := start.Add(time.Duration(n) * 30 * time.Second)
+	got, err := s.GetWindowStats(context.Background(), "BTC", "USD", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := domain.ComputeWindowStats(repo.snaps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != n || !got.Mean.Equal(want.Mean) || !got.Close.Equal(want.Close) ||
+		!got.Last.Equal(want.Last) || !approxEqual(got.Volatility, want.Volatility) {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+	if repo.largest > MaxHistoryPageSize {
+		t.Errorf("loaded %d snapshots at once, want at most %d", repo.largest, MaxHistoryPageSize)
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
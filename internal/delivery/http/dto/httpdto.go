@@ -1,31 +1,439 @@
 package httpdto
 
+import "github.com/shopspring/decimal"
+
 type AddCurrencyRequest struct {
-	Symbol string `json:"symbol" example:"BTC" validate:"required,uppercase,alphanum,min=1,max=10"`
+	Symbol     string `json:"symbol" example:"BTC" validate:"required_without=ProviderID,omitempty,uppercase,alphanum,min=1,max=10"`
+	ProviderID string `json:"provider_id" example:"btc-bitcoin" validate:"omitempty,max=100"` // picks one coin of an ambiguous symbol
 }
 
 type AddCurrencyResponse struct {
-	ID        string `json:"id" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
-	Symbol    string `json:"symbol" example:"BTC"`
-	CreatedAt string `json:"created_at" example:"2025-08-08T18:00:00Z"`
+	ID         string `json:"id" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	Symbol     string `json:"symbol" example:"BTC"`
+	ProviderID string `json:"provider_id" example:"btc-bitcoin"`
+	Status     string `json:"status" example:"active"`
+	CreatedAt  string `json:"created_at" example:"2025-08-08T18:00:00Z"`
+}
+
+// Provider coin, candidates of an ambiguous symbol
+type CoinResponse struct {
+	ProviderID string `json:"provider_id" example:"uni-uniswap"`
+	Symbol     string `json:"symbol" example:"UNI"`
+	Name       string `json:"name" example:"Uniswap"`
+	Rank       int    `json:"rank" example:"22"` // 0 when unranked
+	Type       string `json:"type" example:"token" enums:"coin,token"`
+}
+
+type CoinSearchRequest struct {
+	Query string `form:"q" validate:"required,max=50"`
+	Limit int    `form:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type CoinMatchResponse struct {
+	CoinResponse
+	Tracked bool   `json:"tracked" example:"true"`
+	Status  string `json:"status,omitempty" example:"active"` // status of the tracked currency
+}
+
+type CoinSearchResponse struct {
+	Query string              `json:"query" example:"uni"`
+	Coins []CoinMatchResponse `json:"coins"`
+}
+
+type AmbiguousSymbolResponse struct {
+	Error      string         `json:"error" example:"symbol matches several coins, pick one by provider_id"`
+	Candidates []CoinResponse `json:"candidates"`
 }
 
 type RemoveCurrencyRequest struct {
-	Symbol string `json:"symbol" example:"BTC" validate:"required,uppercase,alphanum,min=1,max=10"`
+	Symbol string `json:"symbol" example:"BTC" validate:"required,currency_ref"`
 }
 
 type RemoveCurrencyResponse struct {
-	Message string `json:"message" example:"removed BTC"`
+	Message string `json:"message" example:"archived BTC"`
+}
+
+type CurrencyListRequest struct {
+	Prefix string `form:"prefix" validate:"omitempty,uppercase,alphanum,max=10"`
+	Status string `form:"status" validate:"omitempty,oneof=active paused archived"`
+	Sort   string `form:"sort" validate:"omitempty,oneof=symbol created"` // defaults to symbol
+	Order  string `form:"order" validate:"omitempty,oneof=asc desc"`
+	Quote  string `form:"quote" validate:"omitempty,uppercase,alphanum,max=10"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=500"`
+	Cursor string `form:"cursor" validate:"omitempty,max=128"`
+}
+
+type CurrencyEntryResponse struct {
+	ID              string           `json:"id" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
+	Symbol          string           `json:"symbol" example:"BTC"`
+	ProviderID      string           `json:"provider_id" example:"btc-bitcoin"` // "legacy:<symbol>" while a currency added before IDs were stored is unresolved
+	Status          string           `json:"status" example:"active"`
+	IntervalSeconds int64            `json:"interval_seconds,omitempty" example:"10"`
+	Priority        int              `json:"priority" example:"90"`
+	CreatedAt       string           `json:"created_at" example:"2025-08-08T18:00:00Z"`
+	LatestPrice     *decimal.Decimal `json:"latest_price,omitempty" swaggertype:"string" example:"29753.55"` // omitted when nothing is stored
+	LatestTimestamp int64            `json:"latest_timestamp,omitempty" example:"1723123199"`
+}
+
+type CurrencyListResponse struct {
+	Quote      string                  `json:"quote" example:"USD"`
+	Currencies []CurrencyEntryResponse `json:"currencies"`
+	NextCursor string                  `json:"next_cursor,omitempty" example:"c3ltYm9sOkVUSA"`
+}
+
+type CurrencyStatusRequest struct {
+	Symbol string `uri:"symbol" json:"-" validate:"required,currency_ref"`
+	Status string `json:"status" example:"paused" enums:"active,paused,archived" validate:"required,oneof=active paused archived"`
+}
+
+type CurrencyStatusResponse struct {
+	Symbol    string `json:"symbol" example:"BTC"`
+	Status    string `json:"status" example:"paused"`
+	UpdatedAt string `json:"updated_at" example:"2025-08-08T18:00:00Z"`
+}
+
+type FetchScheduleRequest struct {
+	Symbol          string `uri:"symbol" json:"-" validate:"required,currency_ref"`
+	IntervalSeconds int64  `json:"interval_seconds" example:"10" validate:"omitempty,gte=1,lte=86400"` // omitted uses the server fetch interval
+	Priority        int    `json:"priority" example:"90" validate:"gte=0,lte=100"`
+}
+
+type FetchScheduleResponse struct {
+	Symbol          string `json:"symbol" example:"BTC"`
+	IntervalSeconds int64  `json:"interval_seconds,omitempty" example:"10"` // omitted when the server fetch interval applies
+	Priority        int    `json:"priority" example:"90"`
+}
+
+type PurgeRequest struct {
+	Symbol string `uri:"symbol" json:"-" validate:"required,currency_ref"`
+	Token  string `json:"token" example:"9f86d081884c7d659a2feaa0c55ad015" validate:"required,hexadecimal,len=32"`
+}
+
+// What a purge deletes, or deleted
+type PurgeCountsResponse struct {
+	Prices       int64 `json:"prices" example:"52560"`
+	Rollups      int64 `json:"rollups" example:"4380"`
+	BackfillJobs int64 `json:"backfill_jobs" example:"1"`
+}
+
+type PurgeTokenResponse struct {
+	Symbol    string              `json:"symbol" example:"BTC"`
+	Token     string              `json:"token" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	ExpiresAt string              `json:"expires_at" example:"2025-08-08T18:10:00Z"`
+	Deletes   PurgeCountsResponse `json:"deletes"`
+}
+
+type PurgeResponse struct {
+	Symbol  string              `json:"symbol" example:"BTC"`
+	Deleted PurgeCountsResponse `json:"deleted"`
 }
 
 type PriceQueryRequest struct {
-	Symbol    string `json:"symbol" example:"BTC" validate:"required,uppercase,alphanum,min=1,max=10"`
-	Timestamp int64  `json:"timestamp" example:"1723123200" validate:"required,gt=0"`
+	Symbol      string `json:"symbol" example:"BTC" validate:"required,currency_ref"`
+	Timestamp   int64  `json:"timestamp" example:"1723123200" validate:"required,gt=0"`
+	Quote       string `json:"quote" example:"USD" validate:"omitempty,uppercase,alphanum,max=10"` // defaults to USD
+	Mode        string `json:"mode" example:"nearest" enums:"nearest,before,after,interpolate" validate:"omitempty,oneof=nearest before after interpolate"`
+	MaxDistance int64  `json:"max_distance" example:"300" validate:"omitempty,gt=0"` // seconds, defaults to the server setting
 }
 
 type PriceQueryResponse struct {
+	Symbol          string               `json:"symbol" example:"BTC"`
+	Quote           string               `json:"quote" example:"USD"`
+	Mode            string               `json:"mode" example:"nearest"`
+	RequestedUnixTs int64                `json:"requested_timestamp" example:"1723123200"`
+	ReturnedUnixTs  int64                `json:"returned_timestamp" example:"1723123199"` // requested timestamp for interpolated prices
+	Price           decimal.Decimal      `json:"price" swaggertype:"string" example:"29753.55"`
+	Sources         []PricePointResponse `json:"sources"`
+	DistanceSeconds int64                `json:"distance_seconds" example:"1"`
+	Stale           bool                 `json:"stale" example:"false"` // distance_seconds exceeds the expected spacing of stored prices, see GetPrice
+}
+
+type BatchPriceQueryItem struct {
+	Symbol    string `json:"symbol" example:"BTC" validate:"required,currency_ref"`
+	Timestamp int64  `json:"timestamp" example:"1723123200" validate:"required,gt=0"`
+	Quote     string `json:"quote" example:"EUR" validate:"omitempty,uppercase,alphanum,max=10"` // defaults to the request quote
+}
+
+type BatchPriceQueryRequest struct {
+	Items       []BatchPriceQueryItem `json:"items" validate:"required,min=1,max=10000,dive"`
+	Quote       string                `json:"quote" example:"USD" validate:"omitempty,uppercase,alphanum,max=10"` // defaults to USD
+	Mode        string                `json:"mode" example:"nearest" enums:"nearest,before,after,interpolate" validate:"omitempty,oneof=nearest before after interpolate"`
+	MaxDistance int64                 `json:"max_distance" example:"300" validate:"omitempty,gt=0"` // seconds, defaults to the server setting
+}
+
+type BatchPriceItemResponse struct {
+	Symbol          string              `json:"symbol" example:"BTC"`
+	RequestedUnixTs int64               `json:"requested_timestamp" example:"1723123200"`
+	Result          *PriceQueryResponse `json:"result,omitempty"`
+	Error           string              `json:"error,omitempty" example:"price not found in the database"`
+}
+
+type BatchPriceQueryResponse struct {
+	Items  []BatchPriceItemResponse `json:"items"` // same order as the request
+	Failed int                      `json:"failed" example:"0"`
+}
+
+type PriceHistoryRequest struct {
+	Symbol string `uri:"symbol" validate:"required,currency_ref"`
+	Quote  string `form:"quote" validate:"omitempty,uppercase,alphanum,max=10"`
+	From   int64  `form:"from" validate:"required,gt=0"`
+	To     int64  `form:"to" validate:"omitempty,gtefield=From"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=5000"`
+	Cursor string `form:"cursor" validate:"omitempty,max=64"`
+}
+
+type PricePointResponse struct {
+	Timestamp         int64           `json:"timestamp" example:"1723123199"`
+	Price             decimal.Decimal `json:"price" swaggertype:"string" example:"29753.55"`
+	Volume24h         *float64        `json:"volume_24h,omitempty" example:"18250331472.5"`
+	MarketCap         *float64        `json:"market_cap,omitempty" example:"586000000000"`
+	PercentChange1h   *float64        `json:"percent_change_1h,omitempty" example:"0.12"`
+	PercentChange24h  *float64        `json:"percent_change_24h,omitempty" example:"-1.4"`
+	PercentChange7d   *float64        `json:"percent_change_7d,omitempty" example:"3.2"`
+	CirculatingSupply *float64        `json:"circulating_supply,omitempty" example:"19700000"`
+	Rank              *int            `json:"rank,omitempty" example:"1"`
+	ResolutionSeconds int64           `json:"resolution_seconds,omitempty" example:"300"` // set for downsampled prices
+}
+
+type PriceHistoryResponse struct {
+	Symbol     string               `json:"symbol" example:"BTC"`
+	Quote      string               `json:"quote" example:"USD"`
+	From       int64                `json:"from" example:"1723120000"`
+	To         int64                `json:"to" example:"1723123200"`
+	Prices     []PricePointResponse `json:"prices"`
+	NextCursor string               `json:"next_cursor,omitempty" example:"MTcyMzEyMzE5OQ"`
+}
+
+type CandlesRequest struct {
+	Symbol   string `uri:"symbol" validate:"required,currency_ref"`
+	Quote    string `form:"quote" validate:"omitempty,uppercase,alphanum,max=10"`
+	Interval string `form:"interval" validate:"required,oneof=1m 5m 1h 1d"`
+	From     int64  `form:"from" validate:"required,gt=0"`
+	To       int64  `form:"to" validate:"omitempty,gtefield=From"`
+}
+
+type CandleResponse struct {
+	Timestamp int64           `json:"timestamp" example:"1723122000"`
+	Open      decimal.Decimal `json:"open" swaggertype:"string" example:"29700.10"`
+	High      decimal.Decimal `json:"high" swaggertype:"string" example:"29810.00"`
+	Low       decimal.Decimal `json:"low" swaggertype:"string" example:"29650.25"`
+	Close     decimal.Decimal `json:"close" swaggertype:"string" example:"29753.55"`
+	Count     int             `json:"count" example:"120"`
+}
+
+type CandlesResponse struct {
+	Symbol   string           `json:"symbol" example:"BTC"`
+	Quote    string           `json:"quote" example:"USD"`
+	Interval string           `json:"interval" example:"1h"`
+	Candles  []CandleResponse `json:"candles"`
+}
+
+type WindowStatsRequest struct {
+	Symbol string `uri:"symbol" validate:"required,currency_ref"`
+	Quote  string `form:"quote" validate:"omitempty,uppercase,alphanum,max=10"`
+	From   int64  `form:"from" validate:"required,gt=0"`
+	To     int64  `form:"to" validate:"omitempty,gtefield=From"`
+}
+
+type WindowStatsResponse struct {
+	Symbol             string          `json:"symbol" example:"BTC"`
+	Quote              string          `json:"quote" example:"USD"`
+	From               int64           `json:"from" example:"1723036800"`
+	To                 int64           `json:"to" example:"1723123200"`
+	Count              int             `json:"count" example:"2880"`
+	FirstUnixTs        int64           `json:"first_timestamp" example:"1723036815"`
+	LastUnixTs         int64           `json:"last_timestamp" example:"1723123185"`
+	Open               decimal.Decimal `json:"open" swaggertype:"string" example:"28900.00"`
+	Close              decimal.Decimal `json:"close" swaggertype:"string" example:"29753.55"`
+	Change             decimal.Decimal `json:"change" swaggertype:"string" example:"853.55"`
+	ChangePercent      float64         `json:"change_percent" example:"2.95"`
+	Min                decimal.Decimal `json:"min" swaggertype:"string" example:"28710.12"`
+	MinUnixTs          int64           `json:"min_timestamp" example:"1723051200"`
+	Max                decimal.Decimal `json:"max" swaggertype:"string" example:"29810.00"`
+	MaxUnixTs          int64           `json:"max_timestamp" example:"1723118400"`
+	Mean               decimal.Decimal `json:"mean" swaggertype:"string" example:"29310.44"`
+	Volatility         float64         `json:"volatility_annualized" example:"0.54"`
+	MaxDrawdownPercent float64         `json:"max_drawdown_percent" example:"1.87"`
+}
+
+type DeadbandRequest struct {
+	Symbol           string   `uri:"symbol" json:"-" validate:"required,currency_ref"`
+	ThresholdPercent *float64 `json:"threshold_percent" example:"0.1" validate:"required,gte=0,lte=100"`
+	HeartbeatSeconds int64    `json:"heartbeat_seconds" example:"900" validate:"required,gte=1,lte=86400"`
+}
+
+type CurrencySymbolRequest struct {
+	Symbol string `uri:"symbol" validate:"required,currency_ref"`
+}
+
+type StorageModeResponse struct {
+	Symbol           string   `json:"symbol" example:"BTC"`
+	Mode             string   `json:"mode" example:"deadband" enums:"all,deadband"`
+	ThresholdPercent *float64 `json:"threshold_percent,omitempty" example:"0.1"`
+	HeartbeatSeconds int64    `json:"heartbeat_seconds,omitempty" example:"900"`
+}
+
+type CoverageRequest struct {
+	Symbol string `uri:"symbol" validate:"required,currency_ref"`
+	Quote  string `form:"quote" validate:"omitempty,uppercase,alphanum,max=10"`
+	From   int64  `form:"from" validate:"required,gt=0"`
+	To     int64  `form:"to" validate:"omitempty,gtefield=From"`
+}
+
+type CoverageResponse struct {
+	Symbol            string                `json:"symbol" example:"BTC"`
+	Quote             string                `json:"quote" example:"USD"`
+	From              int64                 `json:"from" example:"1723036800"`
+	To                int64                 `json:"to" example:"1723123200"`
+	IntervalSeconds   int64                 `json:"interval_seconds" example:"30"`
+	Count             int                   `json:"count" example:"2850"`
+	MissingIntervals  int                   `json:"missing_intervals" example:"30"`
+	CoveragePercent   float64               `json:"coverage_percent" example:"98.96"`
+	LongestGapSeconds int64                 `json:"longest_gap_seconds" example:"630"`
+	Gaps              []CoverageGapResponse `json:"gaps"`
+}
+
+type CoverageGapResponse struct {
+	From            int64 `json:"from" example:"1723051200"`
+	To              int64 `json:"to" example:"1723051830"`
+	DurationSeconds int64 `json:"duration_seconds" example:"630"`
+	Missing         int   `json:"missing" example:"20"`
+}
+
+type IndicatorRequest struct {
+	Symbol   string  `uri:"symbol" validate:"required,currency_ref"`
+	Quote    string  `form:"quote" validate:"omitempty,uppercase,alphanum,max=10"`
+	Name     string  `uri:"name" validate:"required,oneof=sma ema rsi bollinger macd"`
+	Interval string  `form:"interval" validate:"required,oneof=1m 5m 1h 1d"`
+	From     int64   `form:"from" validate:"required,gt=0"`
+	To       int64   `form:"to" validate:"omitempty,gtefield=From"`
+	Period   int     `form:"period" validate:"omitempty,min=2,max=500"`
+	K        float64 `form:"k" validate:"omitempty,gt=0,lte=10"`
+	Fast     int     `form:"fast" validate:"omitempty,min=2,max=500"`
+	Slow     int     `form:"slow" validate:"omitempty,min=2,max=500"`
+	Signal   int     `form:"signal" validate:"omitempty,min=2,max=500"`
+}
+
+type IndicatorPointResponse struct {
+	Timestamp int64              `json:"timestamp" example:"1723122000"`
+	Values    map[string]float64 `json:"values"`
+}
+
+type IndicatorParamsResponse struct {
+	Period int     `json:"period,omitempty" example:"20"`
+	K      float64 `json:"k,omitempty" example:"2"`
+	Fast   int     `json:"fast,omitempty" example:"12"`
+	Slow   int     `json:"slow,omitempty" example:"26"`
+	Signal int     `json:"signal,omitempty" example:"9"`
+}
+
+type IndicatorResponse struct {
+	Symbol    string                   `json:"symbol" example:"BTC"`
+	Quote     string                   `json:"quote" example:"USD"`
+	Indicator string                   `json:"indicator" example:"bollinger"`
+	Interval  string                   `json:"interval" example:"1h"`
+	Params    IndicatorParamsResponse  `json:"params"`
+	Lines     []string                 `json:"lines" example:"middle,upper,lower"`
+	Points    []IndicatorPointResponse `json:"points"`
+}
+
+type PairPriceRequest struct {
+	Base        string `uri:"base" validate:"required,currency_ref"`
+	Quote       string `uri:"quote" validate:"required,currency_ref,nefield=Base"`
+	At          int64  `form:"at" validate:"required,gt=0"`
+	Mode        string `form:"mode" validate:"omitempty,oneof=nearest before after interpolate"`
+	MaxDistance int64  `form:"max_distance" validate:"omitempty,gt=0"`
+}
+
+type PairPriceResponse struct {
+	Base                  string             `json:"base" example:"BTC"`
+	Quote                 string             `json:"quote" example:"ETH"`
+	RequestedUnixTs       int64              `json:"requested_timestamp" example:"1723123200"`
+	Rate                  decimal.Decimal    `json:"rate" swaggertype:"string" example:"18.42"`
+	AlignmentErrorSeconds int64              `json:"alignment_error_seconds" example:"3"`
+	BasePrice             PriceQueryResponse `json:"base_price"`
+	QuotePrice            PriceQueryResponse `json:"quote_price"`
+}
+
+type PairHistoryRequest struct {
+	Base   string `uri:"base" validate:"required,currency_ref"`
+	Quote  string `uri:"quote" validate:"required,currency_ref,nefield=Base"`
+	From   int64  `form:"from" validate:"required,gt=0"`
+	To     int64  `form:"to" validate:"omitempty,gtefield=From"`
+	Limit  int    `form:"limit" validate:"omitempty,min=1,max=5000"`
+	Cursor string `form:"cursor" validate:"omitempty,max=64"`
+}
+
+type PairPointResponse struct {
+	Timestamp             int64           `json:"timestamp" example:"1723123199"`
+	Rate                  decimal.Decimal `json:"rate" swaggertype:"string" example:"18.42"`
+	BasePrice             decimal.Decimal `json:"base_price" swaggertype:"string" example:"29753.55"`
+	QuotePrice            decimal.Decimal `json:"quote_price" swaggertype:"string" example:"1615.30"`
+	AlignmentErrorSeconds int64           `json:"alignment_error_seconds" example:"3"`
+}
+
+type PairHistoryResponse struct {
+	Base       string              `json:"base" example:"BTC"`
+	Quote      string              `json:"quote" example:"ETH"`
+	From       int64               `json:"from" example:"1723120000"`
+	To         int64               `json:"to" example:"1723123200"`
+	Rates      []PairPointResponse `json:"rates"`
+	NextCursor string              `json:"next_cursor,omitempty" example:"MTcyMzEyMzE5OQ"`
+}
+
+type BackfillRequest struct {
+	Symbol string `json:"symbol" example:"BTC" validate:"required,currency_ref"`
+	Quote  string `json:"quote,omitempty" example:"USD" validate:"omitempty,uppercase,alphanum,max=10"`
+	From   int64  `json:"from" example:"1720444800" validate:"required,gt=0"`
+	To     int64  `json:"to,omitempty" example:"1723123200" validate:"omitempty,gtfield=From"`
+}
+
+type BackfillJobRequest struct {
+	ID string `uri:"id" validate:"required,uuid"`
+}
+
+type BackfillJobResponse struct {
+	ID              string  `json:"id" example:"3fa85f64-5717-4562-b3fc-2c963f66afa6"`
 	Symbol          string  `json:"symbol" example:"BTC"`
-	RequestedUnixTs int64   `json:"requested_timestamp" example:"1723123200"`
-	ReturnedUnixTs  int64   `json:"returned_timestamp" example:"1723123199"`
-	Price           float64 `json:"price" example:"29753.55"`
+	Quote           string  `json:"quote" example:"USD"`
+	From            int64   `json:"from" example:"1720444800"`
+	To              int64   `json:"to" example:"1723123200"`
+	IntervalSeconds int64   `json:"interval_seconds" example:"3600"`
+	Cursor          int64   `json:"cursor" example:"1721000000"`
+	Status          string  `json:"status" example:"running" enums:"pending,running,done,failed"`
+	ProgressPercent float64 `json:"progress_percent" example:"20.7"`
+	Inserted        int64   `json:"inserted" example:"154"`
+	Error           string  `json:"error,omitempty"`
+	CreatedAt       string  `json:"created_at" example:"2025-08-08T18:00:00Z"`
+	UpdatedAt       string  `json:"updated_at" example:"2025-08-08T18:02:00Z"`
+}
+
+type ImportRequest struct {
+	Format     string `form:"format" example:"csv" validate:"omitempty,oneof=csv ndjson jsonl"`
+	OnConflict string `form:"on_conflict" example:"skip" validate:"omitempty,oneof=skip overwrite fail"`
+}
+
+type ImportRowErrorResponse struct {
+	Line   int    `json:"line" example:"12"`
+	Reason string `json:"reason" example:"invalid timestamp \"yesterday\", want Unix seconds or RFC 3339"`
+}
+
+type ImportReportResponse struct {
+	Rows     int                      `json:"rows" example:"1200"`
+	Accepted int                      `json:"accepted" example:"1190"`
+	Rejected int                      `json:"rejected" example:"10"`
+	Written  int64                    `json:"written" example:"1100"`
+	Skipped  int64                    `json:"skipped" example:"90"`
+	From     int64                    `json:"from,omitempty" example:"1546300800"`
+	To       int64                    `json:"to,omitempty" example:"1723123200"`
+	Errors   []ImportRowErrorResponse `json:"errors,omitempty"`
+}
+
+type ExportRequest struct {
+	Symbols string `form:"symbols" example:"BTC,ETH" validate:"required,max=500"`
+	Quote   string `form:"quote" validate:"omitempty,uppercase,alphanum,max=10"`
+	From    int64  `form:"from" validate:"required,gt=0"`
+	To      int64  `form:"to" validate:"omitempty,gtefield=From"`
+	Format  string `form:"format" validate:"omitempty,oneof=csv ndjson parquet"`
+	Gzip    bool   `form:"gzip"`
 }
@@ -3,15 +3,19 @@ package http
 import (
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Neroframe/crypto-tracker/internal/app"
 	httpdto "github.com/Neroframe/crypto-tracker/internal/delivery/http/dto"
 	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/internal/indicators"
 	"github.com/Neroframe/crypto-tracker/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 )
 
 type CryptoHandler struct {
@@ -26,14 +30,14 @@ func NewCryptoHandler(v *validator.Validate, log *logger.Logger, svc app.CryptoS
 
 // AddCurrency godoc
 // @Summary Add a new currency
-// @Description Adds a cryptocurrency by symbol to start tracking
+// @Description Adds a cryptocurrency by symbol or provider coin ID to start tracking. A symbol shared by several provider coins is rejected with the candidates, add one of them by provider_id. Adding a paused or archived currency makes it active again with its history intact
 // @Tags Currency
 // @Accept json
 // @Produce json
 // @Param input body httpdto.AddCurrencyRequest true "Currency Symbol"
 // @Success 201 {object} map[string]httpdto.AddCurrencyResponse
 // @Failure 400 {object} map[string]string
-// @Failure 409 {object} map[string]string
+// @Failure 409 {object} httpdto.AmbiguousSymbolResponse
 // @Failure 500 {object} map[string]string
 // @Router /currency/add [post]
 func (h *CryptoHandler) AddCurrency(c *gin.Context) {
@@ -46,10 +50,20 @@ func (h *CryptoHandler) AddCurrency(c *gin.Context) {
 		return
 	}
 
-	cur, err := h.svc.AddCurrency(c.Request.Context(), req.Symbol)
+	cur, err := h.svc.AddCurrency(c.Request.Context(), req.Symbol, req.ProviderID)
 	if err != nil {
+		var ambiguous *app.AmbiguousSymbolError
 		switch {
-		case errors.Is(err, domain.ErrInvalidSymbol):
+		case errors.As(err, &ambiguous):
+			resp := httpdto.AmbiguousSymbolResponse{
+				Error:      app.ErrAmbiguousSymbol.Error(),
+				Candidates: make([]httpdto.CoinResponse, len(ambiguous.Candidates)),
+			}
+			for i, coin := range ambiguous.Candidates {
+				resp.Candidates[i] = toCoinResponse(coin)
+			}
+			c.JSON(http.StatusConflict, resp)
+		case errors.Is(err, domain.ErrInvalidSymbol), errors.Is(err, app.ErrUnknownCoin):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, domain.ErrDuplicateCurrency):
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
@@ -61,16 +75,18 @@ func (h *CryptoHandler) AddCurrency(c *gin.Context) {
 	}
 
 	resp := httpdto.AddCurrencyResponse{
-		ID:        cur.ID.String(),
-		Symbol:    cur.Symbol,
-		CreatedAt: cur.CreatedAt.Format(time.RFC3339),
+		ID:         cur.ID.String(),
+		Symbol:     cur.Symbol,
+		ProviderID: cur.ProviderID,
+		Status:     string(cur.Status),
+		CreatedAt:  cur.CreatedAt.Format(time.RFC3339),
 	}
 	c.JSON(http.StatusCreated, gin.H{"data": resp})
 }
 
 // RemoveCurrency godoc
 // @Summary Remove a tracked currency
-// @Description Removes a tracked cryptocurrency by symbol
+// @Description Archives a tracked cryptocurrency: its prices are no longer fetched, stored history stays queryable. Deleting the history takes an explicit purge
 // @Tags Currency
 // @Accept json
 // @Produce json
@@ -78,6 +94,7 @@ func (h *CryptoHandler) AddCurrency(c *gin.Context) {
 // @Success 200 {object} map[string]httpdto.RemoveCurrencyResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /currency/remove [post]
 func (h *CryptoHandler) RemoveCurrency(c *gin.Context) {
@@ -98,6 +115,8 @@ func (h *CryptoHandler) RemoveCurrency(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		case errors.Is(err, domain.ErrNotTracked):
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		default:
 			log.Error("service.RemoveCurrency failed", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -106,14 +125,404 @@ func (h *CryptoHandler) RemoveCurrency(c *gin.Context) {
 	}
 
 	resp := httpdto.RemoveCurrencyResponse{
-		Message: fmt.Sprintf("removed %s", req.Symbol),
+		Message: fmt.Sprintf("archived %s", req.Symbol),
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// SetDeadband godoc
+// @Summary Enable deadband storage
+// @Description Stores fetched prices of the currency only when they move by more than threshold_percent from the last stored price, or when heartbeat_seconds have passed since it. Lookups of such currencies return the last stored price at or before the requested time
+// @Tags Currency
+// @Accept json
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param input body httpdto.DeadbandRequest true "Deadband settings"
+// @Success 200 {object} map[string]httpdto.StorageModeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/deadband [put]
+func (h *CryptoHandler) SetDeadband(c *gin.Context) {
+	log := h.logger.With("handler", "SetDeadband")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1024)
+
+	var req httpdto.DeadbandRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path params"})
+		return
+	}
+	if !BindAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	band := &domain.Deadband{
+		ThresholdPercent: *req.ThresholdPercent,
+		Heartbeat:        time.Duration(req.HeartbeatSeconds) * time.Second,
+	}
+	cur, err := h.svc.SetDeadband(c.Request.Context(), req.Symbol, band)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidDeadband):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.SetDeadband failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toStorageModeResponse(cur)})
+}
+
+// ClearDeadband godoc
+// @Summary Disable deadband storage
+// @Description Stores every fetched price of the currency again
+// @Tags Currency
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Success 200 {object} map[string]httpdto.StorageModeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/deadband [delete]
+func (h *CryptoHandler) ClearDeadband(c *gin.Context) {
+	log := h.logger.With("handler", "ClearDeadband")
+
+	var req httpdto.CurrencySymbolRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	cur, err := h.svc.SetDeadband(c.Request.Context(), req.Symbol, nil)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.SetDeadband failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toStorageModeResponse(cur)})
+}
+
+// ListCurrencies godoc
+// @Summary List tracked currencies
+// @Description Tracked currencies with their status, fetch schedule and latest stored price, filtered by symbol prefix and status. Pages are sorted by symbol or creation time, pass next_cursor as cursor for the next one
+// @Tags Currency
+// @Produce json
+// @Param prefix query string false "Symbol prefix"
+// @Param status query string false "Status" Enums(active, paused, archived)
+// @Param sort query string false "Sort field" Enums(symbol, created) default(symbol)
+// @Param order query string false "Sort order" Enums(asc, desc) default(asc)
+// @Param quote query string false "Quote currency of the latest price" default(USD)
+// @Param limit query int false "Page size" default(50) maximum(500)
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} map[string]httpdto.CurrencyListResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currencies [get]
+func (h *CryptoHandler) ListCurrencies(c *gin.Context) {
+	log := h.logger.With("handler", "ListCurrencies")
+
+	var req httpdto.CurrencyListRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	opts := app.CurrencyListOptions{
+		Prefix: req.Prefix,
+		Status: domain.CurrencyStatus(req.Status),
+		Sort:   domain.CurrencySort(req.Sort),
+		Desc:   req.Order == "desc",
+		Quote:  req.Quote,
+		Cursor: req.Cursor,
+		Limit:  req.Limit,
+	}
+	page, err := h.svc.ListCurrencies(c.Request.Context(), opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidQuote),
+			errors.Is(err, domain.ErrInvalidSort),
+			errors.Is(err, domain.ErrInvalidStatus),
+			errors.Is(err, app.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.ListCurrencies failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	resp := httpdto.CurrencyListResponse{
+		Quote:      page.Quote,
+		Currencies: make([]httpdto.CurrencyEntryResponse, len(page.Currencies)),
+		NextCursor: page.NextCursor,
+	}
+	for i, l := range page.Currencies {
+		resp.Currencies[i] = toCurrencyEntryResponse(l)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// SearchCoins godoc
+// @Summary Search the provider coin catalog
+// @Description Autocomplete over the symbols and names of Coinpaprika coins. Prefix matches come before fuzzy ones (substrings, letters in order, one typo), each ordered by market cap rank. Coins already tracked are marked with the status of their currency
+// @Tags Coins
+// @Produce json
+// @Param q query string true "Symbol or name fragment"
+// @Param limit query int false "Maximum results" default(20) maximum(100)
+// @Success 200 {object} map[string]httpdto.CoinSearchResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /coins/search [get]
+func (h *CryptoHandler) SearchCoins(c *gin.Context) {
+	log := h.logger.With("handler", "SearchCoins")
+
+	var req httpdto.CoinSearchRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	matches, err := h.svc.SearchCoins(c.Request.Context(), req.Query, req.Limit)
+	if err != nil {
+		log.Error("service.SearchCoins failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	resp := httpdto.CoinSearchResponse{
+		Query: req.Query,
+		Coins: make([]httpdto.CoinMatchResponse, len(matches)),
+	}
+	for i, m := range matches {
+		resp.Coins[i] = httpdto.CoinMatchResponse{
+			CoinResponse: toCoinResponse(m.Coin),
+			Tracked:      m.Tracked,
+			Status:       m.Status,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// SetCurrencyStatus godoc
+// @Summary Change the status of a currency
+// @Description Only active currencies are fetched. Paused currencies stay tracked, archived ones are removed from tracking. Stored prices are kept in every status
+// @Tags Currency
+// @Accept json
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param input body httpdto.CurrencyStatusRequest true "New status"
+// @Success 200 {object} map[string]httpdto.CurrencyStatusResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/status [put]
+func (h *CryptoHandler) SetCurrencyStatus(c *gin.Context) {
+	log := h.logger.With("handler", "SetCurrencyStatus")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1024)
+
+	var req httpdto.CurrencyStatusRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path params"})
+		return
+	}
+	if !BindAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	cur, err := h.svc.SetCurrencyStatus(c.Request.Context(), req.Symbol, domain.CurrencyStatus(req.Status))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidStatus):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.SetCurrencyStatus failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	resp := httpdto.CurrencyStatusResponse{
+		Symbol:    cur.Symbol,
+		Status:    string(cur.Status),
+		UpdatedAt: cur.UpdatedAt.Format(time.RFC3339),
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// SetFetchSchedule godoc
+// @Summary Set the fetch schedule of a currency
+// @Description Fetches the currency every interval_seconds instead of the server fetch interval. When more fetches are due than the provider rate limit allows, higher priorities (0-100) go first
+// @Tags Currency
+// @Accept json
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param input body httpdto.FetchScheduleRequest true "Fetch schedule"
+// @Success 200 {object} map[string]httpdto.FetchScheduleResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/schedule [put]
+func (h *CryptoHandler) SetFetchSchedule(c *gin.Context) {
+	log := h.logger.With("handler", "SetFetchSchedule")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1024)
+
+	var req httpdto.FetchScheduleRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path params"})
+		return
+	}
+	if !BindAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	sched := domain.FetchSchedule{
+		Interval: time.Duration(req.IntervalSeconds) * time.Second,
+		Priority: req.Priority,
+	}
+	cur, err := h.svc.SetFetchSchedule(c.Request.Context(), req.Symbol, sched)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidSchedule):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.SetFetchSchedule failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	resp := httpdto.FetchScheduleResponse{
+		Symbol:          cur.Symbol,
+		IntervalSeconds: int64(cur.Schedule.Interval / time.Second),
+		Priority:        cur.Schedule.Priority,
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// RequestPurge godoc
+// @Summary Request a purge token
+// @Description First step of deleting an archived currency and all of its stored data. Returns a token valid for 10 minutes and the number of rows the purge would delete
+// @Tags Currency
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Success 200 {object} map[string]httpdto.PurgeTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/purge/token [post]
+func (h *CryptoHandler) RequestPurge(c *gin.Context) {
+	log := h.logger.With("handler", "RequestPurge")
+
+	var req httpdto.CurrencySymbolRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	conf, err := h.svc.RequestPurge(c.Request.Context(), req.Symbol)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.RequestPurge failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	resp := httpdto.PurgeTokenResponse{
+		Symbol:    conf.Symbol,
+		Token:     conf.Token,
+		ExpiresAt: conf.ExpiresAt.Format(time.RFC3339),
+		Deletes:   toPurgeCountsResponse(conf.Counts),
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// PurgeCurrency godoc
+// @Summary Purge an archived currency
+// @Description Irreversibly deletes an archived currency with its prices, rollups and backfill jobs. Needs the token from /currency/{symbol}/purge/token
+// @Tags Currency
+// @Accept json
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param input body httpdto.PurgeRequest true "Purge token"
+// @Success 200 {object} map[string]httpdto.PurgeResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/purge [post]
+func (h *CryptoHandler) PurgeCurrency(c *gin.Context) {
+	log := h.logger.With("handler", "PurgeCurrency")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1024)
+
+	var req httpdto.PurgeRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path params"})
+		return
+	}
+	if !BindAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	counts, err := h.svc.PurgeCurrency(c.Request.Context(), req.Symbol, req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPurgeToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotArchived), errors.Is(err, domain.ErrBackfillActive):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.PurgeCurrency failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
 	}
+
+	resp := httpdto.PurgeResponse{Symbol: req.Symbol, Deleted: toPurgeCountsResponse(*counts)}
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
 // GetPrice godoc
 // @Summary Get historical price snapshot
-// @Description Returns the closest price snapshot at or before the given timestamp
+// @Description Returns the price at the given timestamp. mode selects the nearest snapshot (default), the last one at or before, the first one at or after, or a linear interpolation between both neighbours. Returns 422 when the nearest snapshot is further than max_distance seconds away. stale does not depend on max_distance: it is set when distance_seconds exceeds the expected spacing of stored prices, which is the fetch interval of the currency, its deadband heartbeat, or the bucket of the rollup the price was read from, whichever is longest
 // @Tags Price
 // @Accept json
 // @Produce json
@@ -121,6 +530,8 @@ func (h *CryptoHandler) RemoveCurrency(c *gin.Context) {
 // @Success 200 {object} map[string]httpdto.PriceQueryResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /currency/price [post]
 func (h *CryptoHandler) GetPrice(c *gin.Context) {
@@ -133,20 +544,37 @@ func (h *CryptoHandler) GetPrice(c *gin.Context) {
 		return
 	}
 
-	// Check ts boundries
-	if req.Timestamp < 0 || req.Timestamp > time.Now().Unix()+3600 {
+	if !validTimestamp(req.Timestamp) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "timestamp out of range"})
 		return
 	}
 
+	mode, err := domain.ParseLookupMode(req.Mode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	ts := time.Unix(req.Timestamp, 0).UTC()
-	snap, err := h.svc.GetPrice(c.Request.Context(), req.Symbol, ts)
+	opts := app.LookupOptions{
+		Mode:        mode,
+		Quote:       req.Quote,
+		MaxDistance: time.Duration(req.MaxDistance) * time.Second,
+	}
+	lookup, err := h.svc.GetPrice(c.Request.Context(), req.Symbol, ts, opts)
 	if err != nil {
 		switch {
-		case errors.Is(err, domain.ErrInvalidSymbol):
+		case errors.Is(err, domain.ErrInvalidSymbol),
+			errors.Is(err, domain.ErrInvalidQuote),
+			errors.Is(err, domain.ErrInvalidLookupMode):
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		case errors.Is(err, domain.ErrPriceNotFound):
+		case errors.Is(err, domain.ErrNotTracked),
+			errors.Is(err, domain.ErrPriceNotFound):
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrStalePrice):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 		default:
 			log.Error("service.GetPrice failed", "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -154,23 +582,1068 @@ func (h *CryptoHandler) GetPrice(c *gin.Context) {
 		return
 	}
 
-	resp := httpdto.PriceQueryResponse{
-		Symbol:          req.Symbol,
-		RequestedUnixTs: req.Timestamp,
-		ReturnedUnixTs:  snap.Timestamp.Unix(),
-		Price:           snap.Price,
-	}
+	resp := toPriceQueryResponse(req.Symbol, lookup)
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
-// On error writes the HTTP 400 and returns false
-func BindAndValidate(c *gin.Context, validate *validator.Validate, obj interface{}) bool {
-	if err := c.ShouldBindJSON(obj); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
-		return false
+// GetPrices godoc
+// @Summary Get many historical prices
+// @Description Resolves up to 10000 (symbol, timestamp) pairs in one call. Items are answered in request order, each with either a result or an error
+// @Tags Price
+// @Accept json
+// @Produce json
+// @Param input body httpdto.BatchPriceQueryRequest true "Pairs, lookup mode and tolerance"
+// @Success 200 {object} map[string]httpdto.BatchPriceQueryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/prices [post]
+func (h *CryptoHandler) GetPrices(c *gin.Context) {
+	log := h.logger.With("handler", "GetPrices")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, batchBodyLimit)
+
+	var req httpdto.BatchPriceQueryRequest
+	if !BindAndValidate(c, h.validator, &req) {
+		return
 	}
-	if err := validate.Struct(obj); err != nil {
-		// pick the first field error
+
+	mode, err := domain.ParseLookupMode(req.Mode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	queries := make([]domain.PriceQuery, len(req.Items))
+	for i, item := range req.Items {
+		if !validTimestamp(item.Timestamp) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("items[%d]: timestamp out of range", i)})
+			return
+		}
+		queries[i] = domain.PriceQuery{
+			Symbol: item.Symbol,
+			Quote:  item.Quote,
+			At:     time.Unix(item.Timestamp, 0).UTC(),
+		}
+	}
+
+	opts := app.LookupOptions{
+		Mode:        mode,
+		Quote:       req.Quote,
+		MaxDistance: time.Duration(req.MaxDistance) * time.Second,
+	}
+	results, err := h.svc.GetPrices(c.Request.Context(), queries, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidQuote):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetPrices failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	resp := httpdto.BatchPriceQueryResponse{
+		Items: make([]httpdto.BatchPriceItemResponse, len(results)),
+	}
+	for i, res := range results {
+		item := httpdto.BatchPriceItemResponse{
+			Symbol:          req.Items[i].Symbol,
+			RequestedUnixTs: req.Items[i].Timestamp,
+		}
+		if res.Err != nil {
+			item.Error = res.Err.Error()
+			resp.Failed++
+		} else {
+			r := toPriceQueryResponse(item.Symbol, res.Lookup)
+			item.Result = &r
+		}
+		resp.Items[i] = item
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// GetPriceHistory godoc
+// @Summary Get price history
+// @Description Returns the ordered price snapshots within [from, to]. Use next_cursor to fetch the following page
+// @Tags Price
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param quote query string false "Quote currency, defaults to USD"
+// @Param from query int true "Window start (Unix seconds)"
+// @Param to query int false "Window end (Unix seconds), defaults to now"
+// @Param limit query int false "Page size (default 500, max 5000)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} map[string]httpdto.PriceHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/history [get]
+func (h *CryptoHandler) GetPriceHistory(c *gin.Context) {
+	log := h.logger.With("handler", "GetPriceHistory")
+
+	var req httpdto.PriceHistoryRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+
+	from := time.Unix(req.From, 0).UTC()
+	to := time.Unix(req.To, 0).UTC()
+	page, err := h.svc.GetPriceHistory(c.Request.Context(), req.Symbol, req.Quote, from, to, req.Cursor, req.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrInvalidQuote),
+			errors.Is(err, app.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetPriceHistory failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	prices := make([]httpdto.PricePointResponse, len(page.Snapshots))
+	for i, snap := range page.Snapshots {
+		prices[i] = toPricePointResponse(snap)
+	}
+
+	resp := httpdto.PriceHistoryResponse{
+		Symbol:     req.Symbol,
+		Quote:      quoteOrDefault(req.Quote),
+		From:       req.From,
+		To:         req.To,
+		Prices:     prices,
+		NextCursor: page.NextCursor,
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// GetCandles godoc
+// @Summary Get OHLC candles
+// @Description Aggregates stored prices into open/high/low/close candles aligned to the interval
+// @Tags Price
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param quote query string false "Quote currency, defaults to USD"
+// @Param interval query string true "Bucket size" Enums(1m, 5m, 1h, 1d)
+// @Param from query int true "Window start (Unix seconds)"
+// @Param to query int false "Window end (Unix seconds), defaults to now"
+// @Success 200 {object} map[string]httpdto.CandlesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/candles [get]
+func (h *CryptoHandler) GetCandles(c *gin.Context) {
+	log := h.logger.With("handler", "GetCandles")
+
+	var req httpdto.CandlesRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+
+	bucket, err := domain.ParseCandleInterval(req.Interval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	from := time.Unix(req.From, 0).UTC()
+	to := time.Unix(req.To, 0).UTC()
+	candles, err := h.svc.GetCandles(c.Request.Context(), req.Symbol, req.Quote, from, to, bucket)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrInvalidInterval),
+			errors.Is(err, domain.ErrInvalidQuote),
+			errors.Is(err, domain.ErrRangeTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetCandles failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	out := make([]httpdto.CandleResponse, len(candles))
+	for i, cd := range candles {
+		out[i] = httpdto.CandleResponse{
+			Timestamp: cd.Start.Unix(),
+			Open:      cd.Open,
+			High:      cd.High,
+			Low:       cd.Low,
+			Close:     cd.Close,
+			Count:     cd.Count,
+		}
+	}
+
+	resp := httpdto.CandlesResponse{
+		Symbol:   req.Symbol,
+		Quote:    quoteOrDefault(req.Quote),
+		Interval: req.Interval,
+		Candles:  out,
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// GetWindowStats godoc
+// @Summary Get window statistics
+// @Description Summarises stored prices within [from, to]: change, min and max with their timestamps, mean, annualised volatility of log returns and max drawdown
+// @Tags Price
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param quote query string false "Quote currency, defaults to USD"
+// @Param from query int true "Window start (Unix seconds)"
+// @Param to query int false "Window end (Unix seconds), defaults to now"
+// @Success 200 {object} map[string]httpdto.WindowStatsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/stats [get]
+func (h *CryptoHandler) GetWindowStats(c *gin.Context) {
+	log := h.logger.With("handler", "GetWindowStats")
+
+	var req httpdto.WindowStatsRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+
+	from := time.Unix(req.From, 0).UTC()
+	to := time.Unix(req.To, 0).UTC()
+	st, err := h.svc.GetWindowStats(c.Request.Context(), req.Symbol, req.Quote, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrInvalidQuote):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked),
+			errors.Is(err, domain.ErrPriceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetWindowStats failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	resp := httpdto.WindowStatsResponse{
+		Symbol:             req.Symbol,
+		Quote:              quoteOrDefault(req.Quote),
+		From:               req.From,
+		To:                 req.To,
+		Count:              st.Count,
+		FirstUnixTs:        st.First.Unix(),
+		LastUnixTs:         st.Last.Unix(),
+		Open:               st.Open,
+		Close:              st.Close,
+		Change:             st.Change,
+		ChangePercent:      st.ChangePercent,
+		Min:                st.Min,
+		MinUnixTs:          st.MinAt.Unix(),
+		Max:                st.Max,
+		MaxUnixTs:          st.MaxAt.Unix(),
+		Mean:               st.Mean,
+		Volatility:         st.Volatility,
+		MaxDrawdownPercent: st.MaxDrawdown,
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// GetCoverage godoc
+// @Summary Get data coverage
+// @Description Compares stored prices within [from, to] with the fetch interval and lists the gaps, the number of missing fetches, the covered share of the window and the longest gap. The window ends at the current time at the latest
+// @Tags Price
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param quote query string false "Quote currency, defaults to USD"
+// @Param from query int true "Window start (Unix seconds)"
+// @Param to query int false "Window end (Unix seconds), defaults to now"
+// @Success 200 {object} map[string]httpdto.CoverageResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/coverage [get]
+func (h *CryptoHandler) GetCoverage(c *gin.Context) {
+	log := h.logger.With("handler", "GetCoverage")
+
+	var req httpdto.CoverageRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+
+	from := time.Unix(req.From, 0).UTC()
+	to := time.Unix(req.To, 0).UTC()
+	cov, err := h.svc.GetCoverage(c.Request.Context(), req.Symbol, req.Quote, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrInvalidQuote):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetCoverage failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toCoverageResponse(req.Symbol, quoteOrDefault(req.Quote), cov)})
+}
+
+// StartBackfill godoc
+// @Summary Start a historical backfill
+// @Description Queues an import of provider history for a tracked currency over [from, to]. The job runs in the background and resumes after restarts, poll GET /backfill/{id} for progress
+// @Tags Backfill
+// @Accept json
+// @Produce json
+// @Param input body httpdto.BackfillRequest true "Backfill range"
+// @Success 202 {object} map[string]httpdto.BackfillJobResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /backfill [post]
+func (h *CryptoHandler) StartBackfill(c *gin.Context) {
+	log := h.logger.With("handler", "StartBackfill")
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1024)
+
+	var req httpdto.BackfillRequest
+	if !BindAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+
+	from := time.Unix(req.From, 0).UTC()
+	to := time.Unix(req.To, 0).UTC()
+	job, err := h.svc.StartBackfill(c.Request.Context(), req.Symbol, req.Quote, from, to)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrInvalidQuote):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrBackfillUnsupported):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.StartBackfill failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": toBackfillJobResponse(job)})
+}
+
+// GetBackfillJob godoc
+// @Summary Get backfill progress
+// @Description Returns the status, cursor and number of stored prices of a backfill job
+// @Tags Backfill
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} map[string]httpdto.BackfillJobResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /backfill/{id} [get]
+func (h *CryptoHandler) GetBackfillJob(c *gin.Context) {
+	log := h.logger.With("handler", "GetBackfillJob")
+
+	var req httpdto.BackfillJobRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	job, err := h.svc.GetBackfillJob(c.Request.Context(), uuid.MustParse(req.ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBackfillNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetBackfillJob failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toBackfillJobResponse(job)})
+}
+
+// ResumeBackfill godoc
+// @Summary Resume a failed backfill
+// @Description Requeues a failed backfill job, it continues from its cursor. Finished jobs are returned unchanged
+// @Tags Backfill
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 202 {object} map[string]httpdto.BackfillJobResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /backfill/{id}/resume [post]
+func (h *CryptoHandler) ResumeBackfill(c *gin.Context) {
+	log := h.logger.With("handler", "ResumeBackfill")
+
+	var req httpdto.BackfillJobRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	job, err := h.svc.ResumeBackfill(c.Request.Context(), uuid.MustParse(req.ID))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrBackfillNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrBackfillActive):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.ResumeBackfill failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": toBackfillJobResponse(job)})
+}
+
+// ImportPrices godoc
+// @Summary Import historical prices
+// @Description Streams a multipart CSV or NDJSON file of (symbol, timestamp, price[, quote]) rows into storage in batches. Timestamps are Unix seconds or RFC 3339, symbols must be tracked. Invalid rows are rejected and listed in the report, on_conflict decides what happens to prices already stored for the same timestamp. Rows in a month whose partition was detached stop the import with 409
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or NDJSON file"
+// @Param format query string false "File format, guessed from the file name when empty" Enums(csv, ndjson)
+// @Param on_conflict query string false "Conflict policy, defaults to skip" Enums(skip, overwrite, fail)
+// @Success 200 {object} map[string]httpdto.ImportReportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Failure 413 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /import [post]
+func (h *CryptoHandler) ImportPrices(c *gin.Context) {
+	log := h.logger.With("handler", "ImportPrices")
+
+	// Large files take longer than the server read and write timeouts allow
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetReadDeadline(time.Time{}); err != nil {
+		log.Warn("clear read deadline failed", "error", err)
+	}
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn("clear write deadline failed", "error", err)
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, importBodyLimit)
+
+	var req httpdto.ImportRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+
+	// Read the file part as a stream, large imports never sit in memory or on disk
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart form expected"})
+		return
+	}
+	var part *multipart.Part
+	for {
+		part, err = mr.NextPart()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "missing file part"})
+			return
+		}
+		if part.FormName() == "file" {
+			break
+		}
+	}
+	defer part.Close()
+
+	opts := app.ImportOptions{OnConflict: domain.ConflictPolicy(req.OnConflict)}
+	if req.Format != "" {
+		opts.Format, _ = app.ParseImportFormat(req.Format)
+	} else if f, ok := app.ImportFormatFromName(part.FileName()); ok {
+		opts.Format = f
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format is required when the file name has no .csv or .ndjson extension"})
+		return
+	}
+
+	report, err := h.svc.ImportPrices(c.Request.Context(), part, opts)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, domain.ErrDuplicatePrice),
+			errors.Is(err, domain.ErrPriceMonthArchived):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "data": toImportReportResponse(report)})
+		case errors.As(err, &maxErr):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		case errors.Is(err, app.ErrInvalidImportFormat),
+			errors.Is(err, domain.ErrInvalidConflictPolicy):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.ImportPrices failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": toImportReportResponse(report)})
+}
+
+// ExportPrices godoc
+// @Summary Export price history
+// @Description Streams the stored raw prices of one or more symbols over [from, to] as CSV, NDJSON or Parquet. Rows are written as they are read from the database, symbol by symbol and ordered by timestamp, and carry the symbol and currency ID. gzip compresses the whole file for CSV and NDJSON and the pages for Parquet
+// @Tags Export
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Produce application/gzip
+// @Param symbols query string true "Comma separated symbols" example(BTC,ETH)
+// @Param quote query string false "Quote currency, defaults to USD"
+// @Param from query int true "Range start (Unix seconds)"
+// @Param to query int false "Range end (Unix seconds), defaults to now"
+// @Param format query string false "File format, defaults to csv" Enums(csv, ndjson, parquet)
+// @Param gzip query bool false "Compress the output"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /export [get]
+func (h *CryptoHandler) ExportPrices(c *gin.Context) {
+	log := h.logger.With("handler", "ExportPrices")
+
+	var req httpdto.ExportRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+	format := app.ExportCSV
+	if req.Format != "" {
+		format, _ = app.ParseExportFormat(req.Format)
+	}
+
+	exp, err := h.svc.ExportPrices(c.Request.Context(), app.ExportOptions{
+		Symbols: strings.Split(req.Symbols, ","),
+		Quote:   req.Quote,
+		From:    time.Unix(req.From, 0).UTC(),
+		To:      time.Unix(req.To, 0).UTC(),
+		Format:  format,
+		Gzip:    req.Gzip,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrInvalidQuote),
+			errors.Is(err, domain.ErrInvalidSymbol),
+			errors.Is(err, app.ErrInvalidExportFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.ExportPrices failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	// Long ranges take longer than the server write timeout allows
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn("clear write deadline failed", "error", err)
+	}
+	c.Header("Content-Type", format.ContentType(req.Gzip))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="prices%s"`, format.Extension(req.Gzip)))
+	c.Status(http.StatusOK)
+
+	// Headers are gone once rows are written, a failure can only cut the stream short
+	rows, err := exp.Write(c.Request.Context(), c.Writer)
+	if err != nil {
+		if c.Request.Context().Err() == nil {
+			log.Error("export stream failed", "rows", rows, "error", err)
+		}
+		c.Abort()
+		return
+	}
+	log.Debug("export done", "rows", rows)
+}
+
+// GetPairPrice godoc
+// @Summary Get a cross rate
+// @Description Prices base in units of quote at the given time, derived from both USD series. alignment_error_seconds is the gap between the two observations
+// @Tags Pair
+// @Produce json
+// @Param base path string true "Base symbol or provider coin ID"
+// @Param quote path string true "Quote symbol or provider coin ID"
+// @Param at query int true "Unix Timestamp"
+// @Param mode query string false "Lookup mode" Enums(nearest, before, after, interpolate)
+// @Param max_distance query int false "Tolerance in seconds, defaults to the server setting"
+// @Success 200 {object} map[string]httpdto.PairPriceResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pair/{base}/{quote}/price [get]
+func (h *CryptoHandler) GetPairPrice(c *gin.Context) {
+	log := h.logger.With("handler", "GetPairPrice")
+
+	var req httpdto.PairPriceRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if !validTimestamp(req.At) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "timestamp out of range"})
+		return
+	}
+
+	mode, err := domain.ParseLookupMode(req.Mode)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := app.LookupOptions{
+		Mode:        mode,
+		MaxDistance: time.Duration(req.MaxDistance) * time.Second,
+	}
+	at := time.Unix(req.At, 0).UTC()
+	rate, err := h.svc.GetCrossRate(c.Request.Context(), req.Base, req.Quote, at, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidLookupMode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked),
+			errors.Is(err, domain.ErrPriceNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrStalePrice),
+			errors.Is(err, domain.ErrZeroQuotePrice):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetCrossRate failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	resp := httpdto.PairPriceResponse{
+		Base:                  req.Base,
+		Quote:                 req.Quote,
+		RequestedUnixTs:       req.At,
+		Rate:                  rate.Rate,
+		AlignmentErrorSeconds: int64(rate.AlignmentError / time.Second),
+		BasePrice:             toPriceQueryResponse(req.Base, rate.Base),
+		QuotePrice:            toPriceQueryResponse(req.Quote, rate.Quote),
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// GetPairHistory godoc
+// @Summary Get cross rate history
+// @Description Pairs every base snapshot within [from, to] with the nearest quote snapshot. Points without a quote snapshot within the lookup tolerance are left out
+// @Tags Pair
+// @Produce json
+// @Param base path string true "Base symbol or provider coin ID"
+// @Param quote path string true "Quote symbol or provider coin ID"
+// @Param from query int true "Window start (Unix seconds)"
+// @Param to query int false "Window end (Unix seconds), defaults to now"
+// @Param limit query int false "Page size of the base series (default 500, max 5000)"
+// @Param cursor query string false "Cursor from the previous page"
+// @Success 200 {object} map[string]httpdto.PairHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /pair/{base}/{quote}/history [get]
+func (h *CryptoHandler) GetPairHistory(c *gin.Context) {
+	log := h.logger.With("handler", "GetPairHistory")
+
+	var req httpdto.PairHistoryRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+
+	from := time.Unix(req.From, 0).UTC()
+	to := time.Unix(req.To, 0).UTC()
+	page, err := h.svc.GetCrossRateHistory(c.Request.Context(), req.Base, req.Quote, from, to, req.Cursor, req.Limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, app.ErrInvalidCursor):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetCrossRateHistory failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	rates := make([]httpdto.PairPointResponse, len(page.Points))
+	for i, pt := range page.Points {
+		rates[i] = httpdto.PairPointResponse{
+			Timestamp:             pt.Timestamp.Unix(),
+			Rate:                  pt.Rate,
+			BasePrice:             pt.BasePrice,
+			QuotePrice:            pt.QuotePrice,
+			AlignmentErrorSeconds: int64(pt.AlignmentError / time.Second),
+		}
+	}
+
+	resp := httpdto.PairHistoryResponse{
+		Base:       req.Base,
+		Quote:      req.Quote,
+		From:       req.From,
+		To:         req.To,
+		Rates:      rates,
+		NextCursor: page.NextCursor,
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// GetIndicator godoc
+// @Summary Get a technical indicator
+// @Description Computes sma, ema, rsi, bollinger or macd over stored prices resampled to the interval. Unset parameters take the usual defaults (period 20, rsi 14, k 2, macd 12/26/9)
+// @Tags Price
+// @Produce json
+// @Param symbol path string true "Currency symbol or provider coin ID"
+// @Param quote query string false "Quote currency, defaults to USD"
+// @Param name path string true "Indicator" Enums(sma, ema, rsi, bollinger, macd)
+// @Param interval query string true "Resampling interval" Enums(1m, 5m, 1h, 1d)
+// @Param from query int true "Window start (Unix seconds)"
+// @Param to query int false "Window end (Unix seconds), defaults to now"
+// @Param period query int false "Lookback period (sma, ema, rsi, bollinger)"
+// @Param k query number false "Band width in standard deviations (bollinger)"
+// @Param fast query int false "Fast EMA period (macd)"
+// @Param slow query int false "Slow EMA period (macd)"
+// @Param signal query int false "Signal EMA period (macd)"
+// @Success 200 {object} map[string]httpdto.IndicatorResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /currency/{symbol}/indicators/{name} [get]
+func (h *CryptoHandler) GetIndicator(c *gin.Context) {
+	log := h.logger.With("handler", "GetIndicator")
+
+	var req httpdto.IndicatorRequest
+	if !BindQueryAndValidate(c, h.validator, &req) {
+		return
+	}
+	if req.To == 0 {
+		req.To = time.Now().Unix()
+	}
+
+	bucket, err := domain.ParseCandleInterval(req.Interval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	params := indicators.Params{
+		Period: req.Period,
+		K:      req.K,
+		Fast:   req.Fast,
+		Slow:   req.Slow,
+		Signal: req.Signal,
+	}
+	from := time.Unix(req.From, 0).UTC()
+	to := time.Unix(req.To, 0).UTC()
+	series, err := h.svc.GetIndicator(c.Request.Context(), req.Symbol, req.Quote, req.Name, params, from, to, bucket)
+	if err != nil {
+		switch {
+		case errors.Is(err, indicators.ErrUnknownIndicator),
+			errors.Is(err, indicators.ErrInvalidParams),
+			errors.Is(err, domain.ErrInvalidTimeRange),
+			errors.Is(err, domain.ErrInvalidQuote),
+			errors.Is(err, domain.ErrRangeTooLarge):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrNotTracked):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, domain.ErrAmbiguousCurrency):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			log.Error("service.GetIndicator failed", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		}
+		return
+	}
+
+	points := make([]httpdto.IndicatorPointResponse, len(series.Points))
+	for i, pt := range series.Points {
+		values := make(map[string]float64, len(series.Lines))
+		for j, line := range series.Lines {
+			values[line] = pt.Values[j]
+		}
+		points[i] = httpdto.IndicatorPointResponse{
+			Timestamp: pt.Time.Unix(),
+			Values:    values,
+		}
+	}
+
+	resp := httpdto.IndicatorResponse{
+		Symbol:    req.Symbol,
+		Quote:     quoteOrDefault(req.Quote),
+		Indicator: series.Name,
+		Interval:  req.Interval,
+		Params: httpdto.IndicatorParamsResponse{
+			Period: series.Params.Period,
+			K:      series.Params.K,
+			Fast:   series.Params.Fast,
+			Slow:   series.Params.Slow,
+			Signal: series.Params.Signal,
+		},
+		Lines:  series.Lines,
+		Points: points,
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// Body limits of batch endpoints and import uploads, single item endpoints keep 1 KB
+const (
+	batchBodyLimit  = 1 << 20
+	importBodyLimit = app.MaxImportSize
+)
+
+// Rejects negative timestamps and ones further than an hour in the future
+func validTimestamp(ts int64) bool {
+	return ts >= 0 && ts <= time.Now().Unix()+3600
+}
+
+func quoteOrDefault(quote string) string {
+	if quote == "" {
+		return domain.DefaultQuote
+	}
+	return quote
+}
+
+func toPricePointResponse(snap *domain.PriceSnapshot) httpdto.PricePointResponse {
+	return httpdto.PricePointResponse{
+		Timestamp:         snap.Timestamp.Unix(),
+		Price:             snap.Price,
+		Volume24h:         snap.Market.Volume24h,
+		MarketCap:         snap.Market.MarketCap,
+		PercentChange1h:   snap.Market.PercentChange1h,
+		PercentChange24h:  snap.Market.PercentChange24h,
+		PercentChange7d:   snap.Market.PercentChange7d,
+		CirculatingSupply: snap.Market.CirculatingSupply,
+		Rank:              snap.Market.Rank,
+		ResolutionSeconds: int64(snap.Resolution / time.Second),
+	}
+}
+
+func toCoverageResponse(symbol, quote string, cov *domain.Coverage) httpdto.CoverageResponse {
+	gaps := make([]httpdto.CoverageGapResponse, len(cov.Gaps))
+	for i, g := range cov.Gaps {
+		gaps[i] = httpdto.CoverageGapResponse{
+			From:            g.From.Unix(),
+			To:              g.To.Unix(),
+			DurationSeconds: int64(g.Duration() / time.Second),
+			Missing:         g.Missing,
+		}
+	}
+	return httpdto.CoverageResponse{
+		Symbol:            symbol,
+		Quote:             quote,
+		From:              cov.From.Unix(),
+		To:                cov.To.Unix(),
+		IntervalSeconds:   int64(cov.Interval / time.Second),
+		Count:             cov.Count,
+		MissingIntervals:  cov.Missing,
+		CoveragePercent:   cov.Percent,
+		LongestGapSeconds: int64(cov.LongestGap / time.Second),
+		Gaps:              gaps,
+	}
+}
+
+func toStorageModeResponse(cur *domain.Currency) httpdto.StorageModeResponse {
+	resp := httpdto.StorageModeResponse{Symbol: cur.Symbol, Mode: "all"}
+	if band := cur.Deadband; band != nil {
+		resp.Mode = "deadband"
+		resp.ThresholdPercent = &band.ThresholdPercent
+		resp.HeartbeatSeconds = int64(band.Heartbeat / time.Second)
+	}
+	return resp
+}
+
+func toCurrencyEntryResponse(l app.CurrencyListing) httpdto.CurrencyEntryResponse {
+	cur := l.Currency
+	resp := httpdto.CurrencyEntryResponse{
+		ID:              cur.ID.String(),
+		Symbol:          cur.Symbol,
+		ProviderID:      cur.ProviderID,
+		Status:          string(cur.Status),
+		IntervalSeconds: int64(cur.Schedule.Interval / time.Second),
+		Priority:        cur.Schedule.Priority,
+		CreatedAt:       cur.CreatedAt.Format(time.RFC3339),
+	}
+	if l.Latest != nil {
+		resp.LatestPrice = &l.Latest.Price
+		resp.LatestTimestamp = l.Latest.Timestamp.Unix()
+	}
+	return resp
+}
+
+func toCoinResponse(coin app.Coin) httpdto.CoinResponse {
+	return httpdto.CoinResponse{
+		ProviderID: coin.ID,
+		Symbol:     coin.Symbol,
+		Name:       coin.Name,
+		Rank:       coin.Rank,
+		Type:       coin.Type,
+	}
+}
+
+func toPurgeCountsResponse(counts domain.PurgeCounts) httpdto.PurgeCountsResponse {
+	return httpdto.PurgeCountsResponse{
+		Prices:       counts.Prices,
+		Rollups:      counts.Rollups,
+		BackfillJobs: counts.BackfillJobs,
+	}
+}
+
+func toBackfillJobResponse(job *domain.BackfillJob) httpdto.BackfillJobResponse {
+	return httpdto.BackfillJobResponse{
+		ID:              job.ID.String(),
+		Symbol:          job.Symbol,
+		Quote:           job.Quote,
+		From:            job.Start.Unix(),
+		To:              job.End.Unix(),
+		IntervalSeconds: int64(job.Interval / time.Second),
+		Cursor:          job.Cursor.Unix(),
+		Status:          string(job.Status),
+		ProgressPercent: job.Progress(),
+		Inserted:        job.Inserted,
+		Error:           job.Error,
+		CreatedAt:       job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       job.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toImportReportResponse(report *app.ImportReport) httpdto.ImportReportResponse {
+	resp := httpdto.ImportReportResponse{
+		Rows:     report.Rows,
+		Accepted: report.Accepted,
+		Rejected: report.Rejected,
+		Written:  report.Written,
+		Skipped:  report.Skipped,
+	}
+	if !report.From.IsZero() {
+		resp.From = report.From.Unix()
+		resp.To = report.To.Unix()
+	}
+	for _, e := range report.Errors {
+		resp.Errors = append(resp.Errors, httpdto.ImportRowErrorResponse{Line: e.Line, Reason: e.Reason})
+	}
+	return resp
+}
+
+func toPriceQueryResponse(symbol string, lookup *domain.PriceLookup) httpdto.PriceQueryResponse {
+	sources := make([]httpdto.PricePointResponse, len(lookup.Sources))
+	for i, snap := range lookup.Sources {
+		sources[i] = toPricePointResponse(snap)
+	}
+
+	quote := domain.DefaultQuote
+	if len(lookup.Sources) > 0 {
+		quote = lookup.Sources[0].Quote
+	}
+
+	return httpdto.PriceQueryResponse{
+		Symbol:          symbol,
+		Quote:           quote,
+		Mode:            string(lookup.Mode),
+		RequestedUnixTs: lookup.At.Unix(),
+		ReturnedUnixTs:  lookup.ObservedAt().Unix(),
+		Price:           lookup.Price,
+		Sources:         sources,
+		DistanceSeconds: int64(lookup.Distance / time.Second),
+		Stale:           lookup.Stale,
+	}
+}
+
+// Registers the tags the request DTOs use beyond the built-in ones:
+// currency_ref accepts a symbol or a provider coin ID
+func RegisterValidations(v *validator.Validate) error {
+	return v.RegisterValidation("currency_ref", func(fl validator.FieldLevel) bool {
+		return domain.ValidCurrencyRef(fl.Field().String())
+	})
+}
+
+// On error writes the HTTP 400 and returns false
+func BindAndValidate(c *gin.Context, validate *validator.Validate, obj interface{}) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON"})
+		return false
+	}
+	if err := validate.Struct(obj); err != nil {
+		// pick the first field error
+		ve := err.(validator.ValidationErrors)[0]
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": ve.Field() + " failed " + ve.Tag(),
+		})
+		return false
+	}
+	return true
+}
+
+// Binds path and query params, on error writes the HTTP 400 and returns false
+func BindQueryAndValidate(c *gin.Context, validate *validator.Validate, obj interface{}) bool {
+	if err := c.ShouldBindUri(obj); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path params"})
+		return false
+	}
+	if err := c.ShouldBindQuery(obj); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query params"})
+		return false
+	}
+	if err := validate.Struct(obj); err != nil {
 		ve := err.(validator.ValidationErrors)[0]
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": ve.Field() + " failed " + ve.Tag(),
@@ -20,8 +20,39 @@ func SetupRouter(h *CryptoHandler) *gin.Engine {
 		currency.POST("/add", h.AddCurrency)
 		currency.POST("/remove", h.RemoveCurrency)
 		currency.POST("/price", h.GetPrice)
+		currency.POST("/prices", h.GetPrices)
+		currency.GET("/:symbol/history", h.GetPriceHistory)
+		currency.GET("/:symbol/candles", h.GetCandles)
+		currency.GET("/:symbol/stats", h.GetWindowStats)
+		currency.GET("/:symbol/coverage", h.GetCoverage)
+		currency.GET("/:symbol/indicators/:name", h.GetIndicator)
+		currency.PUT("/:symbol/deadband", h.SetDeadband)
+		currency.DELETE("/:symbol/deadband", h.ClearDeadband)
+		currency.PUT("/:symbol/status", h.SetCurrencyStatus)
+		currency.PUT("/:symbol/schedule", h.SetFetchSchedule)
+		currency.POST("/:symbol/purge/token", h.RequestPurge)
+		currency.POST("/:symbol/purge", h.PurgeCurrency)
 	}
 
+	r.GET("/currencies", h.ListCurrencies)
+	r.GET("/coins/search", h.SearchCoins)
+
+	pair := r.Group("/pair")
+	{
+		pair.GET("/:base/:quote/price", h.GetPairPrice)
+		pair.GET("/:base/:quote/history", h.GetPairHistory)
+	}
+
+	backfill := r.Group("/backfill")
+	{
+		backfill.POST("", h.StartBackfill)
+		backfill.GET("/:id", h.GetBackfillJob)
+		backfill.POST("/:id/resume", h.ResumeBackfill)
+	}
+
+	r.POST("/import", h.ImportPrices)
+	r.GET("/export", h.ExportPrices)
+
 	// Health check endpoint
 	r.GET("/healthz", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
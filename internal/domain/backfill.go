@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BackfillStatus string
+
+const (
+	BackfillPending BackfillStatus = "pending"
+	BackfillRunning BackfillStatus = "running"
+	BackfillDone    BackfillStatus = "done"
+	BackfillFailed  BackfillStatus = "failed"
+)
+
+// Import of historical prices of one currency over [Start, End].
+// Cursor is persisted after every page, an interrupted job continues from there
+type BackfillJob struct {
+	ID         uuid.UUID
+	CurrencyID uuid.UUID
+	Symbol     string
+	ProviderID string
+	Quote      string
+	Start      time.Time
+	End        time.Time
+	Interval   time.Duration // spacing of the requested ticks
+	Cursor     time.Time     // next timestamp to fetch
+	Status     BackfillStatus
+	Inserted   int64  // snapshots written so far, duplicates excluded
+	Error      string // last failure, empty otherwise
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func NewBackfillJob(cur *Currency, quote string, start, end time.Time, interval time.Duration) (*BackfillJob, error) {
+	quote, err := NormalizeQuote(quote)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	now := time.Now().UTC()
+	if end.After(now) {
+		end = now
+	}
+	if !start.Before(end) {
+		return nil, ErrInvalidTimeRange
+	}
+	return &BackfillJob{
+		ID:         uuid.New(),
+		CurrencyID: cur.ID,
+		Symbol:     cur.Symbol,
+		ProviderID: cur.ProviderID,
+		Quote:      quote,
+		Start:      start.UTC(),
+		End:        end.UTC(),
+		Interval:   interval,
+		Cursor:     start.UTC(),
+		Status:     BackfillPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// Share of the range already fetched, 0-100
+func (j *BackfillJob) Progress() float64 {
+	if j.Status == BackfillDone {
+		return 100
+	}
+	total := j.End.Sub(j.Start)
+	if total <= 0 {
+		return 0
+	}
+	return min(100, 100*float64(j.Cursor.Sub(j.Start))/float64(total))
+}
+
+func (j *BackfillJob) Finished() bool {
+	return j.Status == BackfillDone || j.Status == BackfillFailed
+}
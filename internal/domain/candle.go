@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Supported candle bucket sizes
+var candleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// OHLC aggregate of the snapshots falling into one bucket
+type Candle struct {
+	Start   time.Time // bucket start, aligned to Unix epoch
+	Open    decimal.Decimal
+	High    decimal.Decimal
+	Low     decimal.Decimal
+	Close   decimal.Decimal
+	CloseAt time.Time // timestamp of the last snapshot in the bucket
+	Count   int       // number of snapshots in the bucket
+}
+
+// Close price of a stored rollup as a snapshot observed at CloseAt
+func (c *Candle) CloseSnapshot(curID uuid.UUID, quote string, resolution time.Duration) *PriceSnapshot {
+	return &PriceSnapshot{
+		CurrencyID: curID,
+		Quote:      quote,
+		Timestamp:  c.CloseAt,
+		Price:      c.Close,
+		Resolution: resolution,
+	}
+}
+
+func ParseCandleInterval(raw string) (time.Duration, error) {
+	d, ok := candleIntervals[strings.ToLower(strings.TrimSpace(raw))]
+	if !ok {
+		return 0, ErrInvalidInterval
+	}
+	return d, nil
+}
+
+// Buckets snapshots ordered by timestamp into candles.
+// Mirrors the SQL aggregation so every repository backend returns the same result
+func BuildCandles(snaps []*PriceSnapshot, bucket time.Duration) []*Candle {
+	size := int64(bucket / time.Second)
+	if size <= 0 {
+		return nil
+	}
+
+	var candles []*Candle
+	var cur *Candle
+	for _, snap := range snaps {
+		start := snap.Timestamp.Unix() / size * size
+		if cur == nil || cur.Start.Unix() != start {
+			cur = &Candle{
+				Start: time.Unix(start, 0).UTC(),
+				Open:  snap.Price,
+				High:  snap.Price,
+				Low:   snap.Price,
+			}
+			candles = append(candles, cur)
+		}
+		cur.High = decimal.Max(cur.High, snap.Price)
+		cur.Low = decimal.Min(cur.Low, snap.Price)
+		cur.Close = snap.Price
+		cur.CloseAt = snap.Timestamp
+		cur.Count++
+	}
+	return candles
+}
+
+// Aggregates epoch aligned candles ordered by start, e.g. rollups, into `bucket` candles:
+// first open, last close, the extremes and the total count. Candles finer than `bucket`
+// are merged, coarser ones are kept as they are
+func MergeCandles(parts []*Candle, bucket time.Duration) []*Candle {
+	size := int64(bucket / time.Second)
+	if size <= 0 {
+		return nil
+	}
+
+	var candles []*Candle
+	var cur *Candle
+	for _, part := range parts {
+		start := part.Start.Unix() / size * size
+		if cur == nil || cur.Start.Unix() != start {
+			merged := *part
+			merged.Start = time.Unix(start, 0).UTC()
+			cur = &merged
+			candles = append(candles, cur)
+			continue
+		}
+		cur.High = decimal.Max(cur.High, part.High)
+		cur.Low = decimal.Min(cur.Low, part.Low)
+		cur.Close = part.Close
+		cur.CloseAt = part.CloseAt
+		cur.Count += part.Count
+	}
+	return candles
+}
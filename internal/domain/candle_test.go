@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func candle(start int64, open, high, low, close string, closeAt int64, count int) *Candle {
+	return &Candle{
+		Start:   time.Unix(start, 0).UTC(),
+		Open:    decimal.RequireFromString(open),
+		High:    decimal.RequireFromString(high),
+		Low:     decimal.RequireFromString(low),
+		Close:   decimal.RequireFromString(close),
+		CloseAt: time.Unix(closeAt, 0).UTC(),
+		Count:   count,
+	}
+}
+
+func assertCandles(t *testing.T, got, want []*Candle) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d candles, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if !g.Start.Equal(w.Start) || !g.Open.Equal(w.Open) || !g.High.Equal(w.High) || !g.Low.Equal(w.Low) ||
+			!g.Close.Equal(w.Close) || !g.CloseAt.Equal(w.CloseAt) || g.Count != w.Count {
+			t.Errorf("candle %d = %+v, want %+v", i, *g, *w)
+		}
+	}
+}
+
+func TestBuildCandles(t *testing.T) {
+	snap := func(ts int64, price string) *PriceSnapshot {
+		return &PriceSnapshot{Timestamp: time.Unix(ts, 0).UTC(), Price: decimal.RequireFromString(price)}
+	}
+	snaps := []*PriceSnapshot{
+		snap(3600, "10"), snap(3700, "12"), snap(4000, "9"), snap(7199, "11"), // one full hour
+		snap(10800, "20"), // the next hour is empty
+	}
+	assertCandles(t, BuildCandles(snaps, time.Hour), []*Candle{
+		candle(3600, "10", "12", "9", "11", 7199, 4),
+		candle(10800, "20", "20", "20", "20", 10800, 1),
+	})
+	if got := BuildCandles(snaps, 0); got != nil {
+		t.Errorf("zero bucket: got %d candles", len(got))
+	}
+}
+
+func TestMergeCandles(t *testing.T) {
+	tests := []struct {
+		name   string
+		parts  []*Candle
+		bucket time.Duration
+		want   []*Candle
+	}{
+		{
+			name: "rollups into coarser buckets",
+			parts: []*Candle{
+				candle(0, "10", "15", "9", "14", 290, 5),
+				candle(300, "14", "14", "8", "8", 590, 5),
+				candle(3600, "8", "9", "7", "9", 3890, 4),
+			},
+			bucket: time.Hour,
+			want: []*Candle{
+				candle(0, "10", "15", "8", "8", 590, 10),
+				candle(3600, "8", "9", "7", "9", 3890, 4),
+			},
+		},
+		{
+			// Coarse rollups stay as they are under a finer bucket
+			name:   "finer bucket",
+			parts:  []*Candle{candle(86400, "1", "3", "1", "2", 172000, 288)},
+			bucket: time.Minute,
+			want:   []*Candle{candle(86400, "1", "3", "1", "2", 172000, 288)},
+		},
+		{
+			// A rollup ending where raw candles of the same bucket continue
+			name: "bucket split across tiers",
+			parts: []*Candle{
+				candle(3600, "5", "6", "4", "6", 5390, 6),
+				candle(5400, "6", "7", "5", "5", 7190, 60),
+			},
+			bucket: time.Hour,
+			want:   []*Candle{candle(3600, "5", "7", "4", "5", 7190, 66)},
+		},
+		{name: "empty", bucket: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertCandles(t, MergeCandles(tt.parts, tt.bucket), tt.want)
+		})
+	}
+}
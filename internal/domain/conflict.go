@@ -0,0 +1,24 @@
+package domain
+
+import "strings"
+
+// What a bulk write does with a price already stored for the same currency, quote and timestamp
+type ConflictPolicy string
+
+const (
+	ConflictSkip      ConflictPolicy = "skip"      // keep the stored price
+	ConflictOverwrite ConflictPolicy = "overwrite" // replace it with the new one
+	ConflictFail      ConflictPolicy = "fail"      // abort with ErrDuplicatePrice
+)
+
+// Empty input defaults to ConflictSkip
+func ParseConflictPolicy(raw string) (ConflictPolicy, error) {
+	switch p := ConflictPolicy(strings.ToLower(strings.TrimSpace(raw))); p {
+	case "":
+		return ConflictSkip, nil
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+		return p, nil
+	default:
+		return "", ErrInvalidConflictPolicy
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// Stretch of a window without stored prices
+type CoverageGap struct {
+	From    time.Time // last price before the gap, or the window start
+	To      time.Time // first price after the gap, or the window end
+	Missing int       // fetches expected in between that never made it
+}
+
+func (g CoverageGap) Duration() time.Duration {
+	return g.To.Sub(g.From)
+}
+
+// How completely stored prices follow the expected fetch cadence over a window
+type Coverage struct {
+	From       time.Time
+	To         time.Time
+	Interval   time.Duration // expected cadence
+	Count      int           // stored prices in the window
+	Missing    int           // expected fetches without a stored price
+	Percent    float64       // share of the window covered by the cadence, 0-100
+	LongestGap time.Duration
+	Gaps       []CoverageGap // ordered by time
+}
+
+// Compares snapshots ordered by timestamp with the expected cadence.
+// Consecutive prices further apart than 1.5 intervals count as a gap, as do
+// the edges of the window. Rollup prices are expected once per bucket
+func ComputeCoverage(snaps []*PriceSnapshot, from, to time.Time, interval time.Duration) (*Coverage, error) {
+	acc, err := NewCoverageAccumulator(from, to, interval)
+	if err != nil {
+		return nil, err
+	}
+	for _, snap := range snaps {
+		acc.Add(snap)
+	}
+	return acc.Coverage(), nil
+}
+
+// Builds a Coverage one snapshot at a time, only the gaps found so far are kept.
+// Snapshots must be added in timestamp order
+type CoverageAccumulator struct {
+	cov       Coverage
+	prev      *PriceSnapshot // last snapshot added
+	uncovered time.Duration
+}
+
+func NewCoverageAccumulator(from, to time.Time, interval time.Duration) (*CoverageAccumulator, error) {
+	if to.Before(from) {
+		return nil, ErrInvalidTimeRange
+	}
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return &CoverageAccumulator{cov: Coverage{From: from, To: to, Interval: interval}}, nil
+}
+
+func (a *CoverageAccumulator) Add(snap *PriceSnapshot) {
+	a.cov.Count++
+	if a.prev == nil {
+		if d := snap.Timestamp.Sub(a.cov.From); d > cadence(a.cov.Interval, snap) {
+			a.addGap(CoverageGap{From: a.cov.From, To: snap.Timestamp, Missing: int(d / cadence(a.cov.Interval, snap))}, d)
+		}
+		a.prev = snap
+		return
+	}
+
+	prev := a.prev
+	a.prev = snap
+	step := max(cadence(a.cov.Interval, prev), cadence(a.cov.Interval, snap))
+	d := snap.Timestamp.Sub(prev.Timestamp)
+	if d*2 <= step*3 {
+		return
+	}
+	missing := int(math.Round(float64(d)/float64(step))) - 1
+	a.addGap(CoverageGap{From: prev.Timestamp, To: snap.Timestamp, Missing: missing}, d-step)
+}
+
+// Coverage of the window by the snapshots added so far, the accumulator can keep going
+func (a *CoverageAccumulator) Coverage() *Coverage {
+	end := *a
+	end.cov.Gaps = slices.Clone(a.cov.Gaps)
+	window := end.cov.To.Sub(end.cov.From)
+	if last := a.prev; last == nil {
+		end.addGap(CoverageGap{From: end.cov.From, To: end.cov.To, Missing: int(window / end.cov.Interval)}, window)
+	} else if d := end.cov.To.Sub(last.Timestamp); d > cadence(end.cov.Interval, last) {
+		end.addGap(CoverageGap{From: last.Timestamp, To: end.cov.To, Missing: int(d / cadence(end.cov.Interval, last))}, d)
+	}
+
+	cov := end.cov
+	cov.Percent = 100
+	if window > 0 {
+		cov.Percent = max(0, 100*(1-float64(end.uncovered)/float64(window)))
+	}
+	return &cov
+}
+
+func (a *CoverageAccumulator) addGap(g CoverageGap, lost time.Duration) {
+	a.cov.Gaps = append(a.cov.Gaps, g)
+	a.cov.Missing += g.Missing
+	a.cov.LongestGap = max(a.cov.LongestGap, g.Duration())
+	a.uncovered += lost
+}
+
+// Expected distance to the neighbours of a snapshot
+func cadence(interval time.Duration, snap *PriceSnapshot) time.Duration {
+	return max(interval, snap.Resolution)
+}
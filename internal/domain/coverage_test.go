@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// Raw snapshots at t0 + the given offsets
+func snapsAt(offsets ...time.Duration) []*PriceSnapshot {
+	snaps := make([]*PriceSnapshot, len(offsets))
+	for i, off := range offsets {
+		snaps[i] = &PriceSnapshot{Timestamp: t0.Add(off)}
+	}
+	return snaps
+}
+
+// Offsets from `from` to `to` inclusive, `step` apart
+func every(step, from, to time.Duration) []time.Duration {
+	var offs []time.Duration
+	for off := from; off <= to; off += step {
+		offs = append(offs, off)
+	}
+	return offs
+}
+
+func TestComputeCoverage(t *testing.T) {
+	m := time.Minute
+	gap := func(from, to time.Duration, missing int) CoverageGap {
+		return CoverageGap{From: t0.Add(from), To: t0.Add(to), Missing: missing}
+	}
+	rollups := func(res time.Duration, offsets ...time.Duration) []*PriceSnapshot {
+		snaps := snapsAt(offsets...)
+		for _, s := range snaps {
+			s.Resolution = res
+		}
+		return snaps
+	}
+
+	tests := []struct {
+		name    string
+		snaps   []*PriceSnapshot
+		window  time.Duration // from t0
+		missing int
+		percent float64
+		longest time.Duration
+		gaps    []CoverageGap
+	}{
+		{
+			name:    "every fetch stored",
+			snaps:   snapsAt(every(m, 0, 10*m)...),
+			window:  10 * m,
+			percent: 100,
+		},
+		{
+			name:    "jitter below 1.5 intervals",
+			snaps:   snapsAt(0, 80*time.Second, 2*m, 3*m+30*time.Second, 4*m),
+			window:  4 * m,
+			percent: 100,
+		},
+		{
+			name:    "just over 1.5 intervals",
+			snaps:   snapsAt(0, 91*time.Second, 151*time.Second),
+			window:  151 * time.Second,
+			missing: 1,
+			percent: 100 * (1 - 31.0/151),
+			longest: 91 * time.Second,
+			gaps:    []CoverageGap{gap(0, 91*time.Second, 1)},
+		},
+		{
+			name:    "interior gap",
+			snaps:   snapsAt(0, m, 2*m, 6*m, 7*m, 8*m, 9*m, 10*m),
+			window:  10 * m,
+			missing: 3,
+			percent: 70,
+			longest: 4 * m,
+			gaps:    []CoverageGap{gap(2*m, 6*m, 3)},
+		},
+		{
+			name:    "window edges",
+			snaps:   snapsAt(3*m, 4*m, 5*m, 6*m),
+			window:  10 * m,
+			missing: 7,
+			percent: 30,
+			longest: 4 * m,
+			gaps:    []CoverageGap{gap(0, 3*m, 3), gap(6*m, 10*m, 4)},
+		},
+		{
+			name:    "edges within one interval",
+			snaps:   snapsAt(m, 2*m),
+			window:  3 * m,
+			percent: 100,
+		},
+		{
+			name:    "nothing stored",
+			window:  10 * m,
+			missing: 10,
+			percent: 0,
+			longest: 10 * m,
+			gaps:    []CoverageGap{gap(0, 10*m, 10)},
+		},
+		{
+			// Rollups are expected once per bucket, not once per fetch
+			name:    "rollups",
+			snaps:   rollups(5*m, 0, 5*m, 10*m, 15*m),
+			window:  15 * m,
+			percent: 100,
+		},
+		{
+			name:    "rollup gap",
+			snaps:   rollups(5*m, 0, 5*m, 20*m),
+			window:  20 * m,
+			missing: 2,
+			percent: 50,
+			longest: 15 * m,
+			gaps:    []CoverageGap{gap(5*m, 20*m, 2)},
+		},
+		{
+			// The coarser neighbour sets the expected step across a tier boundary
+			name:    "rollups followed by raw prices",
+			snaps:   append(rollups(5*m, 0, 5*m), snapsAt(10*m, 11*m)...),
+			window:  11 * m,
+			percent: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cov, err := ComputeCoverage(tt.snaps, t0, t0.Add(tt.window), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cov.Count != len(tt.snaps) || cov.Missing != tt.missing || cov.LongestGap != tt.longest {
+				t.Errorf("count %d missing %d longest %s, want %d, %d, %s",
+					cov.Count, cov.Missing, cov.LongestGap, len(tt.snaps), tt.missing, tt.longest)
+			}
+			if math.Abs(cov.Percent-tt.percent) > 1e-9 {
+				t.Errorf("percent %v, want %v", cov.Percent, tt.percent)
+			}
+			if len(cov.Gaps) != len(tt.gaps) {
+				t.Fatalf("gaps %+v, want %+v", cov.Gaps, tt.gaps)
+			}
+			for i, g := range tt.gaps {
+				if got := cov.Gaps[i]; !got.From.Equal(g.From) || !got.To.Equal(g.To) || got.Missing != g.Missing {
+					t.Errorf("gap %d = %+v, want %+v", i, got, g)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeCoverageInvalid(t *testing.T) {
+	if _, err := ComputeCoverage(nil, t0, t0.Add(-time.Second), time.Minute); !errors.Is(err, ErrInvalidTimeRange) {
+		t.Errorf("reversed window: error %v, want %v", err, ErrInvalidTimeRange)
+	}
+	if _, err := ComputeCoverage(nil, t0, t0.Add(time.Hour), 0); !errors.Is(err, ErrInvalidInterval) {
+		t.Errorf("zero interval: error %v, want %v", err, ErrInvalidInterval)
+	}
+}
+
+// A report taken midway adds the open tail as a gap without changing what follows
+func TestCoverageAccumulatorPartialReport(t *testing.T) {
+	m := time.Minute
+	acc, err := NewCoverageAccumulator(t0, t0.Add(10*m), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snaps := snapsAt(0, m, 2*m, 6*m, 7*m, 8*m, 9*m, 10*m)
+	for _, snap := range snaps[:3] {
+		acc.Add(snap)
+	}
+	if mid := acc.Coverage(); len(mid.Gaps) != 1 || mid.Gaps[0].From != t0.Add(2*m) || mid.Count != 3 {
+		t.Fatalf("midway report = %+v", mid)
+	}
+	for _, snap := range snaps[3:] {
+		acc.Add(snap)
+	}
+	got := acc.Coverage()
+	want, _ := ComputeCoverage(snaps, t0, t0.Add(10*m), m)
+	if got.Count != want.Count || got.Missing != want.Missing || len(got.Gaps) != 1 || got.Gaps[0] != want.Gaps[0] {
+		t.Errorf("coverage = %+v, want %+v", got, want)
+	}
+}
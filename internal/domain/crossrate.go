@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Price of Base expressed in Quote, derived from both USD lookups
+type CrossRate struct {
+	Base           *PriceLookup
+	Quote          *PriceLookup
+	Rate           decimal.Decimal
+	AlignmentError time.Duration // gap between the times the two prices were observed
+}
+
+// One point of a cross-rate series, timed by the base snapshot
+type CrossRatePoint struct {
+	Timestamp      time.Time
+	Rate           decimal.Decimal
+	BasePrice      decimal.Decimal
+	QuotePrice     decimal.Decimal
+	AlignmentError time.Duration // distance to the quote snapshot used
+}
+
+func NewCrossRate(base, quote *PriceLookup) (*CrossRate, error) {
+	if quote.Price.IsZero() {
+		return nil, ErrZeroQuotePrice
+	}
+	return &CrossRate{
+		Base:           base,
+		Quote:          quote,
+		Rate:           base.Price.DivRound(quote.Price, PriceScale),
+		AlignmentError: absDuration(base.ObservedAt().Sub(quote.ObservedAt())),
+	}, nil
+}
+
+// Pairs every base snapshot with the nearest quote snapshot, both ordered by timestamp.
+// LookupBefore pairs with the last quote snapshot at or before instead, for step function series.
+// Base snapshots without a quote within `tolerance` are skipped, 0 disables the check
+func AlignCrossRates(base, quote []*PriceSnapshot, tolerance time.Duration, mode LookupMode) []*CrossRatePoint {
+	var points []*CrossRatePoint
+	j := 0
+	for _, b := range base {
+		if mode == LookupBefore {
+			for j+1 < len(quote) && !quote[j+1].Timestamp.After(b.Timestamp) {
+				j++
+			}
+			if j < len(quote) && quote[j].Timestamp.After(b.Timestamp) {
+				continue // no quote yet
+			}
+		} else {
+			// Advance while the next quote snapshot is closer, the older one wins ties like LookupNearest
+			for j+1 < len(quote) &&
+				absDuration(quote[j+1].Timestamp.Sub(b.Timestamp)) < absDuration(quote[j].Timestamp.Sub(b.Timestamp)) {
+				j++
+			}
+		}
+		if j >= len(quote) || quote[j].Price.IsZero() {
+			continue
+		}
+
+		gap := absDuration(quote[j].Timestamp.Sub(b.Timestamp))
+		if tolerance > 0 && gap > tolerance {
+			continue
+		}
+		points = append(points, &CrossRatePoint{
+			Timestamp:      b.Timestamp,
+			Rate:           b.Price.DivRound(quote[j].Price, PriceScale),
+			BasePrice:      b.Price,
+			QuotePrice:     quote[j].Price,
+			AlignmentError: gap,
+		})
+	}
+	return points
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Snapshot at t0 + `min` minutes
+func at(min int, price string) *PriceSnapshot {
+	return &PriceSnapshot{Timestamp: t0.Add(time.Duration(min) * time.Minute), Price: decimal.RequireFromString(price)}
+}
+
+func TestAlignCrossRates(t *testing.T) {
+	type point struct {
+		min   int    // base timestamp
+		quote string // quote price paired with it
+		gap   time.Duration
+	}
+	tests := []struct {
+		name      string
+		base      []*PriceSnapshot
+		quote     []*PriceSnapshot
+		tolerance time.Duration
+		mode      LookupMode
+		want      []point
+	}{
+		{
+			name:  "nearest on either side",
+			base:  []*PriceSnapshot{at(0, "10"), at(4, "10"), at(9, "10"), at(20, "10")},
+			quote: []*PriceSnapshot{at(1, "2"), at(8, "4"), at(30, "5")},
+			mode:  LookupNearest,
+			want:  []point{{0, "2", time.Minute}, {4, "2", 3 * time.Minute}, {9, "4", time.Minute}, {20, "5", 10 * time.Minute}},
+		},
+		{
+			name:  "older wins ties",
+			base:  []*PriceSnapshot{at(5, "10")},
+			quote: []*PriceSnapshot{at(0, "2"), at(10, "4")},
+			mode:  LookupNearest,
+			want:  []point{{5, "2", 5 * time.Minute}},
+		},
+		{
+			name:      "outside tolerance skipped",
+			base:      []*PriceSnapshot{at(0, "10"), at(10, "10"), at(30, "10")},
+			quote:     []*PriceSnapshot{at(1, "2"), at(29, "4")},
+			tolerance: 2 * time.Minute,
+			mode:      LookupNearest,
+			want:      []point{{0, "2", time.Minute}, {30, "4", time.Minute}},
+		},
+		{
+			name:  "before never looks ahead",
+			base:  []*PriceSnapshot{at(0, "10"), at(5, "10"), at(9, "10"), at(10, "10")},
+			quote: []*PriceSnapshot{at(1, "2"), at(10, "4")},
+			mode:  LookupBefore,
+			want:  []point{{5, "2", 4 * time.Minute}, {9, "2", 8 * time.Minute}, {10, "4", 0}},
+		},
+		{
+			name:  "zero quote price skipped",
+			base:  []*PriceSnapshot{at(0, "10"), at(10, "10")},
+			quote: []*PriceSnapshot{at(0, "0"), at(10, "4")},
+			mode:  LookupNearest,
+			want:  []point{{10, "4", 0}},
+		},
+		{
+			name: "no quote snapshots",
+			base: []*PriceSnapshot{at(0, "10")},
+			mode: LookupNearest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AlignCrossRates(tt.base, tt.quote, tt.tolerance, tt.mode)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d points, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				quote := decimal.RequireFromString(w.quote)
+				if !g.Timestamp.Equal(t0.Add(time.Duration(w.min)*time.Minute)) || !g.QuotePrice.Equal(quote) || g.AlignmentError != w.gap {
+					t.Errorf("point %d = %s quote %s gap %s, want minute %d quote %s gap %s",
+						i, g.Timestamp, g.QuotePrice, g.AlignmentError, w.min, w.quote, w.gap)
+				}
+				if want := g.BasePrice.DivRound(quote, PriceScale); !g.Rate.Equal(want) {
+					t.Errorf("point %d rate %s, want %s", i, g.Rate, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewCrossRate(t *testing.T) {
+	lookup := func(min int, price string) *PriceLookup {
+		return &PriceLookup{Price: decimal.RequireFromString(price), Sources: []*PriceSnapshot{at(min, price)}}
+	}
+
+	cr, err := NewCrossRate(lookup(0, "1"), lookup(2, "3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := decimal.RequireFromString("0.33333333333333333333"); !cr.Rate.Equal(want) {
+		t.Errorf("rate %s, want %s", cr.Rate, want)
+	}
+	if cr.AlignmentError != 2*time.Minute {
+		t.Errorf("alignment error %s, want 2m", cr.AlignmentError)
+	}
+
+	if _, err := NewCrossRate(lookup(0, "1"), lookup(0, "0")); err != ErrZeroQuotePrice {
+		t.Errorf("zero quote: error %v, want %v", err, ErrZeroQuotePrice)
+	}
+}
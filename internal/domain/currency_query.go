@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Order of a currency listing
+type CurrencySort string
+
+const (
+	SortBySymbol  CurrencySort = "symbol"
+	SortByCreated CurrencySort = "created"
+)
+
+// Empty input defaults to SortBySymbol
+func ParseCurrencySort(raw string) (CurrencySort, error) {
+	switch s := CurrencySort(strings.ToLower(strings.TrimSpace(raw))); s {
+	case "":
+		return SortBySymbol, nil
+	case SortBySymbol, SortByCreated:
+		return s, nil
+	default:
+		return "", ErrInvalidSort
+	}
+}
+
+// Filter and keyset position of one page of tracked currencies
+type CurrencyQuery struct {
+	Prefix string         // symbol prefix, empty matches every symbol
+	Status CurrencyStatus // empty matches every status
+	Sort   CurrencySort
+	Desc   bool
+	After  *CurrencyKey // last row of the previous page, nil for the first page
+	Limit  int
+}
+
+// Position of a currency in a listing, only the fields of the sort order are used
+type CurrencyKey struct {
+	Symbol     string
+	ProviderID string // breaks ties between currencies sharing a symbol
+	CreatedAt  time.Time
+	ID         uuid.UUID // breaks ties between equal creation times
+}
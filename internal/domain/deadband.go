@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Change-based storage of a currency. A fetched price is only stored when it moved by more than
+// ThresholdPercent from the last stored one, or when Heartbeat has passed since it. Stored prices
+// then form a step function: the price at any time is the last one stored before it
+type Deadband struct {
+	ThresholdPercent float64 // relative move that forces a write, 0 only drops identical prices
+	Heartbeat        time.Duration
+}
+
+const maxDeadbandHeartbeat = 24 * time.Hour
+
+func (d *Deadband) Validate() error {
+	if d.ThresholdPercent < 0 || d.ThresholdPercent > 100 {
+		return ErrInvalidDeadband
+	}
+	if d.Heartbeat < time.Second || d.Heartbeat > maxDeadbandHeartbeat || d.Heartbeat%time.Second != 0 {
+		return ErrInvalidDeadband
+	}
+	return nil
+}
+
+// Whether `next` has to be stored after `last`, the latest stored price of the same series.
+// Everything is stored without a previous price
+func (d *Deadband) ShouldStore(last, next *PriceSnapshot) bool {
+	if last == nil {
+		return true
+	}
+	if !next.Timestamp.After(last.Timestamp) {
+		return false // already covered by the stored step
+	}
+	if next.Timestamp.Sub(last.Timestamp) >= d.Heartbeat {
+		return true
+	}
+	// |next - last| * 100 > threshold * |last|, without dividing
+	moved := next.Price.Sub(last.Price).Abs().Mul(decimal.NewFromInt(100))
+	return moved.GreaterThan(last.Price.Abs().Mul(decimal.NewFromFloat(d.ThresholdPercent)))
+}
+
+// Lookup mode answering `m` on a step function. Nearest and interpolated prices would reach
+// into a later step, so they read the last stored price instead. Nil keeps `m`
+func (d *Deadband) StepMode(m LookupMode) LookupMode {
+	if d == nil {
+		return m
+	}
+	if m == LookupNearest || m == LookupInterpolate {
+		return LookupBefore
+	}
+	return m
+}
+
+// Longest expected distance between stored prices, fetches run every `interval`
+func (d *Deadband) MaxGap(interval time.Duration) time.Duration {
+	return d.Heartbeat + interval
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDeadbandValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		d     Deadband
+		valid bool
+	}{
+		{"typical", Deadband{ThresholdPercent: 0.5, Heartbeat: time.Hour}, true},
+		{"zero threshold", Deadband{Heartbeat: time.Second}, true},
+		{"full range", Deadband{ThresholdPercent: 100, Heartbeat: maxDeadbandHeartbeat}, true},
+		{"negative threshold", Deadband{ThresholdPercent: -1, Heartbeat: time.Hour}, false},
+		{"threshold over 100", Deadband{ThresholdPercent: 100.1, Heartbeat: time.Hour}, false},
+		{"no heartbeat", Deadband{ThresholdPercent: 1}, false},
+		{"heartbeat over a day", Deadband{ThresholdPercent: 1, Heartbeat: maxDeadbandHeartbeat + time.Second}, false},
+		{"fractional heartbeat", Deadband{ThresholdPercent: 1, Heartbeat: 1500 * time.Millisecond}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.d.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidDeadband) {
+				t.Errorf("error %v, want %v", err, ErrInvalidDeadband)
+			}
+		})
+	}
+}
+
+func TestDeadbandShouldStore(t *testing.T) {
+	at := func(off time.Duration, price string) *PriceSnapshot {
+		return &PriceSnapshot{Timestamp: t0.Add(off), Price: decimal.RequireFromString(price)}
+	}
+	onePercent := Deadband{ThresholdPercent: 1, Heartbeat: time.Hour}
+	exact := Deadband{Heartbeat: time.Hour}
+
+	tests := []struct {
+		name string
+		d    Deadband
+		last *PriceSnapshot
+		next *PriceSnapshot
+		want bool
+	}{
+		{"first price", onePercent, nil, at(0, "100"), true},
+		{"within the band", onePercent, at(0, "100"), at(time.Minute, "100.99"), false},
+		{"on the band edge", onePercent, at(0, "100"), at(time.Minute, "101"), false},
+		{"above the band", onePercent, at(0, "100"), at(time.Minute, "101.01"), true},
+		{"below the band", onePercent, at(0, "100"), at(time.Minute, "98.99"), true},
+		{"just before the heartbeat", onePercent, at(0, "100"), at(time.Hour-time.Second, "100"), false},
+		{"heartbeat", onePercent, at(0, "100"), at(time.Hour, "100"), true},
+		{"same timestamp", onePercent, at(0, "100"), at(0, "200"), false},
+		{"older than the stored price", onePercent, at(time.Minute, "100"), at(0, "200"), false},
+		{"zero threshold, identical", exact, at(0, "100"), at(time.Minute, "100.0"), false},
+		{"zero threshold, smallest move", exact, at(0, "100"), at(time.Minute, "100.00000000000000000001"), true},
+		// No relative move is defined from zero, any change is stored
+		{"from zero", onePercent, at(0, "0"), at(time.Minute, "0.00000001"), true},
+		{"staying at zero", onePercent, at(0, "0"), at(time.Minute, "0"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.ShouldStore(tt.last, tt.next); got != tt.want {
+				t.Errorf("ShouldStore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeadbandStepMode(t *testing.T) {
+	d := &Deadband{ThresholdPercent: 1, Heartbeat: time.Hour}
+	for m, want := range map[LookupMode]LookupMode{
+		LookupNearest:     LookupBefore,
+		LookupInterpolate: LookupBefore,
+		LookupBefore:      LookupBefore,
+		LookupAfter:       LookupAfter,
+	} {
+		if got := d.StepMode(m); got != want {
+			t.Errorf("StepMode(%v) = %v, want %v", m, got, want)
+		}
+	}
+	var none *Deadband
+	if got := none.StepMode(LookupNearest); got != LookupNearest {
+		t.Errorf("nil StepMode = %v, want %v", got, LookupNearest)
+	}
+	if got := d.MaxGap(time.Minute); got != time.Hour+time.Minute {
+		t.Errorf("MaxGap = %s, want 1h1m", got)
+	}
+}
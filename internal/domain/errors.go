@@ -4,11 +4,39 @@ import "errors"
 
 var (
 	ErrInvalidSymbol     = errors.New("invalid cryptocurrency symbol")
+	ErrInvalidQuote      = errors.New("invalid quote currency")
 	ErrDuplicateCurrency = errors.New("cryptocurrency already exist")
 	ErrNotTracked        = errors.New("cryptocurrency not tracked")
+	ErrAmbiguousCurrency = errors.New("symbol names several tracked currencies, use the provider coin ID")
+	ErrInvalidProviderID = errors.New("invalid provider coin ID")
+	ErrInvalidDeadband   = errors.New("invalid deadband settings")
+	ErrInvalidStatus     = errors.New("invalid currency status")
+	ErrInvalidSchedule   = errors.New("invalid fetch schedule")
+	ErrInvalidSort       = errors.New("invalid sort order")
+	ErrNotArchived       = errors.New("currency must be archived before it can be purged")
+	ErrInvalidPurgeToken = errors.New("purge token is invalid or expired")
 
 	ErrNegativePrice   = errors.New("price must be non-negative")
 	ErrTimestampFuture = errors.New("timestamp cannot be in the future")
 	ErrDuplicatePrice  = errors.New("price already exists for this timestamp")
 	ErrPriceNotFound   = errors.New("price not found in the database")
+
+	ErrInvalidConflictPolicy = errors.New("invalid conflict policy")
+
+	ErrInvalidLookupMode = errors.New("invalid lookup mode")
+	ErrStalePrice        = errors.New("nearest price is too far from the requested timestamp")
+	ErrZeroQuotePrice    = errors.New("quote currency price is zero")
+
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	ErrInvalidInterval  = errors.New("unsupported interval")
+	ErrRangeTooLarge    = errors.New("time range too large for the requested interval")
+
+	ErrInvalidRetention = errors.New("invalid retention tiers")
+
+	ErrPriceMonthArchived = errors.New("month of prices is archived in a detached partition, attach it first")
+	ErrPartitionNotFound  = errors.New("no detached partition for that month")
+
+	ErrBackfillNotFound    = errors.New("backfill job not found")
+	ErrBackfillUnsupported = errors.New("price provider has no historical data")
+	ErrBackfillActive      = errors.New("backfill job is still active")
 )
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Lifecycle of a tracked currency. Only a purge deletes its stored prices
+type CurrencyStatus string
+
+const (
+	CurrencyActive   CurrencyStatus = "active"   // prices are fetched
+	CurrencyPaused   CurrencyStatus = "paused"   // fetching is suspended, the currency stays tracked
+	CurrencyArchived CurrencyStatus = "archived" // removed from tracking, history is kept until purged
+)
+
+func ParseCurrencyStatus(raw string) (CurrencyStatus, error) {
+	switch s := CurrencyStatus(strings.ToLower(strings.TrimSpace(raw))); s {
+	case CurrencyActive, CurrencyPaused, CurrencyArchived:
+		return s, nil
+	default:
+		return "", ErrInvalidStatus
+	}
+}
+
+// Stored rows of a currency that a purge deletes
+type PurgeCounts struct {
+	Prices       int64
+	Rollups      int64
+	BackfillJobs int64
+}
+
+// Issued before a purge, the token has to be sent back to confirm it
+type PurgeConfirmation struct {
+	Symbol    string
+	Token     string
+	ExpiresAt time.Time
+	Counts    PurgeCounts // what would be deleted at the time of the request
+}
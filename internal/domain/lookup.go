@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// How a price is picked from the stored snapshots around the requested time
+type LookupMode string
+
+const (
+	LookupNearest     LookupMode = "nearest"     // closest snapshot on either side, older wins ties
+	LookupBefore      LookupMode = "before"      // last snapshot at or before, no look-ahead
+	LookupAfter       LookupMode = "after"       // first snapshot at or after
+	LookupInterpolate LookupMode = "interpolate" // linear between the older and newer snapshots
+)
+
+// Empty input defaults to LookupNearest
+func ParseLookupMode(raw string) (LookupMode, error) {
+	switch m := LookupMode(strings.ToLower(strings.TrimSpace(raw))); m {
+	case "":
+		return LookupNearest, nil
+	case LookupNearest, LookupBefore, LookupAfter, LookupInterpolate:
+		return m, nil
+	default:
+		return "", ErrInvalidLookupMode
+	}
+}
+
+// Whether the mode needs the snapshot at or before the requested time
+func (m LookupMode) NeedsOlder() bool {
+	return m != LookupAfter
+}
+
+// Whether the mode needs the snapshot at or after the requested time
+func (m LookupMode) NeedsNewer() bool {
+	return m != LookupBefore
+}
+
+// Price resolved for a point in time
+type PriceLookup struct {
+	Mode     LookupMode
+	At       time.Time        // requested time
+	Price    decimal.Decimal  // in the quote currency of the sources
+	Sources  []*PriceSnapshot // snapshots the price came from, ordered by timestamp
+	Distance time.Duration    // largest gap between `At` and a source snapshot
+	Stale    bool             // set by the service when Distance exceeds the expected spacing of stored prices, not the tolerance
+}
+
+// Time the price was observed: the source snapshot time,
+// or the requested time for interpolated prices
+func (l *PriceLookup) ObservedAt() time.Time {
+	if len(l.Sources) == 1 {
+		return l.Sources[0].Timestamp
+	}
+	return l.At
+}
+
+// One (symbol, quote, time) item of a batch lookup
+type PriceQuery struct {
+	Symbol     string // symbol or provider coin ID of the currency
+	Quote      string
+	At         time.Time
+	Resolution time.Duration // rollup tier to read, 0 for raw snapshots
+}
+
+// Outcome of one batch item, exactly one of Lookup and Err is set
+type PriceLookupResult struct {
+	Lookup *PriceLookup
+	Err    error
+}
+
+// Picks or derives the price at `at` from its neighbours.
+// older is the latest snapshot at or before `at`, newer the earliest at or after it; either may be nil
+func ResolvePrice(mode LookupMode, at time.Time, older, newer *PriceSnapshot) (*PriceLookup, error) {
+	at = at.UTC()
+	pick := func(s *PriceSnapshot) (*PriceLookup, error) {
+		if s == nil {
+			return nil, ErrPriceNotFound
+		}
+		return &PriceLookup{
+			Mode:     mode,
+			At:       at,
+			Price:    s.Price,
+			Sources:  []*PriceSnapshot{s},
+			Distance: absDuration(at.Sub(s.Timestamp)),
+		}, nil
+	}
+
+	// An exact match satisfies every mode
+	if older != nil && older.Timestamp.Equal(at) {
+		return pick(older)
+	}
+	if newer != nil && newer.Timestamp.Equal(at) {
+		return pick(newer)
+	}
+
+	switch mode {
+	case LookupBefore:
+		return pick(older)
+	case LookupAfter:
+		return pick(newer)
+	case LookupInterpolate:
+		// No extrapolation past either end of the stored series
+		if older == nil || newer == nil {
+			return nil, ErrPriceNotFound
+		}
+		// Multiply before dividing so tiny prices keep their precision
+		span := decimal.NewFromInt(newer.Timestamp.Unix() - older.Timestamp.Unix())
+		elapsed := decimal.NewFromInt(at.Unix() - older.Timestamp.Unix())
+		delta := newer.Price.Sub(older.Price).Mul(elapsed).DivRound(span, PriceScale)
+		return &PriceLookup{
+			Mode:     mode,
+			At:       at,
+			Price:    older.Price.Add(delta),
+			Sources:  []*PriceSnapshot{older, newer},
+			Distance: max(at.Sub(older.Timestamp), newer.Timestamp.Sub(at)),
+		}, nil
+	case LookupNearest:
+		switch {
+		case older != nil && newer != nil:
+			if at.Sub(older.Timestamp) <= newer.Timestamp.Sub(at) {
+				return pick(older)
+			}
+			return pick(newer)
+		case older != nil:
+			return pick(older)
+		default:
+			return pick(newer)
+		}
+	default:
+		return nil, ErrInvalidLookupMode
+	}
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -6,42 +6,105 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // allow 1–10 uppercase alphanumeric chars
 var symbolRegex = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
 
+// Provider coin IDs are lowercase, e.g. "uni-uniswap"
+var providerIDRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+const maxProviderIDLen = 100
+
+// Placeholder ID of currencies added before provider IDs were stored, followed by the lowercase
+// symbol. Replaced by the coin the symbol resolved to when the service starts
+const LegacyProviderPrefix = "legacy:"
+
+// Identified by its provider coin ID, several currencies can share a symbol
 type Currency struct {
-	ID        uuid.UUID
-	Symbol    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID         uuid.UUID
+	Symbol     string
+	ProviderID string // coin ID at the price provider, e.g. "btc-bitcoin"
+	Status     CurrencyStatus
+	Schedule   FetchSchedule
+	Deadband   *Deadband // nil stores every fetched price
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
 }
 
-func NewCurrency(raw string) (*Currency, error) {
+func NewCurrency(raw, providerID string) (*Currency, error) {
 	s := strings.ToUpper(strings.TrimSpace(raw))
 	if !symbolRegex.MatchString(s) {
 		return nil, ErrInvalidSymbol
 	}
+	if len(providerID) > maxProviderIDLen || !providerIDRegex.MatchString(providerID) {
+		return nil, ErrInvalidProviderID
+	}
 	now := time.Now().UTC()
 	return &Currency{
-		ID:        uuid.New(),
-		Symbol:    s,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:         uuid.New(),
+		Symbol:     s,
+		ProviderID: providerID,
+		Status:     CurrencyActive,
+		CreatedAt:  now,
+		UpdatedAt:  now,
 	}, nil
 }
 
+// Whether `ref` names the currency, by its provider coin ID or by its symbol in any case.
+// A symbol can name several currencies
+func (c *Currency) Matches(ref string) bool {
+	return strings.EqualFold(ref, c.ProviderID) || strings.EqualFold(ref, c.Symbol)
+}
+
+// Whether the provider ID is still the placeholder of a currency added before IDs were stored
+func (c *Currency) HasLegacyProviderID() bool {
+	return strings.HasPrefix(c.ProviderID, LegacyProviderPrefix)
+}
+
+// Whether `ref` can name a currency: a symbol, or a provider coin ID in either case
+func ValidCurrencyRef(ref string) bool {
+	return symbolRegex.MatchString(ref) ||
+		len(ref) <= maxProviderIDLen && providerIDRegex.MatchString(strings.ToLower(ref))
+}
+
+// Quote currency of prices that don't specify one
+const DefaultQuote = "USD"
+
+// Fractional digits kept for prices, matches the scale of currency_prices.price
+const PriceScale = 20
+
 type PriceSnapshot struct {
 	ID         uuid.UUID
 	CurrencyID uuid.UUID
-	Timestamp  time.Time
-	Price      float64
+	Quote      string    // quote currency, e.g. "USD", "EUR", "BTC"
+	Timestamp  time.Time // when the provider observed the price
+	Price      decimal.Decimal
+	Market     MarketData
+	FetchedAt  time.Time     // when the tracker fetched it, zero when unknown
+	Resolution time.Duration // rollup bucket size it was read from, 0 for raw snapshots
 	CreatedAt  time.Time
 }
 
-func NewPriceSnapshot(curID uuid.UUID, ts time.Time, val float64) (*PriceSnapshot, error) {
-	if val < 0 {
+// Optional ticker data captured with a price, nil when the provider didn't report it.
+// Volume and market cap are expressed in the snapshot's quote currency
+type MarketData struct {
+	Volume24h         *float64
+	MarketCap         *float64
+	PercentChange1h   *float64
+	PercentChange24h  *float64
+	PercentChange7d   *float64
+	CirculatingSupply *float64
+	Rank              *int
+}
+
+func NewPriceSnapshot(curID uuid.UUID, quote string, ts time.Time, val decimal.Decimal) (*PriceSnapshot, error) {
+	quote, err := NormalizeQuote(quote)
+	if err != nil {
+		return nil, err
+	}
+	if val.IsNegative() {
 		return nil, ErrNegativePrice
 	}
 	now := time.Now().UTC()
@@ -51,8 +114,21 @@ func NewPriceSnapshot(curID uuid.UUID, ts time.Time, val float64) (*PriceSnapsho
 	return &PriceSnapshot{
 		ID:         uuid.New(),
 		CurrencyID: curID,
+		Quote:      quote,
 		Timestamp:  ts.UTC(),
 		Price:      val,
 		CreatedAt:  now,
 	}, nil
 }
+
+// Uppercases the quote currency, empty input defaults to DefaultQuote
+func NormalizeQuote(raw string) (string, error) {
+	q := strings.ToUpper(strings.TrimSpace(raw))
+	if q == "" {
+		return DefaultQuote, nil
+	}
+	if !symbolRegex.MatchString(q) {
+		return "", ErrInvalidQuote
+	}
+	return q, nil
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewCurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		symbol     string
+		providerID string
+		want       string
+		err        error
+	}{
+		{"valid", "btc", "btc-bitcoin", "BTC", nil},
+		{"trimmed", "  uni ", "uni-uniswap", "UNI", nil},
+		{"dotted id", "ETH", "eth.ethereum_2", "ETH", nil},
+		{"symbol too long", "ABCDEFGHIJK", "abc-abc", "", ErrInvalidSymbol},
+		{"symbol punctuation", "BT-C", "btc-bitcoin", "", ErrInvalidSymbol},
+		{"missing id", "BTC", "", "", ErrInvalidProviderID},
+		{"uppercase id", "BTC", "BTC-Bitcoin", "", ErrInvalidProviderID},
+		{"leading dash", "BTC", "-btc", "", ErrInvalidProviderID},
+		{"legacy placeholder", "BTC", LegacyProviderPrefix + "btc", "", ErrInvalidProviderID},
+		{"id too long", "BTC", strings.Repeat("a", maxProviderIDLen+1), "", ErrInvalidProviderID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cur, err := NewCurrency(tt.symbol, tt.providerID)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if cur.Symbol != tt.want || cur.ProviderID != tt.providerID || cur.Status != CurrencyActive {
+				t.Fatalf("got %s %s %s, want %s %s active", cur.Symbol, cur.ProviderID, cur.Status, tt.want, tt.providerID)
+			}
+		})
+	}
+}
+
+func TestCurrencyMatches(t *testing.T) {
+	cur := &Currency{Symbol: "UNI", ProviderID: "uni-uniswap"}
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"UNI", true},
+		{"uni", true},
+		{"uni-uniswap", true},
+		{"UNI-UNISWAP", true},
+		{"uni-universe", false},
+		{"UN", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := cur.Matches(tt.ref); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestCurrencyHasLegacyProviderID(t *testing.T) {
+	if !(&Currency{Symbol: "BTC", ProviderID: "legacy:btc"}).HasLegacyProviderID() {
+		t.Error("placeholder not reported as legacy")
+	}
+	if (&Currency{Symbol: "BTC", ProviderID: "btc-bitcoin"}).HasLegacyProviderID() {
+		t.Error("coin ID reported as legacy")
+	}
+}
+
+func TestValidCurrencyRef(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{"BTC", true},
+		{"btc", true},
+		{"uni-uniswap", true},
+		{"UNI-UNISWAP", true},
+		{"eth.ethereum_2", true},
+		{"ABCDEFGHIJKL", true}, // too long for a symbol, still a valid ID
+		{strings.Repeat("a", maxProviderIDLen), true},
+		{strings.Repeat("a", maxProviderIDLen+1), false},
+		{"", false},
+		{"-btc", false},
+		{"btc bitcoin", false},
+		{"legacy:btc", false},
+		{"btc/usd", false},
+	}
+	for _, tt := range tests {
+		if got := ValidCurrencyRef(tt.ref); got != tt.want {
+			t.Errorf("ValidCurrencyRef(%q) = %v, want %v", tt.ref, got, tt.want)
+		}
+	}
+}
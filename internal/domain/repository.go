@@ -3,12 +3,65 @@ package domain
 import (
 	"context"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type CryptoRepository interface {
 	AddCurrency(ctx context.Context, c *Currency) error
-	RemoveCurrency(ctx context.Context, symbol string) error
-	GetPriceSnapshot(ctx context.Context, symbol string, ts time.Time) (*PriceSnapshot, error)
+	// Currencies are named by symbol or provider coin ID, see Currency.Matches. GetCurrency fails
+	// with ErrAmbiguousCurrency when a symbol names several, GetCurrencies returns all of them
+	GetCurrency(ctx context.Context, ref string) (*Currency, error)
+	GetCurrencies(ctx context.Context, refs []string) ([]*Currency, error)
+	UpdateCurrency(ctx context.Context, c *Currency) error
+	GetPriceSnapshot(ctx context.Context, ref, quote string, ts time.Time, mode LookupMode) (*PriceLookup, error)
+	GetPriceSnapshots(ctx context.Context, queries []PriceQuery, mode LookupMode) ([]PriceLookupResult, error)
 	SavePriceSnapshot(ctx context.Context, snap *PriceSnapshot) error
+	SavePriceSnapshots(ctx context.Context, snaps []*PriceSnapshot, onConflict ConflictPolicy) (int64, error)
+	ListPriceSnapshots(ctx context.Context, currencyID uuid.UUID, quote string, start, end time.Time, limit int) ([]*PriceSnapshot, error)
+	StreamPriceSnapshots(ctx context.Context, currencyID uuid.UUID, quote string, start, end time.Time, fn func(*PriceSnapshot) error) error
 	ListCurrencies(ctx context.Context, limit, offset int) ([]*Currency, error)
+	FindCurrencies(ctx context.Context, q CurrencyQuery) ([]*Currency, error)
+	RollupRepository
+	BackfillRepository
+	PurgeRepository
+}
+
+// Optional capability for repositories that aggregate raw snapshots into candles natively.
+// Services fall back to BuildCandles over ListPriceSnapshots otherwise
+type CandleRepository interface {
+	ListCandles(ctx context.Context, currencyID uuid.UUID, quote string, start, end time.Time, bucket time.Duration) ([]*Candle, error)
+}
+
+// Downsampled storage behind the retention tiers. Rollups are candles keyed by
+// resolution, a source resolution of 0 means raw snapshots
+type RollupRepository interface {
+	RollupPrices(ctx context.Context, source, resolution time.Duration, since, until time.Time) (int64, error)
+	LatestRollup(ctx context.Context, resolution time.Duration) (time.Time, error)
+	ListRollups(ctx context.Context, currencyID uuid.UUID, quote string, resolution time.Duration, start, end time.Time, limit int) ([]*Candle, error)
+	PruneRollups(ctx context.Context, resolution time.Duration, before time.Time) (int64, error)
+	PrunePriceSnapshots(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Optional capability for repositories that partition prices by month
+type PartitionRepository interface {
+	// Fails with ErrPriceMonthArchived when a month in the range is detached
+	EnsurePricePartitions(ctx context.Context, from, to time.Time) ([]string, error)
+	DropPricePartitions(ctx context.Context, before time.Time, detachOnly bool) ([]string, error)
+	AttachPricePartition(ctx context.Context, month time.Time) (string, error)
+}
+
+// Persisted backfill jobs
+type BackfillRepository interface {
+	CreateBackfillJob(ctx context.Context, job *BackfillJob) error
+	UpdateBackfillJob(ctx context.Context, job *BackfillJob) error
+	GetBackfillJob(ctx context.Context, id uuid.UUID) (*BackfillJob, error)
+	ListUnfinishedBackfillJobs(ctx context.Context) ([]*BackfillJob, error)
+}
+
+// Irreversible deletion of archived currencies, confirmed by a token saved beforehand
+type PurgeRepository interface {
+	CountCurrencyData(ctx context.Context, currencyID uuid.UUID) (PurgeCounts, error)
+	SavePurgeToken(ctx context.Context, currencyID uuid.UUID, token string, expiresAt time.Time) error
+	PurgeCurrency(ctx context.Context, currencyID uuid.UUID, token string, now time.Time) (PurgeCounts, error)
 }
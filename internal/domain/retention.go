@@ -0,0 +1,111 @@
+package domain
+
+import "time"
+
+// Downsampled storage level, rolled up from the next finer tier
+type RetentionTier struct {
+	Resolution time.Duration // bucket size
+	Keep       time.Duration // age after which buckets are deleted, 0 keeps them forever
+}
+
+// How long prices are kept at each resolution.
+// Raw snapshots are kept for Raw (0 = forever), older prices are read from Rollups, finest first
+type RetentionPolicy struct {
+	Raw     time.Duration
+	Rollups []RetentionTier
+}
+
+// Part of a time window served by a single tier
+type TierSegment struct {
+	Resolution time.Duration // 0 for raw snapshots
+	From       time.Time
+	To         time.Time
+}
+
+func (p RetentionPolicy) Enabled() bool {
+	return p.Raw > 0
+}
+
+// Every tier must be rolled up from the previous one before it expires,
+// so resolutions grow by whole multiples and retention grows with them
+func (p RetentionPolicy) Validate() error {
+	if p.Raw < 0 {
+		return ErrInvalidRetention
+	}
+	var prevRes time.Duration
+	prevKeep := p.Raw
+	for _, t := range p.Rollups {
+		if t.Resolution < time.Second || t.Resolution%time.Second != 0 {
+			return ErrInvalidRetention
+		}
+		// Buckets nest exactly into the coarser ones
+		if prevRes > 0 && (t.Resolution <= prevRes || t.Resolution%prevRes != 0) {
+			return ErrInvalidRetention
+		}
+		// The source tier must be pruned and must outlive one full bucket
+		if prevKeep == 0 || prevKeep < t.Resolution {
+			return ErrInvalidRetention
+		}
+		if t.Keep < 0 || t.Keep != 0 && t.Keep < prevKeep {
+			return ErrInvalidRetention
+		}
+		prevRes, prevKeep = t.Resolution, t.Keep
+	}
+	return nil
+}
+
+// Resolution of the tier holding prices at `at`. Past the last tier's retention
+// nothing is stored anymore and the coarsest resolution is returned
+func (p RetentionPolicy) ResolutionAt(at, now time.Time) time.Duration {
+	if !p.Enabled() || !at.Before(now.Add(-p.Raw)) {
+		return 0
+	}
+	for _, t := range p.Rollups {
+		if t.Keep == 0 || !at.Before(now.Add(-t.Keep)) {
+			return t.Resolution
+		}
+	}
+	if len(p.Rollups) == 0 {
+		return 0
+	}
+	return p.Rollups[len(p.Rollups)-1].Resolution
+}
+
+// Splits [from, to] into per-tier segments ordered by time.
+// Ranges older than every tier are left out since their data is gone
+func (p RetentionPolicy) Segments(from, to, now time.Time) []TierSegment {
+	if !p.Enabled() {
+		return []TierSegment{{From: from, To: to}}
+	}
+
+	// Walk tiers newest first, each one covers [now - keep, upper]
+	var segs []TierSegment
+	upper := to
+	tiers := append([]RetentionTier{{Keep: p.Raw}}, p.Rollups...)
+	for _, t := range tiers {
+		lower := from
+		if t.Keep > 0 {
+			if oldest := now.Add(-t.Keep); oldest.After(lower) {
+				lower = oldest
+			}
+		}
+		if !upper.Before(lower) {
+			segs = append(segs, TierSegment{Resolution: t.Resolution, From: lower, To: upper})
+		}
+		if t.Keep == 0 {
+			break
+		}
+		// The next tier ends where this one starts, never past `to`
+		if next := now.Add(-t.Keep - time.Second); next.Before(upper) {
+			upper = next
+		}
+		if upper.Before(from) {
+			break
+		}
+	}
+
+	for i, j := 0, len(segs)-1; i < j; i, j = i+1, j-1 {
+		segs[i], segs[j] = segs[j], segs[i]
+	}
+	return segs
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// Raw for 7 days, 5 minute bars for 90 days, daily bars forever
+var tieredPolicy = RetentionPolicy{
+	Raw: 7 * day,
+	Rollups: []RetentionTier{
+		{Resolution: 5 * time.Minute, Keep: 90 * day},
+		{Resolution: day},
+	},
+}
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		valid  bool
+	}{
+		{"disabled", RetentionPolicy{}, true},
+		{"raw only", RetentionPolicy{Raw: day}, true},
+		{"tiered", tieredPolicy, true},
+		{"negative raw", RetentionPolicy{Raw: -time.Hour}, false},
+		{"rollups of unpruned raw", RetentionPolicy{Rollups: []RetentionTier{{Resolution: time.Hour}}}, false},
+		{"sub-second resolution", RetentionPolicy{Raw: day, Rollups: []RetentionTier{{Resolution: time.Millisecond}}}, false},
+		{"fractional seconds", RetentionPolicy{Raw: day, Rollups: []RetentionTier{{Resolution: 1500 * time.Millisecond}}}, false},
+		{"buckets don't nest", RetentionPolicy{Raw: day, Rollups: []RetentionTier{
+			{Resolution: 5 * time.Minute, Keep: 2 * day}, {Resolution: 7 * time.Minute},
+		}}, false},
+		{"coarser before finer", RetentionPolicy{Raw: day, Rollups: []RetentionTier{
+			{Resolution: time.Hour, Keep: 2 * day}, {Resolution: 5 * time.Minute},
+		}}, false},
+		{"source expires within one bucket", RetentionPolicy{Raw: time.Minute, Rollups: []RetentionTier{{Resolution: 5 * time.Minute}}}, false},
+		{"source kept exactly one bucket", RetentionPolicy{Raw: 5 * time.Minute, Rollups: []RetentionTier{{Resolution: 5 * time.Minute}}}, true},
+		{"tier kept shorter than its source", RetentionPolicy{Raw: 7 * day, Rollups: []RetentionTier{{Resolution: time.Hour, Keep: day}}}, false},
+		{"tier after a forever tier", RetentionPolicy{Raw: day, Rollups: []RetentionTier{
+			{Resolution: time.Hour}, {Resolution: day},
+		}}, false},
+		{"negative keep", RetentionPolicy{Raw: day, Rollups: []RetentionTier{{Resolution: time.Hour, Keep: -day}}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidRetention) {
+				t.Errorf("error %v, want %v", err, ErrInvalidRetention)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicyResolutionAt(t *testing.T) {
+	now := t0
+	tests := []struct {
+		name   string
+		policy RetentionPolicy
+		at     time.Time
+		want   time.Duration
+	}{
+		{"disabled", RetentionPolicy{}, now.Add(-1000 * day), 0},
+		{"raw", tieredPolicy, now.Add(-time.Hour), 0},
+		{"last raw second", tieredPolicy, now.Add(-7 * day), 0},
+		{"first rolled up second", tieredPolicy, now.Add(-7*day - time.Second), 5 * time.Minute},
+		{"last 5m second", tieredPolicy, now.Add(-90 * day), 5 * time.Minute},
+		{"daily", tieredPolicy, now.Add(-90*day - time.Second), day},
+		{"past every tier", RetentionPolicy{Raw: day, Rollups: []RetentionTier{{Resolution: time.Hour, Keep: 2 * day}}}, now.Add(-3 * day), time.Hour},
+		{"raw only, pruned", RetentionPolicy{Raw: day}, now.Add(-2 * day), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.ResolutionAt(tt.at, now); got != tt.want {
+				t.Errorf("ResolutionAt = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetentionPolicySegments(t *testing.T) {
+	now := t0
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+	seg := func(res time.Duration, from, to time.Time) TierSegment {
+		return TierSegment{Resolution: res, From: from, To: to}
+	}
+
+	tests := []struct {
+		name     string
+		policy   RetentionPolicy
+		from, to time.Time
+		want     []TierSegment
+	}{
+		{
+			name:   "disabled",
+			policy: RetentionPolicy{},
+			from:   ago(1000 * day), to: now,
+			want: []TierSegment{seg(0, ago(1000*day), now)},
+		},
+		{
+			name:   "raw only",
+			policy: tieredPolicy,
+			from:   ago(day), to: now,
+			want: []TierSegment{seg(0, ago(day), now)},
+		},
+		{
+			name:   "across every tier",
+			policy: tieredPolicy,
+			from:   ago(200 * day), to: now,
+			want: []TierSegment{
+				seg(day, ago(200*day), ago(90*day+time.Second)),
+				seg(5*time.Minute, ago(90*day), ago(7*day+time.Second)),
+				seg(0, ago(7*day), now),
+			},
+		},
+		{
+			name:   "starting on the raw boundary",
+			policy: tieredPolicy,
+			from:   ago(7 * day), to: now,
+			want: []TierSegment{seg(0, ago(7*day), now)},
+		},
+		{
+			name:   "one second before the raw boundary",
+			policy: tieredPolicy,
+			from:   ago(7*day + time.Second), to: now,
+			want: []TierSegment{
+				seg(5*time.Minute, ago(7*day+time.Second), ago(7*day+time.Second)),
+				seg(0, ago(7*day), now),
+			},
+		},
+		{
+			name:   "only rolled up",
+			policy: tieredPolicy,
+			from:   ago(30 * day), to: ago(10 * day),
+			want: []TierSegment{seg(5*time.Minute, ago(30*day), ago(10*day))},
+		},
+		{
+			name:   "older than every tier",
+			policy: RetentionPolicy{Raw: day, Rollups: []RetentionTier{{Resolution: time.Hour, Keep: 2 * day}}},
+			from:   ago(10 * day), to: ago(5 * day),
+		},
+		{
+			name:   "partly older than every tier",
+			policy: RetentionPolicy{Raw: day, Rollups: []RetentionTier{{Resolution: time.Hour, Keep: 2 * day}}},
+			from:   ago(10 * day), to: now,
+			want: []TierSegment{
+				seg(time.Hour, ago(2*day), ago(day+time.Second)),
+				seg(0, ago(day), now),
+			},
+		},
+		{
+			name:   "raw only, partly pruned",
+			policy: RetentionPolicy{Raw: day},
+			from:   ago(3 * day), to: now,
+			want: []TierSegment{seg(0, ago(day), now)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Segments(tt.from, tt.to, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d segments %+v, want %+v", len(got), got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i].Resolution != tt.want[i].Resolution || !got[i].From.Equal(tt.want[i].From) || !got[i].To.Equal(tt.want[i].To) {
+					t.Errorf("segment %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// How often and how urgently a currency is fetched. When more fetches are due than the
+// provider rate limit allows, higher priorities go first
+type FetchSchedule struct {
+	Interval time.Duration // 0 uses the global fetch interval
+	Priority int           // 0 to MaxFetchPriority
+}
+
+const (
+	minFetchInterval = time.Second
+	maxFetchInterval = 24 * time.Hour
+
+	MaxFetchPriority = 100
+)
+
+func (f FetchSchedule) Validate() error {
+	if f.Interval != 0 && (f.Interval < minFetchInterval || f.Interval > maxFetchInterval || f.Interval%time.Second != 0) {
+		return ErrInvalidSchedule
+	}
+	if f.Priority < 0 || f.Priority > MaxFetchPriority {
+		return ErrInvalidSchedule
+	}
+	return nil
+}
+
+// Fetch interval of the schedule, `def` when it has none
+func (f FetchSchedule) IntervalOr(def time.Duration) time.Duration {
+	if f.Interval > 0 {
+		return f.Interval
+	}
+	return def
+}
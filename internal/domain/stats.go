@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const year = 365 * 24 * time.Hour
+
+// Summary statistics of a price series over a window
+type WindowStats struct {
+	Count         int
+	First         time.Time // timestamp of the first snapshot
+	Last          time.Time // timestamp of the last snapshot
+	Open          decimal.Decimal
+	Close         decimal.Decimal
+	Change        decimal.Decimal // Close - Open
+	ChangePercent float64
+	Min           decimal.Decimal
+	MinAt         time.Time
+	Max           decimal.Decimal
+	MaxAt         time.Time
+	Mean          decimal.Decimal
+	Volatility    float64 // annualised standard deviation of log returns, as a fraction
+	MaxDrawdown   float64 // largest peak-to-trough decline, in percent of the peak
+}
+
+// Computes the statistics of snapshots ordered by timestamp
+func ComputeWindowStats(snaps []*PriceSnapshot) (*WindowStats, error) {
+	var acc StatsAccumulator
+	for _, snap := range snaps {
+		acc.Add(snap)
+	}
+	return acc.Stats()
+}
+
+// Builds WindowStats one snapshot at a time, so a window never has to be held in memory.
+// The zero value is ready to use, snapshots must be added in timestamp order
+type StatsAccumulator struct {
+	st   WindowStats
+	sum  decimal.Decimal
+	peak float64
+	prev float64
+
+	// Running mean and sum of squared deviations of the log returns (Welford)
+	returns    int
+	returnMean float64
+	returnM2   float64
+}
+
+func (a *StatsAccumulator) Add(snap *PriceSnapshot) {
+	st := &a.st
+	if st.Count == 0 {
+		st.First, st.Open = snap.Timestamp, snap.Price
+		st.Min, st.MinAt = snap.Price, snap.Timestamp
+		st.Max, st.MaxAt = snap.Price, snap.Timestamp
+		a.sum = decimal.Zero
+	}
+	st.Count++
+	st.Last, st.Close = snap.Timestamp, snap.Price
+	a.sum = a.sum.Add(snap.Price)
+
+	if snap.Price.LessThan(st.Min) {
+		st.Min, st.MinAt = snap.Price, snap.Timestamp
+	}
+	if snap.Price.GreaterThan(st.Max) {
+		st.Max, st.MaxAt = snap.Price, snap.Timestamp
+	}
+
+	// Prices stay exact, ratio based metrics are computed in float64
+	p := snap.Price.InexactFloat64()
+	a.peak = max(a.peak, p)
+	if a.peak > 0 {
+		st.MaxDrawdown = max(st.MaxDrawdown, (a.peak-p)/a.peak*100)
+	}
+
+	if a.prev > 0 && p > 0 {
+		r := math.Log(p / a.prev)
+		a.returns++
+		d := r - a.returnMean
+		a.returnMean += d / float64(a.returns)
+		a.returnM2 += d * (r - a.returnMean)
+	}
+	a.prev = p
+}
+
+// Statistics of the snapshots added so far, ErrPriceNotFound when there were none
+func (a *StatsAccumulator) Stats() (*WindowStats, error) {
+	if a.st.Count == 0 {
+		return nil, ErrPriceNotFound
+	}
+	st := a.st
+	st.Change = st.Close.Sub(st.Open)
+	if !st.Open.IsZero() {
+		st.ChangePercent = st.Change.Div(st.Open).InexactFloat64() * 100
+	}
+	st.Mean = a.sum.DivRound(decimal.NewFromInt(int64(st.Count)), PriceScale)
+
+	// Annualise the sample standard deviation with the average sampling interval of the window
+	if a.returns > 1 && st.Last.After(st.First) {
+		step := st.Last.Sub(st.First) / time.Duration(st.Count-1)
+		st.Volatility = math.Sqrt(a.returnM2/float64(a.returns-1)) * math.Sqrt(float64(year)/float64(step))
+	}
+	return &st, nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+var t0 = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+// Snapshots one `step` apart starting at t0
+func series(step time.Duration, prices ...string) []*PriceSnapshot {
+	snaps := make([]*PriceSnapshot, len(prices))
+	for i, p := range prices {
+		snaps[i] = &PriceSnapshot{
+			Timestamp: t0.Add(time.Duration(i) * step),
+			Price:     decimal.RequireFromString(p),
+		}
+	}
+	return snaps
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*max(1, math.Abs(b))
+}
+
+func TestComputeWindowStats(t *testing.T) {
+	st, err := ComputeWindowStats(series(time.Hour, "100", "120", "90", "110"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Count != 4 || !st.First.Equal(t0) || !st.Last.Equal(t0.Add(3*time.Hour)) {
+		t.Errorf("count %d from %s to %s", st.Count, st.First, st.Last)
+	}
+	if !st.Open.Equal(decimal.NewFromInt(100)) || !st.Close.Equal(decimal.NewFromInt(110)) {
+		t.Errorf("open %s close %s", st.Open, st.Close)
+	}
+	if !st.Change.Equal(decimal.NewFromInt(10)) || !approx(st.ChangePercent, 10) {
+		t.Errorf("change %s (%v%%), want 10 (10%%)", st.Change, st.ChangePercent)
+	}
+	if !st.Min.Equal(decimal.NewFromInt(90)) || !st.MinAt.Equal(t0.Add(2*time.Hour)) {
+		t.Errorf("min %s at %s", st.Min, st.MinAt)
+	}
+	if !st.Max.Equal(decimal.NewFromInt(120)) || !st.MaxAt.Equal(t0.Add(time.Hour)) {
+		t.Errorf("max %s at %s", st.Max, st.MaxAt)
+	}
+	if !st.Mean.Equal(decimal.NewFromInt(105)) {
+		t.Errorf("mean %s, want 105", st.Mean)
+	}
+	// From the 120 peak down to 90
+	if !approx(st.MaxDrawdown, 25) {
+		t.Errorf("max drawdown %v, want 25", st.MaxDrawdown)
+	}
+}
+
+func TestComputeWindowStatsVolatility(t *testing.T) {
+	// Log returns of +ln(1.1) and -ln(1.1): mean 0, sample deviation ln(1.1) * sqrt(2)
+	sd := math.Log(1.1) * math.Sqrt(2)
+	tests := []struct {
+		name  string
+		snaps []*PriceSnapshot
+		want  float64
+	}{
+		{"daily", series(24*time.Hour, "100", "110", "100"), sd * math.Sqrt(365)},
+		{"hourly", series(time.Hour, "100", "110", "100"), sd * math.Sqrt(365*24)},
+		{"flat", series(time.Hour, "5", "5", "5", "5"), 0},
+		// A single return has no deviation
+		{"two points", series(time.Hour, "100", "110"), 0},
+		{"one point", series(time.Hour, "100"), 0},
+		// Zero prices have no log return, the rest still counts
+		{"zero price skipped", series(24*time.Hour, "0", "100", "110", "100"), sd * math.Sqrt(365)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ComputeWindowStats(tt.snaps)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !approx(st.Volatility, tt.want) {
+				t.Errorf("volatility %v, want %v", st.Volatility, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeWindowStatsEdgeCases(t *testing.T) {
+	if _, err := ComputeWindowStats(nil); !errors.Is(err, ErrPriceNotFound) {
+		t.Errorf("empty window: error %v, want %v", err, ErrPriceNotFound)
+	}
+
+	st, err := ComputeWindowStats(series(time.Hour, "0", "5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.ChangePercent != 0 || !st.Change.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("zero open: change %s (%v%%)", st.Change, st.ChangePercent)
+	}
+
+	// Rising prices never draw down, the mean keeps PriceScale decimals
+	st, err = ComputeWindowStats(series(time.Hour, "1", "2", "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.MaxDrawdown != 0 {
+		t.Errorf("max drawdown %v, want 0", st.MaxDrawdown)
+	}
+	if want := decimal.RequireFromString("1.66666666666666666667"); !st.Mean.Equal(want) {
+		t.Errorf("mean %s, want %s", st.Mean, want)
+	}
+}
@@ -0,0 +1,8 @@
+package indicators
+
+import "errors"
+
+var (
+	ErrUnknownIndicator = errors.New("unknown indicator")
+	ErrInvalidParams    = errors.New("invalid indicator parameters")
+)
@@ -0,0 +1,77 @@
+package indicators
+
+import "math"
+
+// Simple moving average, the first period-1 values are NaN
+func SMA(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period <= 0 || len(values) < period {
+		return out
+	}
+
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// Exponential moving average seeded with the SMA of the first period values.
+// Leading NaNs in the input are skipped, so EMA can run over another indicator's output
+func EMA(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	start := 0
+	for start < len(values) && math.IsNaN(values[start]) {
+		start++
+	}
+	if period <= 0 || len(values)-start < period {
+		return out
+	}
+
+	var seed float64
+	for _, v := range values[start : start+period] {
+		seed += v
+	}
+	prev := seed / float64(period)
+	out[start+period-1] = prev
+
+	alpha := 2 / float64(period+1)
+	for i := start + period; i < len(values); i++ {
+		prev = alpha*values[i] + (1-alpha)*prev
+		out[i] = prev
+	}
+	return out
+}
+
+// Bollinger bands: SMA middle line with bands k population standard deviations away
+func Bollinger(values []float64, period int, k float64) (middle, upper, lower []float64) {
+	middle = SMA(values, period)
+	upper = nanSlice(len(values))
+	lower = nanSlice(len(values))
+
+	for i := period - 1; i >= 0 && i < len(values); i++ {
+		var ss float64
+		for _, v := range values[i-period+1 : i+1] {
+			d := v - middle[i]
+			ss += d * d
+		}
+		sd := math.Sqrt(ss / float64(period))
+		upper[i] = middle[i] + k*sd
+		lower[i] = middle[i] - k*sd
+	}
+	return middle, upper, lower
+}
+
+func nanSlice(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
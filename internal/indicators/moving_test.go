@@ -0,0 +1,115 @@
+package indicators
+
+import (
+	"math"
+	"testing"
+)
+
+var nan = math.NaN()
+
+// Compares series element-wise, NaN only equals NaN
+func assertSeries(t *testing.T, name string, got, want []float64, tol float64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: len = %d, want %d", name, len(got), len(want))
+	}
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || math.Abs(got[i]-want[i]) > tol {
+			t.Errorf("%s[%d] = %v, want %v\ngot  %v", name, i, got[i], want[i], got)
+			return
+		}
+	}
+}
+
+func TestSMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		{"warm-up", []float64{1, 2, 3, 4, 5, 6}, 3, []float64{nan, nan, 2, 3, 4, 5}},
+		{"period 1", []float64{4, 8, 15}, 1, []float64{4, 8, 15}},
+		{"window equals period", []float64{2, 4, 9}, 3, []float64{nan, nan, 5}},
+		{"window shorter than period", []float64{1, 2}, 3, []float64{nan, nan}},
+		{"flat", []float64{7, 7, 7, 7}, 2, []float64{nan, 7, 7, 7}},
+		{"zero period", []float64{1, 2}, 0, []float64{nan, nan}},
+		{"empty", nil, 3, []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSeries(t, "sma", SMA(tt.values, tt.period), tt.want, 1e-12)
+		})
+	}
+}
+
+func TestEMA(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+	}{
+		// alpha 0.5, seeded with the SMA of the first three values
+		{"seeded with sma", []float64{2, 4, 6, 8, 12, 14}, 3, []float64{nan, nan, 4, 6, 9, 11.5}},
+		// StockCharts' 10-day EMA example, published to two decimals
+		{"reference", []float64{
+			22.27, 22.19, 22.08, 22.17, 22.18, 22.13, 22.23, 22.43, 22.24, 22.29,
+			22.15, 22.39, 22.38, 22.61, 23.36,
+		}, 10, []float64{
+			nan, nan, nan, nan, nan, nan, nan, nan, nan, 22.22,
+			22.21, 22.24, 22.27, 22.33, 22.52,
+		}},
+		{"leading NaN skipped", []float64{nan, nan, 1, 3, 5}, 2, []float64{nan, nan, nan, 2, 4}},
+		{"window shorter than period", []float64{nan, 1, 2}, 3, []float64{nan, nan, nan}},
+		{"flat", []float64{3, 3, 3, 3}, 2, []float64{nan, 3, 3, 3}},
+		{"zero period", []float64{1, 2}, 0, []float64{nan, nan}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSeries(t, "ema", EMA(tt.values, tt.period), tt.want, 0.005)
+		})
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	tests := []struct {
+		name              string
+		values            []float64
+		period            int
+		k                 float64
+		mid, upper, lower []float64
+	}{
+		// Mean 5 and population standard deviation 2
+		{
+			"reference", []float64{2, 4, 4, 4, 5, 5, 7, 9}, 8, 2,
+			[]float64{nan, nan, nan, nan, nan, nan, nan, 5},
+			[]float64{nan, nan, nan, nan, nan, nan, nan, 9},
+			[]float64{nan, nan, nan, nan, nan, nan, nan, 1},
+		},
+		{
+			"rolling", []float64{1, 3, 5, 5}, 2, 1,
+			[]float64{nan, 2, 4, 5},
+			[]float64{nan, 3, 5, 5},
+			[]float64{nan, 1, 3, 5},
+		},
+		{
+			"flat collapses the bands", []float64{4, 4, 4}, 3, 2,
+			[]float64{nan, nan, 4},
+			[]float64{nan, nan, 4},
+			[]float64{nan, nan, 4},
+		},
+		{
+			"window shorter than period", []float64{1, 2}, 3, 2,
+			[]float64{nan, nan}, []float64{nan, nan}, []float64{nan, nan},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mid, upper, lower := Bollinger(tt.values, tt.period, tt.k)
+			assertSeries(t, "middle", mid, tt.mid, 1e-12)
+			assertSeries(t, "upper", upper, tt.upper, 1e-12)
+			assertSeries(t, "lower", lower, tt.lower, 1e-12)
+		})
+	}
+}
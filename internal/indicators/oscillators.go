@@ -0,0 +1,65 @@
+package indicators
+
+// Relative strength index with Wilder's smoothing, the first period values are NaN
+func RSI(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period <= 0 || len(values) <= period {
+		return out
+	}
+
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		d := values[i] - values[i-1]
+		if d > 0 {
+			gain += d
+		} else {
+			loss -= d
+		}
+	}
+	gain /= float64(period)
+	loss /= float64(period)
+	out[period] = rsi(gain, loss)
+
+	for i := period + 1; i < len(values); i++ {
+		d := values[i] - values[i-1]
+		g, l := 0.0, 0.0
+		if d > 0 {
+			g = d
+		} else {
+			l = -d
+		}
+		gain = (gain*float64(period-1) + g) / float64(period)
+		loss = (loss*float64(period-1) + l) / float64(period)
+		out[i] = rsi(gain, loss)
+	}
+	return out
+}
+
+// A flat window has neither gains nor losses and sits in the middle
+func rsi(gain, loss float64) float64 {
+	if gain == 0 && loss == 0 {
+		return 50
+	}
+	if loss == 0 {
+		return 100
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+// MACD line (fast EMA - slow EMA), its signal EMA and the histogram between them
+func MACD(values []float64, fast, slow, signal int) (macd, sig, hist []float64) {
+	fastEMA := EMA(values, fast)
+	slowEMA := EMA(values, slow)
+
+	macd = make([]float64, len(values))
+	for i := range values {
+		macd[i] = fastEMA[i] - slowEMA[i] // NaN until both are warm
+	}
+
+	sig = EMA(macd, signal)
+	hist = make([]float64, len(values))
+	for i := range values {
+		hist[i] = macd[i] - sig[i]
+	}
+	return macd, sig, hist
+}
@@ -0,0 +1,77 @@
+package indicators
+
+import "testing"
+
+func TestRSI(t *testing.T) {
+	// Wilder's worked example as published by StockCharts, which rounds the averages
+	// to two decimals along the way, hence the tolerance
+	closes := []float64{
+		44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08,
+		45.89, 46.03, 45.61, 46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64,
+	}
+	reference := []float64{
+		nan, nan, nan, nan, nan, nan, nan, nan, nan, nan,
+		nan, nan, nan, nan, 70.53, 66.32, 66.55, 69.41, 66.36, 57.97,
+	}
+
+	tests := []struct {
+		name   string
+		values []float64
+		period int
+		want   []float64
+		tol    float64
+	}{
+		{"reference", closes, 14, reference, 0.1},
+		{"only gains", []float64{1, 2, 3, 4}, 2, []float64{nan, nan, 100, 100}, 1e-12},
+		{"only losses", []float64{4, 3, 2, 1}, 2, []float64{nan, nan, 0, 0}, 1e-12},
+		{"balanced", []float64{1, 2, 1}, 2, []float64{nan, nan, 50}, 1e-12},
+		// No gains and no losses would divide zero by zero
+		{"flat", []float64{5, 5, 5, 5}, 2, []float64{nan, nan, 50, 50}, 1e-12},
+		{"flat after a gain", []float64{1, 2, 2, 2}, 1, []float64{nan, 100, 50, 50}, 1e-12},
+		// The first value needs period changes, so period+1 points
+		{"window equals period", []float64{1, 2, 3}, 3, []float64{nan, nan, nan}, 0},
+		{"window shorter than period", []float64{1, 2}, 3, []float64{nan, nan}, 0},
+		{"zero period", []float64{1, 2}, 0, []float64{nan, nan}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertSeries(t, "rsi", RSI(tt.values, tt.period), tt.want, tt.tol)
+		})
+	}
+}
+
+func TestMACD(t *testing.T) {
+	tests := []struct {
+		name               string
+		values             []float64
+		fast, slow, signal int
+		macd, sig, hist    []float64
+	}{
+		// On a straight line the fast EMA lags by 0.5 and the slow one by 1, so the MACD is
+		// a constant 0.5 once both are warm and the signal needs one more point
+		{
+			"linear", []float64{1, 2, 3, 4, 5, 6}, 2, 3, 2,
+			[]float64{nan, nan, 0.5, 0.5, 0.5, 0.5},
+			[]float64{nan, nan, nan, 0.5, 0.5, 0.5},
+			[]float64{nan, nan, nan, 0, 0, 0},
+		},
+		{
+			"flat", []float64{3, 3, 3, 3, 3}, 2, 3, 2,
+			[]float64{nan, nan, 0, 0, 0},
+			[]float64{nan, nan, nan, 0, 0},
+			[]float64{nan, nan, nan, 0, 0},
+		},
+		{
+			"window shorter than slow period", []float64{1, 2}, 2, 3, 2,
+			[]float64{nan, nan}, []float64{nan, nan}, []float64{nan, nan},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			macd, sig, hist := MACD(tt.values, tt.fast, tt.slow, tt.signal)
+			assertSeries(t, "macd", macd, tt.macd, 1e-12)
+			assertSeries(t, "signal", sig, tt.sig, 1e-12)
+			assertSeries(t, "histogram", hist, tt.hist, 1e-12)
+		})
+	}
+}
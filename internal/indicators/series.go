@@ -0,0 +1,132 @@
+package indicators
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	NameSMA       = "sma"
+	NameEMA       = "ema"
+	NameRSI       = "rsi"
+	NameBollinger = "bollinger"
+	NameMACD      = "macd"
+)
+
+// Indicator parameters, zero values take the conventional defaults
+type Params struct {
+	Period int     // SMA, EMA, RSI, Bollinger
+	K      float64 // Bollinger band width in standard deviations
+	Fast   int     // MACD
+	Slow   int     // MACD
+	Signal int     // MACD
+}
+
+// Fills unset parameters and validates them for the indicator
+func (p Params) withDefaults(name string) (Params, error) {
+	switch name {
+	case NameSMA, NameEMA, NameBollinger:
+		if p.Period == 0 {
+			p.Period = 20
+		}
+		if name == NameBollinger && p.K == 0 {
+			p.K = 2
+		}
+	case NameRSI:
+		if p.Period == 0 {
+			p.Period = 14
+		}
+	case NameMACD:
+		if p.Fast == 0 {
+			p.Fast = 12
+		}
+		if p.Slow == 0 {
+			p.Slow = 26
+		}
+		if p.Signal == 0 {
+			p.Signal = 9
+		}
+		if p.Fast >= p.Slow {
+			return p, ErrInvalidParams
+		}
+	default:
+		return p, ErrUnknownIndicator
+	}
+	if p.Period < 0 || p.K < 0 || p.Fast < 0 || p.Slow < 0 || p.Signal < 0 {
+		return p, ErrInvalidParams
+	}
+	return p, nil
+}
+
+// Number of input points consumed before the first output value
+func Warmup(name string, p Params) (int, error) {
+	p, err := p.withDefaults(strings.ToLower(name))
+	if err != nil {
+		return 0, err
+	}
+	switch strings.ToLower(name) {
+	case NameRSI:
+		return p.Period, nil
+	case NameMACD:
+		return p.Slow + p.Signal - 2, nil
+	default:
+		return p.Period - 1, nil
+	}
+}
+
+// One output row, Values are aligned with Series.Lines
+type Point struct {
+	Time   time.Time
+	Values []float64
+}
+
+type Series struct {
+	Name   string
+	Params Params   // effective parameters after defaults
+	Lines  []string // e.g. "middle", "upper", "lower"
+	Points []Point
+}
+
+// Computes the named indicator over an evenly sampled series.
+// Warm-up rows where any line is undefined are dropped
+func Compute(name string, p Params, times []time.Time, values []float64) (*Series, error) {
+	name = strings.ToLower(name)
+	p, err := p.withDefaults(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	var cols [][]float64
+	switch name {
+	case NameSMA:
+		lines, cols = []string{"value"}, [][]float64{SMA(values, p.Period)}
+	case NameEMA:
+		lines, cols = []string{"value"}, [][]float64{EMA(values, p.Period)}
+	case NameRSI:
+		lines, cols = []string{"value"}, [][]float64{RSI(values, p.Period)}
+	case NameBollinger:
+		mid, up, low := Bollinger(values, p.Period, p.K)
+		lines, cols = []string{"middle", "upper", "lower"}, [][]float64{mid, up, low}
+	case NameMACD:
+		macd, sig, hist := MACD(values, p.Fast, p.Slow, p.Signal)
+		lines, cols = []string{"macd", "signal", "histogram"}, [][]float64{macd, sig, hist}
+	}
+
+	s := &Series{Name: name, Params: p, Lines: lines}
+	for i, t := range times {
+		row := make([]float64, len(cols))
+		defined := true
+		for j, col := range cols {
+			row[j] = col[i]
+			if math.IsNaN(row[j]) {
+				defined = false
+			}
+		}
+		if defined {
+			s.Points = append(s.Points, Point{Time: t, Values: row})
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,77 @@
+package indicators
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestComputeDropsWarmup(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := make([]time.Time, 60)
+	values := make([]float64, 60)
+	for i := range values {
+		times[i] = start.Add(time.Duration(i) * time.Hour)
+		values[i] = 100 + float64(i%7) - float64(i%3)
+	}
+
+	for _, name := range []string{NameSMA, NameEMA, NameRSI, NameBollinger, NameMACD} {
+		t.Run(name, func(t *testing.T) {
+			warmup, err := Warmup(name, Params{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := Compute(name, Params{}, times, values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := len(s.Points), len(values)-warmup; got != want {
+				t.Fatalf("points = %d, want %d", got, want)
+			}
+			if !s.Points[0].Time.Equal(times[warmup]) {
+				t.Errorf("first point at %s, want %s", s.Points[0].Time, times[warmup])
+			}
+			if len(s.Points[0].Values) != len(s.Lines) {
+				t.Errorf("values = %d, lines = %d", len(s.Points[0].Values), len(s.Lines))
+			}
+
+			// Fewer points than the warm-up leaves nothing defined
+			short, err := Compute(name, Params{}, times[:warmup], values[:warmup])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(short.Points) != 0 {
+				t.Errorf("short window: %d points, want none", len(short.Points))
+			}
+		})
+	}
+}
+
+func TestParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		params Params
+		warmup int
+		err    error
+	}{
+		{NameSMA, Params{}, 19, nil},
+		{NameRSI, Params{}, 14, nil},
+		{NameMACD, Params{}, 33, nil},
+		{NameEMA, Params{Period: 5}, 4, nil},
+		{"MACD", Params{Fast: 5, Slow: 10, Signal: 3}, 11, nil},
+		{NameMACD, Params{Fast: 26, Slow: 12}, 0, ErrInvalidParams},
+		{NameSMA, Params{Period: -1}, 0, ErrInvalidParams},
+		{NameBollinger, Params{K: -2}, 0, ErrInvalidParams},
+		{"vwap", Params{}, 0, ErrUnknownIndicator},
+	}
+	for _, tt := range tests {
+		warmup, err := Warmup(tt.name, tt.params)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Warmup(%s, %+v) error = %v, want %v", tt.name, tt.params, err, tt.err)
+			continue
+		}
+		if warmup != tt.warmup {
+			t.Errorf("Warmup(%s, %+v) = %d, want %d", tt.name, tt.params, warmup, tt.warmup)
+		}
+	}
+}
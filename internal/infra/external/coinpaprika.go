@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Neroframe/crypto-tracker/internal/app"
+	"github.com/Neroframe/crypto-tracker/internal/domain"
 	"github.com/Neroframe/crypto-tracker/pkg/logger"
+	"github.com/shopspring/decimal"
 	"golang.org/x/time/rate"
 )
 
@@ -17,49 +20,75 @@ type CoinPaprikaClient struct {
 	httpClient *http.Client
 	limiter    *rate.Limiter
 	baseURL    string
-	idMap      map[string]string // e.g. "BTC" -> "btc-bitcoin"
+	quotes     []string              // quote currencies requested with every ticker
+	catalog    []app.Coin            // active coins, best ranked first
+	coins      map[string]app.Coin   // active coins by ID, e.g. "btc-bitcoin"
+	symbols    map[string][]app.Coin // active coins by uppercase symbol, best ranked first
 	log        *logger.Logger
 }
 
-// ApiKey empty for free tier usage
+// ApiKey empty for free tier usage. Empty quotes default to USD only
 func NewCoinPaprikaClient(
 	httpClient *http.Client,
 	baseURL string,
 	rateLimit float64,
+	quotes []string,
 	log *logger.Logger,
 ) (*CoinPaprikaClient, error) {
 	if httpClient == nil {
 		httpClient = &http.Client{Timeout: 5 * time.Second}
 	}
+	if len(quotes) == 0 {
+		quotes = []string{"USD"}
+	}
+	upper := make([]string, len(quotes))
+	for i, q := range quotes {
+		upper[i] = strings.ToUpper(strings.TrimSpace(q))
+	}
 	c := &CoinPaprikaClient{
 		httpClient: httpClient,
 		limiter:    rate.NewLimiter(rate.Limit(rateLimit), 1),
 		baseURL:    strings.TrimSuffix(baseURL, "/"),
-		idMap:      make(map[string]string),
+		quotes:     upper,
+		coins:      make(map[string]app.Coin),
+		symbols:    make(map[string][]app.Coin),
 		log:        log,
 	}
-	if err := c.populateIDMap(context.Background()); err != nil {
-		return nil, fmt.Errorf("populate CoinPaprika ID map: %w", err)
+	if err := c.populateCatalog(context.Background()); err != nil {
+		return nil, fmt.Errorf("populate CoinPaprika catalog: %w", err)
 	}
 	return c, nil
 }
 
-func (c *CoinPaprikaClient) SymbolExists(symbol string) (bool, error) {
-	_, ok := c.idMap[strings.ToUpper(symbol)]
-	return ok, nil
+func (c *CoinPaprikaClient) CoinsBySymbol(symbol string) ([]app.Coin, error) {
+	return slices.Clone(c.symbols[strings.ToUpper(symbol)]), nil
 }
 
-func (c *CoinPaprikaClient) FetchPrice(ctx context.Context, symbol string) (*app.PricePoint, error) {
+func (c *CoinPaprikaClient) CoinByID(id string) (*app.Coin, error) {
+	coin, ok := c.coins[strings.ToLower(id)]
+	if !ok {
+		return nil, app.ErrUnknownCoin
+	}
+	return &coin, nil
+}
+
+func (c *CoinPaprikaClient) ListCoins() ([]app.Coin, error) {
+	return c.catalog, nil
+}
+
+func (c *CoinPaprikaClient) FetchPrice(ctx context.Context, id string) (*app.PricePoint, error) {
 	if err := c.limiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("%w: rate limit: %v", ErrExternalAPI, err)
 	}
 
-	id, ok := c.idMap[strings.ToUpper(symbol)]
+	coin, ok := c.coins[strings.ToLower(id)]
 	if !ok {
-		return nil, ErrUnknownSymbol
+		return nil, app.ErrUnknownCoin
 	}
+	symbol := coin.Symbol
 
-	url := fmt.Sprintf("%s/v1/tickers/%s", c.baseURL, id)
+	// All configured quotes come back in a single response
+	url := fmt.Sprintf("%s/v1/tickers/%s?quotes=%s", c.baseURL, coin.ID, strings.Join(c.quotes, ","))
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%w: new request: %v", ErrExternalAPI, err)
@@ -76,8 +105,16 @@ func (c *CoinPaprikaClient) FetchPrice(ctx context.Context, symbol string) (*app
 	}
 
 	var payload struct {
-		Quotes map[string]struct {
-			Price float64 `json:"price"`
+		Rank              *int      `json:"rank"`
+		LastUpdated       time.Time `json:"last_updated"`
+		CirculatingSupply *float64  `json:"circulating_supply"`
+		Quotes            map[string]struct {
+			Price            decimal.Decimal `json:"price"` // decoded from the raw number, no float rounding
+			Volume24h        *float64        `json:"volume_24h"`
+			MarketCap        *float64        `json:"market_cap"`
+			PercentChange1h  *float64        `json:"percent_change_1h"`
+			PercentChange24h *float64        `json:"percent_change_24h"`
+			PercentChange7d  *float64        `json:"percent_change_7d"`
 		} `json:"quotes"`
 	}
 	dec := json.NewDecoder(resp.Body)
@@ -85,62 +122,109 @@ func (c *CoinPaprikaClient) FetchPrice(ctx context.Context, symbol string) (*app
 		return nil, fmt.Errorf("%w: decode JSON: %v", ErrExternalAPI, err)
 	}
 
-	usdQ, ok := payload.Quotes["USD"]
-	if !ok {
-		return nil, fmt.Errorf("%w: no USD quote for %s", ErrExternalAPI, symbol)
+	quotes := make(map[string]app.Quote, len(c.quotes))
+	for _, q := range c.quotes {
+		pq, ok := payload.Quotes[q]
+		if !ok {
+			c.log.Warn("quote missing in ticker", "symbol", symbol, "quote", q)
+			continue
+		}
+		quotes[q] = app.Quote{
+			Price: pq.Price,
+			Market: domain.MarketData{
+				Volume24h:         pq.Volume24h,
+				MarketCap:         pq.MarketCap,
+				PercentChange1h:   pq.PercentChange1h,
+				PercentChange24h:  pq.PercentChange24h,
+				PercentChange7d:   pq.PercentChange7d,
+				CirculatingSupply: payload.CirculatingSupply,
+				Rank:              payload.Rank,
+			},
+		}
+	}
+	if len(quotes) == 0 {
+		return nil, fmt.Errorf("%w: no quotes %v for %s", ErrExternalAPI, c.quotes, symbol)
+	}
+
+	// The ticker may be cached upstream, so the observation time comes from
+	// last_updated. Fall back to the fetch time if it's missing or ahead of our clock
+	fetchedAt := time.Now().UTC()
+	observedAt := payload.LastUpdated
+	if observedAt.IsZero() || observedAt.After(fetchedAt) {
+		observedAt = fetchedAt
 	}
 
-	// return PricePoint
 	return &app.PricePoint{
 		Symbol:    symbol,
-		Price:     usdQ.Price,
-		Timestamp: time.Now().UTC().Unix(),
+		Quotes:    quotes,
+		Timestamp: observedAt.Unix(),
+		FetchedAt: fetchedAt.Unix(),
 	}, nil
 }
 
-// Calls the CoinPaprika /v1/coins endpoint and builds
-// a map from uppercase symbol "BTC" to CoinPaprika ID "btc-bitcoin"
-func (c *CoinPaprikaClient) populateIDMap(ctx context.Context) error {
+// Calls the CoinPaprika /v1/coins endpoint and indexes the active coins and tokens
+// by ID and by uppercase symbol. Several coins often share a symbol
+func (c *CoinPaprikaClient) populateCatalog(ctx context.Context) error {
 	url := fmt.Sprintf("%s/v1/coins", c.baseURL)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("populateIDMap: create request: %w", err)
+		return fmt.Errorf("populateCatalog: create request: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("populateIDMap: request failed: %w", err)
+		return fmt.Errorf("populateCatalog: request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("populateIDMap: bad status %d", resp.StatusCode)
+		return fmt.Errorf("populateCatalog: bad status %d", resp.StatusCode)
 	}
 
 	var coins []struct {
 		ID       string `json:"id"`
+		Name     string `json:"name"`
 		Symbol   string `json:"symbol"`
+		Rank     int    `json:"rank"`
 		Type     string `json:"type"`
 		IsActive bool   `json:"is_active"`
 	}
 	dec := json.NewDecoder(resp.Body)
 	if err := dec.Decode(&coins); err != nil {
-		return fmt.Errorf("populateIDMap: decode JSON: %w", err)
+		return fmt.Errorf("populateCatalog: decode JSON: %w", err)
 	}
 
-	// Fill the map only with active coins
 	for _, coin := range coins {
-		if coin.Type == "coin" && coin.IsActive {
-			sym := strings.ToUpper(coin.Symbol)
-			// Only set if not already present
-			if _, exists := c.idMap[sym]; !exists {
-				c.idMap[sym] = coin.ID
-			}
+		if !coin.IsActive || (coin.Type != "coin" && coin.Type != "token") {
+			continue
 		}
+		sym := strings.ToUpper(coin.Symbol)
+		entry := app.Coin{ID: coin.ID, Symbol: sym, Name: coin.Name, Rank: coin.Rank, Type: coin.Type}
+		c.catalog = append(c.catalog, entry)
+		c.coins[coin.ID] = entry
+		c.symbols[sym] = append(c.symbols[sym], entry)
+	}
+	slices.SortStableFunc(c.catalog, byRank)
+	for _, list := range c.symbols {
+		slices.SortStableFunc(list, byRank)
 	}
 
-	c.log.Debug("populateIDMap sample", "BTC", c.idMap["BTC"])
-	c.log.Info("populateIDMap: loaded symbol map", "count", len(c.idMap))
+	c.log.Debug("populateCatalog sample", "BTC", c.symbols["BTC"])
+	c.log.Info("populateCatalog: loaded coin catalog", "coins", len(c.coins), "symbols", len(c.symbols))
 	return nil
 }
+
+// Ranked coins first by rank, unranked ones after them
+func byRank(a, b app.Coin) int {
+	switch {
+	case a.Rank == b.Rank:
+		return 0
+	case a.Rank == 0:
+		return 1
+	case b.Rank == 0:
+		return -1
+	default:
+		return a.Rank - b.Rank
+	}
+}
@@ -0,0 +1,106 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/app"
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/shopspring/decimal"
+)
+
+// Largest page of the historical ticks endpoint
+const historyPageLimit = 5000
+
+// Tick spacings accepted by /v1/tickers/{id}/historical
+var historyIntervals = map[time.Duration]string{
+	5 * time.Minute:  "5m",
+	10 * time.Minute: "10m",
+	15 * time.Minute: "15m",
+	30 * time.Minute: "30m",
+	45 * time.Minute: "45m",
+	time.Hour:        "1h",
+	2 * time.Hour:    "2h",
+	3 * time.Hour:    "3h",
+	6 * time.Hour:    "6h",
+	12 * time.Hour:   "12h",
+	24 * time.Hour:   "1d",
+}
+
+// Returns one page of historical ticks starting at `start`, ordered by time.
+// Callers page by asking again right after the last returned tick
+func (c *CoinPaprikaClient) FetchHistory(
+	ctx context.Context,
+	id, quote string,
+	start, end time.Time,
+	interval time.Duration,
+) ([]app.HistoricalTick, error) {
+	step, ok := historyIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported history interval %s", ErrExternalAPI, interval)
+	}
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("%w: rate limit: %v", ErrExternalAPI, err)
+	}
+
+	coin, ok := c.coins[strings.ToLower(id)]
+	if !ok {
+		return nil, app.ErrUnknownCoin
+	}
+
+	params := url.Values{}
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("interval", step)
+	params.Set("limit", strconv.Itoa(historyPageLimit))
+	params.Set("quote", strings.ToLower(quote))
+	reqURL := fmt.Sprintf("%s/v1/tickers/%s/historical?%s", c.baseURL, coin.ID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: new request: %v", ErrExternalAPI, err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: request failed: %v", ErrExternalAPI, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusTooManyRequests:
+		return nil, ErrExternalRateLimit
+	default:
+		return nil, fmt.Errorf("%w: status %d", ErrExternalAPI, resp.StatusCode)
+	}
+
+	var payload []struct {
+		Timestamp time.Time       `json:"timestamp"`
+		Price     decimal.Decimal `json:"price"`
+		Volume24h *float64        `json:"volume_24h"`
+		MarketCap *float64        `json:"market_cap"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("%w: decode JSON: %v", ErrExternalAPI, err)
+	}
+
+	ticks := make([]app.HistoricalTick, len(payload))
+	for i, p := range payload {
+		ticks[i] = app.HistoricalTick{
+			Timestamp: p.Timestamp.UTC(),
+			Price:     p.Price,
+			Market: domain.MarketData{
+				Volume24h: p.Volume24h,
+				MarketCap: p.MarketCap,
+			},
+		}
+	}
+	return ticks, nil
+}
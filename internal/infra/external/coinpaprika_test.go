@@ -0,0 +1,56 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+)
+
+// Serves a one coin catalog and its ticker, recording the requested paths
+func newPaprikaServer(t *testing.T, paths *[]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/coins", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":"btc-bitcoin","name":"Bitcoin","symbol":"BTC","rank":1,"type":"coin","is_active":true}]`)
+	})
+	mux.HandleFunc("/v1/tickers/", func(w http.ResponseWriter, r *http.Request) {
+		*paths = append(*paths, r.URL.Path)
+		fmt.Fprint(w, `{"rank":1,"quotes":{"USD":{"price":97123.45}}}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestFetchPriceCoinIDCase(t *testing.T) {
+	var paths []string
+	srv := newPaprikaServer(t, &paths)
+	c, err := NewCoinPaprikaClient(srv.Client(), srv.URL, 100, nil, logger.New(logger.Config{Level: "error"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"btc-bitcoin", "BTC-Bitcoin"} {
+		p, err := c.FetchPrice(context.Background(), id)
+		if err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		if p.Symbol != "BTC" || p.Quotes["USD"].Price.String() != "97123.45" {
+			t.Errorf("%s: price = %+v", id, p)
+		}
+	}
+	if len(paths) != 2 || paths[0] != "/v1/tickers/btc-bitcoin" || paths[1] != paths[0] {
+		t.Errorf("requested %v, want the lowercase coin ID twice", paths)
+	}
+
+	// The ticker body is no history page, only the requested path matters
+	c.FetchHistory(context.Background(), "BTC-BITCOIN", "USD", time.Now().Add(-time.Hour), time.Now(), time.Hour)
+	if len(paths) != 3 || paths[2] != "/v1/tickers/btc-bitcoin/historical" {
+		t.Errorf("requested %v, want the lowercase coin ID in the history path", paths)
+	}
+}
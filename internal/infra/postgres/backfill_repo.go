@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BackfillJobModel struct {
+	ID              uuid.UUID `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid()"`
+	CurrencyID      uuid.UUID `gorm:"column:currency_id;type:uuid;not null"`
+	Quote           string    `gorm:"column:quote;type:varchar(10);not null"`
+	StartTs         int64     `gorm:"column:start_ts;not null"`
+	EndTs           int64     `gorm:"column:end_ts;not null"`
+	IntervalSeconds int64     `gorm:"column:interval_seconds;not null"`
+	CursorTs        int64     `gorm:"column:cursor_ts;not null"`
+	Status          string    `gorm:"column:status;type:varchar(16);not null"`
+	Inserted        int64     `gorm:"column:inserted;not null"`
+	Error           string    `gorm:"column:error;not null"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (BackfillJobModel) TableName() string {
+	return "backfill_jobs"
+}
+
+// Job joined with the symbol and provider ID of its currency
+type backfillJobRow struct {
+	BackfillJobModel
+	Symbol     string
+	ProviderID string
+}
+
+func (r backfillJobRow) toDomain() *domain.BackfillJob {
+	return &domain.BackfillJob{
+		ID:         r.ID,
+		CurrencyID: r.CurrencyID,
+		Symbol:     r.Symbol,
+		ProviderID: r.ProviderID,
+		Quote:      r.Quote,
+		Start:      time.Unix(r.StartTs, 0).UTC(),
+		End:        time.Unix(r.EndTs, 0).UTC(),
+		Interval:   time.Duration(r.IntervalSeconds) * time.Second,
+		Cursor:     time.Unix(r.CursorTs, 0).UTC(),
+		Status:     domain.BackfillStatus(r.Status),
+		Inserted:   r.Inserted,
+		Error:      r.Error,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+func newBackfillJobModel(job *domain.BackfillJob) BackfillJobModel {
+	return BackfillJobModel{
+		ID:              job.ID,
+		CurrencyID:      job.CurrencyID,
+		Quote:           job.Quote,
+		StartTs:         job.Start.Unix(),
+		EndTs:           job.End.Unix(),
+		IntervalSeconds: int64(job.Interval / time.Second),
+		CursorTs:        job.Cursor.Unix(),
+		Status:          string(job.Status),
+		Inserted:        job.Inserted,
+		Error:           job.Error,
+		CreatedAt:       job.CreatedAt,
+		UpdatedAt:       job.UpdatedAt,
+	}
+}
+
+func (r *GormRepo) CreateBackfillJob(ctx context.Context, job *domain.BackfillJob) error {
+	model := newBackfillJobModel(job)
+	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
+		return fmt.Errorf("gorm CreateBackfillJob: %w", err)
+	}
+	return nil
+}
+
+// Saves the progress of a job: cursor, status, counters and error
+func (r *GormRepo) UpdateBackfillJob(ctx context.Context, job *domain.BackfillJob) error {
+	res := r.db.WithContext(ctx).
+		Model(&BackfillJobModel{ID: job.ID}).
+		Updates(map[string]interface{}{
+			"cursor_ts":  job.Cursor.Unix(),
+			"status":     string(job.Status),
+			"inserted":   job.Inserted,
+			"error":      job.Error,
+			"updated_at": time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("gorm UpdateBackfillJob: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrBackfillNotFound
+	}
+	return nil
+}
+
+func (r *GormRepo) GetBackfillJob(ctx context.Context, id uuid.UUID) (*domain.BackfillJob, error) {
+	var row backfillJobRow
+	err := r.backfillJobs(ctx).Where("b.id = ?", id).Take(&row).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrBackfillNotFound
+		}
+		return nil, fmt.Errorf("gorm GetBackfillJob: %w", err)
+	}
+	return row.toDomain(), nil
+}
+
+// Pending and running jobs, oldest first. Running ones were interrupted by a restart
+func (r *GormRepo) ListUnfinishedBackfillJobs(ctx context.Context) ([]*domain.BackfillJob, error) {
+	var rows []backfillJobRow
+	err := r.backfillJobs(ctx).
+		Where("b.status IN ?", []string{string(domain.BackfillPending), string(domain.BackfillRunning)}).
+		Order("b.created_at ASC").
+		Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm ListUnfinishedBackfillJobs: %w", err)
+	}
+
+	jobs := make([]*domain.BackfillJob, len(rows))
+	for i, row := range rows {
+		jobs[i] = row.toDomain()
+	}
+	return jobs, nil
+}
+
+func (r *GormRepo) backfillJobs(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Table("backfill_jobs b").
+		Select("b.*, c.symbol, c.provider_id").
+		Joins("JOIN currencies c ON c.id = b.currency_id")
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Neroframe/crypto-tracker/internal/domain"
@@ -11,8 +12,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Rows per INSERT of bulk writes, 15 columns each stays well below the bind parameter limit
+const saveBatchSize = 1000
+
 type GormRepo struct {
 	db     *gorm.DB
 	logger *logger.Logger
@@ -26,11 +31,14 @@ func (r *GormRepo) AddCurrency(ctx context.Context, c *domain.Currency) error {
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// Rely on autoCreateTime/autoUpdateTime tags
 		model := CurrencyModel{
-			ID:     c.ID,
-			Symbol: c.Symbol,
+			ID:         c.ID,
+			Symbol:     c.Symbol,
+			ProviderID: c.ProviderID,
+			Status:     string(c.Status),
 		}
 		if err := tx.Create(&model).Error; err != nil {
 			var pgErr *pgconn.PgError
+			// Only the provider ID is unique, other currencies may share the symbol
 			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 				return domain.ErrDuplicateCurrency
 			}
@@ -46,92 +54,97 @@ func (r *GormRepo) AddCurrency(ctx context.Context, c *domain.Currency) error {
 	return nil
 }
 
-func (r *GormRepo) RemoveCurrency(ctx context.Context, symbol string) error {
-	res := r.db.WithContext(ctx).
-		Where("symbol = ?", symbol).
-		Delete(&CurrencyModel{})
-	if res.Error != nil {
-		return res.Error
+// Matches a currency by provider coin ID or by symbol, whatever the case of the reference.
+// Takes the reference twice
+const currencyRefMatch = "provider_id = lower(?) OR symbol = upper(?)"
+
+func (r *GormRepo) GetCurrency(ctx context.Context, ref string) (*domain.Currency, error) {
+	var rows []CurrencyModel
+	if err := r.db.WithContext(ctx).Where(currencyRefMatch, ref, ref).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("gorm GetCurrency: %w", err)
 	}
-	if res.RowsAffected == 0 {
-		return domain.ErrNotTracked
+	// An ID match is unique, a symbol may match several
+	for _, cm := range rows {
+		if strings.EqualFold(cm.ProviderID, ref) {
+			return cm.toDomain(), nil
+		}
+	}
+	switch len(rows) {
+	case 0:
+		return nil, domain.ErrNotTracked
+	case 1:
+		return rows[0].toDomain(), nil
+	default:
+		return nil, domain.ErrAmbiguousCurrency
 	}
-	return nil
 }
 
-// Finds the nearest price with closest to `ts` (before or after)
-func (r *GormRepo) GetPriceSnapshot(ctx context.Context, symbol string, ts time.Time) (*domain.PriceSnapshot, error) {
-	var cm CurrencyModel
+// Returns the tracked currencies named by `refs`, every one sharing a symbol in the list.
+// Unknown references are left out
+func (r *GormRepo) GetCurrencies(ctx context.Context, refs []string) ([]*domain.Currency, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(refs))
+	symbols := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = strings.ToLower(ref)
+		symbols[i] = strings.ToUpper(ref)
+	}
+	var rows []CurrencyModel
 	if err := r.db.WithContext(ctx).
-		Where("symbol = ?", symbol).
-		First(&cm).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, domain.ErrNotTracked
-		}
-		return nil, fmt.Errorf("gorm GetPriceSnapshot (currency lookup): %w", err)
+		Where("provider_id IN ? OR symbol IN ?", ids, symbols).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("gorm GetCurrencies: %w", err)
 	}
+	currs := make([]*domain.Currency, len(rows))
+	for i, cm := range rows {
+		currs[i] = cm.toDomain()
+	}
+	return currs, nil
+}
 
-	tsUnix := ts.Unix()
+// Saves the settings of a currency, its symbol and creation time never change.
+// Any change withdraws a pending purge token
+func (r *GormRepo) UpdateCurrency(ctx context.Context, c *domain.Currency) error {
+	updates := map[string]interface{}{
+		"provider_id":            c.ProviderID,
+		"status":                 string(c.Status),
+		"fetch_interval_seconds": nil,
+		"priority":               c.Schedule.Priority,
+		"deadband_percent":       nil,
+		"heartbeat_seconds":      nil,
+		"purge_token":            nil,
+		"purge_token_expires_at": nil,
+		"updated_at":             time.Now().UTC(),
+	}
+	if c.Schedule.Interval > 0 {
+		updates["fetch_interval_seconds"] = int64(c.Schedule.Interval / time.Second)
+	}
+	if c.Deadband != nil {
+		updates["deadband_percent"] = c.Deadband.ThresholdPercent
+		updates["heartbeat_seconds"] = int64(c.Deadband.Heartbeat / time.Second)
+	}
 
-	// Try exact match
-	var exact PriceSnapshotModel
-	if err := r.db.WithContext(ctx).
-		Where("currency_id = ? AND timestamp = ?", cm.ID, tsUnix).
-		First(&exact).Error; err == nil {
-		return &domain.PriceSnapshot{
-			ID:         exact.ID,
-			CurrencyID: exact.CurrencyID,
-			Timestamp:  time.Unix(exact.Timestamp, 0).UTC(),
-			Price:      exact.Price,
-			CreatedAt:  exact.CreatedAt,
-		}, nil
-	}
-
-	// Fallback to nearest older, newer
-	var older, newer PriceSnapshotModel
-	errOlder := r.db.WithContext(ctx).
-		Where("currency_id = ? AND timestamp < ?", cm.ID, tsUnix).
-		Order("timestamp DESC").Limit(1).First(&older).Error
-
-	errNewer := r.db.WithContext(ctx).
-		Where("currency_id = ? AND timestamp > ?", cm.ID, tsUnix).
-		Order("timestamp ASC").Limit(1).First(&newer).Error
-
-	if errors.Is(errOlder, gorm.ErrRecordNotFound) && errors.Is(errNewer, gorm.ErrRecordNotFound) {
-		return nil, domain.ErrPriceNotFound
-	}
-
-	var chosen PriceSnapshotModel
-	switch {
-	case errOlder == nil && errNewer == nil:
-		if (tsUnix - older.Timestamp) <= (newer.Timestamp - tsUnix) {
-			chosen = older
-		} else {
-			chosen = newer
+	res := r.db.WithContext(ctx).
+		Model(&CurrencyModel{}).
+		Where("id = ?", c.ID).
+		Updates(updates)
+	if res.Error != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(res.Error, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrDuplicateCurrency // the provider ID belongs to another currency
 		}
-	case errOlder == nil:
-		chosen = older
-	case errNewer == nil:
-		chosen = newer
-	}
-
-	return &domain.PriceSnapshot{
-		ID:         chosen.ID,
-		CurrencyID: chosen.CurrencyID,
-		Timestamp:  time.Unix(chosen.Timestamp, 0).UTC(),
-		Price:      chosen.Price,
-		CreatedAt:  chosen.CreatedAt,
-	}, nil
+		return fmt.Errorf("gorm UpdateCurrency: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotTracked
+	}
+	return nil
 }
 
 func (r *GormRepo) SavePriceSnapshot(ctx context.Context, snap *domain.PriceSnapshot) error {
-	model := PriceSnapshotModel{
-		ID:         snap.ID,
-		CurrencyID: snap.CurrencyID,
-		Timestamp:  snap.Timestamp.Unix(),
-		Price:      snap.Price,
-		CreatedAt:  snap.CreatedAt,
-	}
+	model := newPriceSnapshotModel(snap)
 
 	err := r.db.WithContext(ctx).Create(&model).Error
 	if err != nil {
@@ -145,31 +158,136 @@ func (r *GormRepo) SavePriceSnapshot(ctx context.Context, snap *domain.PriceSnap
 	return nil
 }
 
-func (r *GormRepo) ListPriceSnapshots(ctx context.Context, currencyID uuid.UUID, start, end time.Time) ([]*domain.PriceSnapshot, error) {
+// Inserts snapshots in batches, conflicts on (currency_id, quote, timestamp) follow `onConflict`.
+// Returns the number of rows written, with ConflictFail nothing is written on a conflict.
+// A single call must not contain the same key twice
+func (r *GormRepo) SavePriceSnapshots(ctx context.Context, snaps []*domain.PriceSnapshot, onConflict domain.ConflictPolicy) (int64, error) {
+	if len(snaps) == 0 {
+		return 0, nil
+	}
+	models := make([]PriceSnapshotModel, len(snaps))
+	for i, snap := range snaps {
+		models[i] = newPriceSnapshotModel(snap)
+	}
+
+	var written int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		switch onConflict {
+		case domain.ConflictSkip:
+			tx = tx.Clauses(clause.OnConflict{DoNothing: true})
+		case domain.ConflictOverwrite:
+			tx = tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "currency_id"}, {Name: "quote"}, {Name: "timestamp"}},
+				DoUpdates: clause.AssignmentColumns([]string{
+					"price", "fetched_at", "volume_24h", "market_cap", "percent_change_1h",
+					"percent_change_24h", "percent_change_7d", "circulating_supply", "rank",
+				}),
+			})
+		}
+		res := tx.CreateInBatches(&models, saveBatchSize)
+		written = res.RowsAffected
+		return res.Error
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return 0, domain.ErrDuplicatePrice
+		}
+		return 0, fmt.Errorf("gorm SavePriceSnapshots: %w", err)
+	}
+	return written, nil
+}
+
+// Returns snapshots in [start, end] ordered by timestamp, at most `limit` rows when limit > 0
+func (r *GormRepo) ListPriceSnapshots(ctx context.Context, currencyID uuid.UUID, quote string, start, end time.Time, limit int) ([]*domain.PriceSnapshot, error) {
 	var rows []PriceSnapshotModel
 
-	err := r.db.WithContext(ctx).
-		Where("currency_id = ? AND timestamp BETWEEN ? AND ?", currencyID, start.Unix(), end.Unix()).
-		Order("timestamp ASC").
-		Find(&rows).Error
+	q := r.db.WithContext(ctx).
+		Where("currency_id = ? AND quote = ? AND timestamp BETWEEN ? AND ?", currencyID, quote, start.Unix(), end.Unix()).
+		Order("timestamp ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
 
-	if err != nil {
+	if err := q.Find(&rows).Error; err != nil {
 		return nil, fmt.Errorf("gorm ListPriceSnapshots: %w", err)
 	}
 
 	snaps := make([]*domain.PriceSnapshot, len(rows))
 	for i, pm := range rows {
-		snaps[i] = &domain.PriceSnapshot{
-			ID:         pm.ID,
-			CurrencyID: pm.CurrencyID,
-			Timestamp:  time.Unix(pm.Timestamp, 0).UTC(),
-			Price:      pm.Price,
-			CreatedAt:  pm.CreatedAt,
-		}
+		snaps[i] = pm.toDomain()
 	}
 	return snaps, nil
 }
 
+// Calls fn for every snapshot in [start, end] ordered by timestamp. Rows are scanned one at a time
+// from the open result set, so the range is never held in memory. An error from fn stops the scan
+func (r *GormRepo) StreamPriceSnapshots(
+	ctx context.Context,
+	currencyID uuid.UUID,
+	quote string,
+	start, end time.Time,
+	fn func(*domain.PriceSnapshot) error,
+) error {
+	rows, err := r.db.WithContext(ctx).
+		Model(&PriceSnapshotModel{}).
+		Where("currency_id = ? AND quote = ? AND timestamp BETWEEN ? AND ?", currencyID, quote, start.Unix(), end.Unix()).
+		Order("timestamp ASC").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("gorm StreamPriceSnapshots: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pm PriceSnapshotModel
+		if err := r.db.ScanRows(rows, &pm); err != nil {
+			return fmt.Errorf("gorm StreamPriceSnapshots: %w", err)
+		}
+		if err := fn(pm.toDomain()); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("gorm StreamPriceSnapshots: %w", err)
+	}
+	return nil
+}
+
+// Aggregates OHLC candles of raw snapshots in Postgres, buckets are aligned to the Unix epoch.
+// Ranges already pruned into rollups are merged from them by the service
+func (r *GormRepo) ListCandles(ctx context.Context, currencyID uuid.UUID, quote string, start, end time.Time, bucket time.Duration) ([]*domain.Candle, error) {
+	size := int64(bucket / time.Second)
+	if size <= 0 {
+		return nil, domain.ErrInvalidInterval
+	}
+
+	var rows []candleRow
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT (timestamp / ?) * ? AS bucket,
+		       (array_agg(price ORDER BY timestamp ASC))[1] AS open,
+		       MAX(price) AS high,
+		       MIN(price) AS low,
+		       (array_agg(price ORDER BY timestamp DESC))[1] AS close,
+		       MAX(timestamp) AS close_at,
+		       COUNT(*) AS count
+		FROM currency_prices
+		WHERE currency_id = ? AND quote = ? AND timestamp BETWEEN ? AND ?
+		GROUP BY bucket
+		ORDER BY bucket ASC`,
+		size, size, currencyID, quote, start.Unix(), end.Unix(),
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("gorm ListCandles: %w", err)
+	}
+
+	candles := make([]*domain.Candle, len(rows))
+	for i, row := range rows {
+		candles[i] = row.toDomain()
+	}
+	return candles, nil
+}
+
 func (r *GormRepo) ListCurrencies(ctx context.Context, limit, offset int) ([]*domain.Currency, error) {
 	if limit <= 0 || offset < 0 {
 		return nil, fmt.Errorf("invalid pagination params: limit=%d offset=%d", limit, offset)
@@ -186,13 +304,52 @@ func (r *GormRepo) ListCurrencies(ctx context.Context, limit, offset int) ([]*do
 
 	currs := make([]*domain.Currency, len(rows))
 	for i, cm := range rows {
-		currs[i] = &domain.Currency{
-			ID:        cm.ID,
-			Symbol:    cm.Symbol,
-			CreatedAt: cm.CreatedAt,
-			UpdatedAt: cm.UpdatedAt,
+		currs[i] = cm.toDomain()
+	}
+
+	return currs, nil
+}
+
+// Returns one page of currencies matching `q` in its sort order, starting after q.After
+func (r *GormRepo) FindCurrencies(ctx context.Context, q domain.CurrencyQuery) ([]*domain.Currency, error) {
+	if q.Limit <= 0 {
+		return nil, fmt.Errorf("invalid page size: limit=%d", q.Limit)
+	}
+
+	tx := r.db.WithContext(ctx).Limit(q.Limit)
+	if q.Prefix != "" {
+		// Symbols are alphanumeric, LIKE wildcards can't appear in the prefix
+		tx = tx.Where("symbol LIKE ?", q.Prefix+"%")
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", string(q.Status))
+	}
+
+	cmp, dir := ">", "ASC"
+	if q.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	switch q.Sort {
+	case domain.SortByCreated:
+		if q.After != nil {
+			tx = tx.Where("(created_at, id) "+cmp+" (?, ?)", q.After.CreatedAt, q.After.ID)
+		}
+		tx = tx.Order("created_at " + dir).Order("id " + dir)
+	default:
+		// Symbols are shared by several coins, the provider ID breaks ties
+		if q.After != nil {
+			tx = tx.Where("(symbol, provider_id) "+cmp+" (?, ?)", q.After.Symbol, q.After.ProviderID)
 		}
+		tx = tx.Order("symbol " + dir).Order("provider_id " + dir)
 	}
 
+	var rows []CurrencyModel
+	if err := tx.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("gorm FindCurrencies: %w", err)
+	}
+	currs := make([]*domain.Currency, len(rows))
+	for i, cm := range rows {
+		currs[i] = cm.toDomain()
+	}
 	return currs, nil
 }
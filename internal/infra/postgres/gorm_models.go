@@ -3,28 +3,174 @@ package postgres
 import (
 	"time"
 
+	"github.com/Neroframe/crypto-tracker/internal/domain"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 type CurrencyModel struct {
-	ID        uuid.UUID `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid()"`
-	Symbol    string    `gorm:"column:symbol;type:varchar(10);unique;not null"`
-	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+	ID         uuid.UUID `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid()"`
+	Symbol     string    `gorm:"column:symbol;type:varchar(10);not null"`
+	ProviderID string    `gorm:"column:provider_id;type:varchar(100);unique;not null"`
+	Status     string    `gorm:"column:status;type:varchar(16);not null;default:active"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoUpdateTime"`
+
+	// Fetch schedule, NULL interval uses the global one
+	FetchIntervalSeconds *int64 `gorm:"column:fetch_interval_seconds"`
+	Priority             int    `gorm:"column:priority;not null;default:0"`
+
+	// Deadband storage, both set or both NULL
+	DeadbandPercent  *float64 `gorm:"column:deadband_percent;type:numeric"`
+	HeartbeatSeconds *int64   `gorm:"column:heartbeat_seconds"`
 }
 
 func (CurrencyModel) TableName() string {
 	return "currencies"
 }
 
+func (m CurrencyModel) toDomain() *domain.Currency {
+	cur := &domain.Currency{
+		ID:         m.ID,
+		Symbol:     m.Symbol,
+		ProviderID: m.ProviderID,
+		Status:     domain.CurrencyStatus(m.Status),
+		Schedule:   domain.FetchSchedule{Priority: m.Priority},
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+	if m.FetchIntervalSeconds != nil {
+		cur.Schedule.Interval = time.Duration(*m.FetchIntervalSeconds) * time.Second
+	}
+	if m.DeadbandPercent != nil && m.HeartbeatSeconds != nil {
+		cur.Deadband = &domain.Deadband{
+			ThresholdPercent: *m.DeadbandPercent,
+			Heartbeat:        time.Duration(*m.HeartbeatSeconds) * time.Second,
+		}
+	}
+	return cur
+}
+
 type PriceSnapshotModel struct {
-	ID         uuid.UUID `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid()"`
-	CurrencyID uuid.UUID `gorm:"column:currency_id;type:uuid;not null"`
-	Timestamp  int64     `gorm:"column:timestamp;not null"`
-	Price      float64   `gorm:"column:price;type:numeric(20,10);not null"`
-	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	ID         uuid.UUID       `gorm:"column:id;type:uuid;primaryKey;default:gen_random_uuid()"`
+	CurrencyID uuid.UUID       `gorm:"column:currency_id;type:uuid;not null"`
+	Quote      string          `gorm:"column:quote;type:varchar(10);not null;default:USD"`
+	Timestamp  int64           `gorm:"column:timestamp;not null"`
+	Price      decimal.Decimal `gorm:"column:price;type:numeric(40,20);not null"`
+	FetchedAt  *time.Time      `gorm:"column:fetched_at"`
+	CreatedAt  time.Time       `gorm:"column:created_at;autoCreateTime"`
+
+	// Optional market data
+	Volume24h         *float64 `gorm:"column:volume_24h;type:numeric"`
+	MarketCap         *float64 `gorm:"column:market_cap;type:numeric"`
+	PercentChange1h   *float64 `gorm:"column:percent_change_1h"`
+	PercentChange24h  *float64 `gorm:"column:percent_change_24h"`
+	PercentChange7d   *float64 `gorm:"column:percent_change_7d"`
+	CirculatingSupply *float64 `gorm:"column:circulating_supply;type:numeric"`
+	Rank              *int     `gorm:"column:rank"`
 }
 
 func (PriceSnapshotModel) TableName() string {
 	return "currency_prices"
 }
+
+func (m PriceSnapshotModel) toDomain() *domain.PriceSnapshot {
+	snap := &domain.PriceSnapshot{
+		ID:         m.ID,
+		CurrencyID: m.CurrencyID,
+		Quote:      m.Quote,
+		Timestamp:  time.Unix(m.Timestamp, 0).UTC(),
+		Price:      m.Price,
+		Market: domain.MarketData{
+			Volume24h:         m.Volume24h,
+			MarketCap:         m.MarketCap,
+			PercentChange1h:   m.PercentChange1h,
+			PercentChange24h:  m.PercentChange24h,
+			PercentChange7d:   m.PercentChange7d,
+			CirculatingSupply: m.CirculatingSupply,
+			Rank:              m.Rank,
+		},
+		CreatedAt: m.CreatedAt,
+	}
+	if m.FetchedAt != nil {
+		snap.FetchedAt = m.FetchedAt.UTC()
+	}
+	return snap
+}
+
+func newPriceSnapshotModel(snap *domain.PriceSnapshot) PriceSnapshotModel {
+	m := PriceSnapshotModel{
+		ID:                snap.ID,
+		CurrencyID:        snap.CurrencyID,
+		Quote:             snap.Quote,
+		Timestamp:         snap.Timestamp.Unix(),
+		Price:             snap.Price,
+		CreatedAt:         snap.CreatedAt,
+		Volume24h:         snap.Market.Volume24h,
+		MarketCap:         snap.Market.MarketCap,
+		PercentChange1h:   snap.Market.PercentChange1h,
+		PercentChange24h:  snap.Market.PercentChange24h,
+		PercentChange7d:   snap.Market.PercentChange7d,
+		CirculatingSupply: snap.Market.CirculatingSupply,
+		Rank:              snap.Market.Rank,
+	}
+	if !snap.FetchedAt.IsZero() {
+		fetchedAt := snap.FetchedAt
+		m.FetchedAt = &fetchedAt
+	}
+	return m
+}
+
+// Result row of the candle aggregation query
+type candleRow struct {
+	Bucket  int64
+	Open    decimal.Decimal
+	High    decimal.Decimal
+	Low     decimal.Decimal
+	Close   decimal.Decimal
+	CloseAt int64
+	Count   int
+}
+
+func (r candleRow) toDomain() *domain.Candle {
+	return &domain.Candle{
+		Start:   time.Unix(r.Bucket, 0).UTC(),
+		Open:    r.Open,
+		High:    r.High,
+		Low:     r.Low,
+		Close:   r.Close,
+		CloseAt: time.Unix(r.CloseAt, 0).UTC(),
+		Count:   r.Count,
+	}
+}
+
+// Downsampled OHLC bucket of one retention tier
+type PriceRollupModel struct {
+	CurrencyID uuid.UUID       `gorm:"column:currency_id;type:uuid;primaryKey"`
+	Quote      string          `gorm:"column:quote;type:varchar(10);primaryKey"`
+	Resolution int64           `gorm:"column:resolution;primaryKey"` // bucket size in seconds
+	Bucket     int64           `gorm:"column:bucket;primaryKey"`     // bucket start, Unix seconds
+	Open       decimal.Decimal `gorm:"column:open;type:numeric(40,20);not null"`
+	High       decimal.Decimal `gorm:"column:high;type:numeric(40,20);not null"`
+	Low        decimal.Decimal `gorm:"column:low;type:numeric(40,20);not null"`
+	Close      decimal.Decimal `gorm:"column:close;type:numeric(40,20);not null"`
+	CloseAt    int64           `gorm:"column:close_at;not null"`
+	Count      int             `gorm:"column:count;not null"`
+	UpdatedAt  time.Time       `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (PriceRollupModel) TableName() string {
+	return "currency_price_rollups"
+}
+
+func (m PriceRollupModel) toDomain() *domain.Candle {
+	return &domain.Candle{
+		Start:   time.Unix(m.Bucket, 0).UTC(),
+		Open:    m.Open,
+		High:    m.High,
+		Low:     m.Low,
+		Close:   m.Close,
+		CloseAt: time.Unix(m.CloseAt, 0).UTC(),
+		Count:   m.Count,
+	}
+}
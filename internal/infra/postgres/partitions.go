@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"gorm.io/gorm"
+)
+
+// Monthly partitions of currency_prices are named after their UTC month, e.g. currency_prices_p202508
+const (
+	pricePartitionPrefix  = "currency_prices_p"
+	pricePartitionDefault = "currency_prices_default"
+)
+
+// Month covered by one partition
+type pricePartition struct {
+	name  string
+	start time.Time // inclusive
+	end   time.Time // exclusive
+}
+
+func newPricePartition(month time.Time) pricePartition {
+	start := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return pricePartition{
+		name:  pricePartitionPrefix + start.Format("200601"),
+		start: start,
+		end:   start.AddDate(0, 1, 0),
+	}
+}
+
+// Creates the missing monthly partitions between the months of `from` and `to`. Rows of the month
+// already caught by the default partition are moved into it. Nothing is created when a month of the
+// range was detached, its archived rows are only brought back by AttachPricePartition
+func (r *GormRepo) EnsurePricePartitions(ctx context.Context, from, to time.Time) ([]string, error) {
+	existing, err := r.listPricePartitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm EnsurePricePartitions: %w", err)
+	}
+	have := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		have[p.name] = true
+	}
+
+	var missing []pricePartition
+	for p := newPricePartition(from.UTC()); !p.start.After(to); p = newPricePartition(p.end) {
+		if have[p.name] {
+			continue
+		}
+		detached, err := r.tableExists(ctx, p.name)
+		if err != nil {
+			return nil, fmt.Errorf("gorm EnsurePricePartitions: %s: %w", p.name, err)
+		}
+		if detached {
+			return nil, fmt.Errorf("gorm EnsurePricePartitions: %s: %w", p.name, domain.ErrPriceMonthArchived)
+		}
+		missing = append(missing, p)
+	}
+
+	var created []string
+	for _, p := range missing {
+		if err := r.createPricePartition(ctx, p); err != nil {
+			return created, fmt.Errorf("gorm EnsurePricePartitions: %s: %w", p.name, err)
+		}
+		created = append(created, p.name)
+	}
+	return created, nil
+}
+
+// Attaches the detached partition of `month` again with its archived rows. Rows of currencies
+// purged since are deleted first, and archived rows give way to the ones stored since in the
+// default partition
+func (r *GormRepo) AttachPricePartition(ctx context.Context, month time.Time) (string, error) {
+	p := newPricePartition(month.UTC())
+	existing, err := r.listPricePartitions(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gorm AttachPricePartition: %w", err)
+	}
+	for _, e := range existing {
+		if e.name == p.name {
+			return "", fmt.Errorf("gorm AttachPricePartition: %s is attached: %w", p.name, domain.ErrPartitionNotFound)
+		}
+	}
+	detached, err := r.tableExists(ctx, p.name)
+	if err != nil {
+		return "", fmt.Errorf("gorm AttachPricePartition: %s: %w", p.name, err)
+	}
+	if !detached {
+		return "", fmt.Errorf("gorm AttachPricePartition: %s: %w", p.name, domain.ErrPartitionNotFound)
+	}
+
+	lo, hi := p.start.Unix(), p.end.Unix()
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stmts := []string{
+			fmt.Sprintf(`
+				DELETE FROM %s t
+				WHERE NOT EXISTS (SELECT 1 FROM currencies c WHERE c.id = t.currency_id)`,
+				p.name),
+			fmt.Sprintf(`
+				DELETE FROM %s t
+				USING %s d
+				WHERE d.timestamp >= %d AND d.timestamp < %d
+				  AND t.currency_id = d.currency_id AND t.quote = d.quote AND t.timestamp = d.timestamp`,
+				p.name, pricePartitionDefault, lo, hi),
+		}
+		stmts = append(stmts, movePartitionStmts(p)...)
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("gorm AttachPricePartition: %s: %w", p.name, err)
+	}
+	return p.name, nil
+}
+
+// Removes the monthly partitions that end at or before `before`. Detaching is instant and keeps
+// the table around for archiving, otherwise it is dropped right away
+func (r *GormRepo) DropPricePartitions(ctx context.Context, before time.Time, detachOnly bool) ([]string, error) {
+	existing, err := r.listPricePartitions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gorm DropPricePartitions: %w", err)
+	}
+
+	var removed []string
+	for _, p := range existing {
+		if p.end.After(before) {
+			break // ordered by month
+		}
+		err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf(`ALTER TABLE currency_prices DETACH PARTITION %s`, p.name)).Error; err != nil {
+				return err
+			}
+			if detachOnly {
+				return nil
+			}
+			return tx.Exec(fmt.Sprintf(`DROP TABLE %s`, p.name)).Error
+		})
+		if err != nil {
+			return removed, fmt.Errorf("gorm DropPricePartitions: %s: %w", p.name, err)
+		}
+		removed = append(removed, p.name)
+	}
+	return removed, nil
+}
+
+// Monthly partitions currently attached to currency_prices, oldest first
+func (r *GormRepo) listPricePartitions(ctx context.Context) ([]pricePartition, error) {
+	var names []string
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'currency_prices'`,
+	).Scan(&names).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var parts []pricePartition
+	for _, name := range names {
+		suffix, ok := strings.CutPrefix(name, pricePartitionPrefix)
+		if !ok {
+			continue // default partition
+		}
+		month, err := time.Parse("200601", suffix)
+		if err != nil {
+			continue
+		}
+		parts = append(parts, newPricePartition(month))
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].start.Before(parts[j].start) })
+	return parts, nil
+}
+
+// Whether a table named `name` exists, e.g. a month left behind by a detach-only removal
+func (r *GormRepo) tableExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+	err := r.db.WithContext(ctx).Raw(`SELECT to_regclass(?) IS NOT NULL`, name).Scan(&exists).Error
+	return exists, err
+}
+
+// Builds the partition as a plain table, moves matching rows out of the default partition
+// and attaches it, all in one transaction so no row is ever visible twice
+func (r *GormRepo) createPricePartition(ctx context.Context, p pricePartition) error {
+	stmts := append([]string{
+		fmt.Sprintf(`CREATE TABLE %s (LIKE currency_prices INCLUDING DEFAULTS)`, p.name),
+	}, movePartitionStmts(p)...)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Moves the rows of the month out of the default partition into the table and attaches it
+func movePartitionStmts(p pricePartition) []string {
+	lo, hi := p.start.Unix(), p.end.Unix()
+	return []string{
+		fmt.Sprintf(`
+			WITH moved AS (
+				DELETE FROM %s WHERE timestamp >= %d AND timestamp < %d RETURNING *
+			)
+			INSERT INTO %s SELECT * FROM moved`,
+			pricePartitionDefault, lo, hi, p.name),
+		fmt.Sprintf(`ALTER TABLE currency_prices ATTACH PARTITION %s FOR VALUES FROM (%d) TO (%d)`, p.name, lo, hi),
+	}
+}
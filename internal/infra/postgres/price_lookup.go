@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Items resolved per statement, keeps bind params well below the Postgres limit of 65535
+const batchLookupChunk = 10000
+
+// One neighbour of a query item. Side and the snapshot columns are NULL
+// when nothing matched, TrackedID is NULL when the currency isn't tracked
+type neighbourRow struct {
+	Idx       int
+	TrackedID *uuid.UUID
+	Ambiguous bool // the symbol names several currencies
+	Side      *string
+	PriceSnapshotModel
+}
+
+// Snapshots around the requested time of one query item
+type neighbours struct {
+	tracked   bool
+	ambiguous bool
+	older     *domain.PriceSnapshot // latest at or before
+	newer     *domain.PriceSnapshot // earliest at or after
+}
+
+// Error of a query item whose currency didn't resolve, nil when it did
+func (nb neighbours) err() error {
+	switch {
+	case nb.ambiguous:
+		return domain.ErrAmbiguousCurrency
+	case !nb.tracked:
+		return domain.ErrNotTracked
+	}
+	return nil
+}
+
+// Resolves the price at `ts` according to `mode` in a single round trip:
+// the currency join and both neighbour probes run as one statement
+func (r *GormRepo) GetPriceSnapshot(ctx context.Context, ref, quote string, ts time.Time, mode domain.LookupMode) (*domain.PriceLookup, error) {
+	found, err := r.queryNeighbours(ctx, []domain.PriceQuery{{Symbol: ref, Quote: quote, At: ts}}, mode)
+	if err != nil {
+		return nil, fmt.Errorf("gorm GetPriceSnapshot: %w", err)
+	}
+	if err := found[0].err(); err != nil {
+		return nil, err
+	}
+	return domain.ResolvePrice(mode, ts, found[0].older, found[0].newer)
+}
+
+// Resolves many (symbol, quote, time) items with one set-based statement per chunk.
+// Each item probes idx_currency_prices_currency_quote_timestamp through a LATERAL join.
+// Results are aligned with `queries`, unknown or ambiguous currencies and empty series become per-item errors
+func (r *GormRepo) GetPriceSnapshots(ctx context.Context, queries []domain.PriceQuery, mode domain.LookupMode) ([]domain.PriceLookupResult, error) {
+	results := make([]domain.PriceLookupResult, len(queries))
+
+	for start := 0; start < len(queries); start += batchLookupChunk {
+		end := min(start+batchLookupChunk, len(queries))
+
+		found, err := r.queryNeighbours(ctx, queries[start:end], mode)
+		if err != nil {
+			return nil, fmt.Errorf("gorm GetPriceSnapshots: %w", err)
+		}
+
+		for j, nb := range found {
+			i := start + j
+			if err := nb.err(); err != nil {
+				results[i].Err = err
+				continue
+			}
+			results[i].Lookup, results[i].Err = domain.ResolvePrice(mode, queries[i].At, nb.older, nb.newer)
+		}
+	}
+	return results, nil
+}
+
+// Fetches the currency and the neighbouring snapshots of every query,
+// with one statement per retention tier. The result is aligned with `queries`
+func (r *GormRepo) queryNeighbours(ctx context.Context, queries []domain.PriceQuery, mode domain.LookupMode) ([]neighbours, error) {
+	tiers := make(map[time.Duration][]int)
+	for i, q := range queries {
+		tiers[q.Resolution] = append(tiers[q.Resolution], i)
+	}
+
+	found := make([]neighbours, len(queries))
+	for res, idxs := range tiers {
+		if err := r.queryTierNeighbours(ctx, queries, idxs, res, mode, found); err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+// Probes the neighbours of queries[idxs] in one tier: raw snapshots when `res` is 0,
+// otherwise the close of the rollups, which is the last snapshot of each bucket
+func (r *GormRepo) queryTierNeighbours(
+	ctx context.Context,
+	queries []domain.PriceQuery,
+	idxs []int,
+	res time.Duration,
+	mode domain.LookupMode,
+	found []neighbours,
+) error {
+	values := make([]string, len(idxs))
+	args := make([]interface{}, 0, len(idxs)*4)
+	for i, idx := range idxs {
+		q := queries[idx]
+		values[i] = "(?::int, ?::text, ?::text, ?::bigint)"
+		args = append(args, idx, q.Symbol, q.Quote, q.At.Unix())
+	}
+
+	older, newer := `(
+			SELECT 'older' AS side, p.*
+			FROM currency_prices p
+			WHERE p.currency_id = c.id AND p.quote = q.quote AND p.timestamp <= q.ts
+			ORDER BY p.timestamp DESC
+			LIMIT 1
+		)`, `(
+			SELECT 'newer' AS side, p.*
+			FROM currency_prices p
+			WHERE p.currency_id = c.id AND p.quote = q.quote AND p.timestamp >= q.ts
+			ORDER BY p.timestamp ASC
+			LIMIT 1
+		)`
+	if res > 0 {
+		// close_at grows with bucket, so ordering by the primary key is enough
+		size := int64(res / time.Second)
+		older = fmt.Sprintf(`(
+			SELECT 'older' AS side, r.currency_id, r.quote, r.close_at AS timestamp, r.close AS price, r.updated_at AS created_at
+			FROM currency_price_rollups r
+			WHERE r.currency_id = c.id AND r.quote = q.quote AND r.resolution = %d AND r.close_at <= q.ts
+			ORDER BY r.bucket DESC
+			LIMIT 1
+		)`, size)
+		newer = fmt.Sprintf(`(
+			SELECT 'newer' AS side, r.currency_id, r.quote, r.close_at AS timestamp, r.close AS price, r.updated_at AS created_at
+			FROM currency_price_rollups r
+			WHERE r.currency_id = c.id AND r.quote = q.quote AND r.resolution = %d AND r.close_at >= q.ts
+			ORDER BY r.bucket ASC
+			LIMIT 1
+		)`, size)
+	}
+
+	// Only probe the sides the mode needs
+	var probes []string
+	if mode.NeedsOlder() {
+		probes = append(probes, older)
+	}
+	if mode.NeedsNewer() {
+		probes = append(probes, newer)
+	}
+
+	// The currency is named by provider ID or symbol, an ID match wins over symbol matches
+	sql := fmt.Sprintf(`
+		WITH q (idx, ref, quote, ts) AS (VALUES %s)
+		SELECT q.idx, c.id AS tracked_id, COALESCE(c.matches > 1 AND NOT c.exact, FALSE) AS ambiguous, n.*
+		FROM q
+		LEFT JOIN LATERAL (
+			SELECT c.id, c.provider_id = lower(q.ref) AS exact, COUNT(*) OVER () AS matches
+			FROM currencies c
+			WHERE c.provider_id = lower(q.ref) OR c.symbol = upper(q.ref)
+			ORDER BY exact DESC
+			LIMIT 1
+		) c ON TRUE
+		LEFT JOIN LATERAL (%s) n ON TRUE
+		ORDER BY q.idx`,
+		strings.Join(values, ", "), strings.Join(probes, " UNION ALL "),
+	)
+
+	var rows []neighbourRow
+	if err := r.db.WithContext(ctx).Raw(sql, args...).Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		nb := &found[row.Idx]
+		nb.tracked = row.TrackedID != nil
+		nb.ambiguous = row.Ambiguous
+		if row.Side == nil {
+			continue
+		}
+		snap := row.toDomain()
+		snap.Resolution = res
+		if *row.Side == "older" {
+			nb.older = snap
+		} else {
+			nb.newer = snap
+		}
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/Neroframe/crypto-tracker/pkg/logger"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// DSN of a migrated, disposable database. Tests and benchmarks that need Postgres are skipped without it
+const testDSNEnv = "CRYPTO_TEST_DSN"
+
+const (
+	benchSymbol   = "ZZBENCH"
+	benchRows     = 3_000_000
+	benchInterval = time.Minute
+)
+
+func openTestRepo(tb testing.TB) *GormRepo {
+	tb.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		tb.Skipf("%s not set", testDSNEnv)
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		tb.Fatalf("connect: %v", err)
+	}
+	tb.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return NewGormRepo(db, logger.New(logger.Config{Level: "error"}))
+}
+
+// Seeds one currency with benchRows USD snapshots one benchInterval apart, ending now.
+// Everything seeded is removed when the benchmark ends, including the monthly partitions
+// created for it
+func seedPriceHistory(b *testing.B, r *GormRepo) (start, end time.Time) {
+	b.Helper()
+	ctx := context.Background()
+	end = time.Now().UTC().Truncate(benchInterval)
+	start = end.Add(-benchInterval * (benchRows - 1))
+
+	id := uuid.New()
+	var created []string
+	cleanup := func() {
+		r.db.Exec(`DELETE FROM currency_prices WHERE currency_id IN (SELECT id FROM currencies WHERE symbol = ?)`, benchSymbol)
+		r.db.Exec(`DELETE FROM currencies WHERE symbol = ?`, benchSymbol)
+		dropEmptyPartitions(b, r, created)
+	}
+	cleanup() // leftovers of an interrupted run
+	b.Cleanup(cleanup)
+
+	created, err := r.EnsurePricePartitions(ctx, start, end)
+	if err != nil {
+		b.Fatalf("partitions: %v", err)
+	}
+
+	err = r.db.Exec(`INSERT INTO currencies (id, symbol, provider_id) VALUES (?, ?, ?)`,
+		id, benchSymbol, "zzbench-benchmark").Error
+	if err != nil {
+		b.Fatalf("seed currency: %v", err)
+	}
+	err = r.db.Exec(`
+		INSERT INTO currency_prices (currency_id, quote, timestamp, price)
+		SELECT ?, 'USD', ts, 100 + (ts % 997) / 100.0
+		FROM generate_series(?::bigint, ?::bigint, ?::bigint) AS ts`,
+		id, start.Unix(), end.Unix(), int64(benchInterval/time.Second),
+	).Error
+	if err != nil {
+		b.Fatalf("seed prices: %v", err)
+	}
+	if err := r.db.Exec(`ANALYZE currency_prices`).Error; err != nil {
+		b.Fatalf("analyze: %v", err)
+	}
+	return start, end
+}
+
+// Drops the partitions a seed created once the seeded rows are gone. A partition that
+// received rows from elsewhere in the meantime is kept
+func dropEmptyPartitions(tb testing.TB, r *GormRepo, names []string) {
+	tb.Helper()
+	for _, name := range names {
+		var rows int64
+		if err := r.db.Raw(fmt.Sprintf(`SELECT COUNT(*) FROM %s`, name)).Scan(&rows).Error; err != nil || rows > 0 {
+			continue
+		}
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf(`ALTER TABLE currency_prices DETACH PARTITION %s`, name)).Error; err != nil {
+				return err
+			}
+			return tx.Exec(fmt.Sprintf(`DROP TABLE %s`, name)).Error
+		})
+		if err != nil {
+			tb.Logf("drop partition %s: %v", name, err)
+		}
+	}
+}
+
+// The lookup GetPriceSnapshot replaced: the currency and each neighbour in their own round trip
+func legacyGetPriceSnapshot(ctx context.Context, r *GormRepo, symbol, quote string, ts time.Time, mode domain.LookupMode) (*domain.PriceLookup, error) {
+	var cm CurrencyModel
+	if err := r.db.WithContext(ctx).Where("symbol = ?", symbol).First(&cm).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotTracked
+		}
+		return nil, err
+	}
+	tsUnix := ts.Unix()
+
+	var older, newer *domain.PriceSnapshot
+	if mode.NeedsOlder() {
+		var row PriceSnapshotModel
+		err := r.db.WithContext(ctx).
+			Where("currency_id = ? AND quote = ? AND timestamp <= ?", cm.ID, quote, tsUnix).
+			Order("timestamp DESC").Limit(1).First(&row).Error
+		switch {
+		case err == nil:
+			older = row.toDomain()
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	if mode.NeedsNewer() && (older == nil || older.Timestamp.Unix() != tsUnix) {
+		var row PriceSnapshotModel
+		err := r.db.WithContext(ctx).
+			Where("currency_id = ? AND quote = ? AND timestamp >= ?", cm.ID, quote, tsUnix).
+			Order("timestamp ASC").Limit(1).First(&row).Error
+		switch {
+		case err == nil:
+			newer = row.toDomain()
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+	}
+	return domain.ResolvePrice(mode, ts, older, newer)
+}
+
+// Compares the single-statement lookup with the per-neighbour queries it replaced.
+// Run with CRYPTO_TEST_DSN pointing at a migrated database:
+//
+//	go test ./internal/infra/postgres -run '^$' -bench GetPriceSnapshot -benchtime 5000x
+func BenchmarkGetPriceSnapshot(b *testing.B) {
+	r := openTestRepo(b)
+	start, end := seedPriceHistory(b, r)
+	ctx := context.Background()
+	span := end.Sub(start)
+
+	lookups := map[string]func(time.Time, domain.LookupMode) (*domain.PriceLookup, error){
+		"single": func(ts time.Time, mode domain.LookupMode) (*domain.PriceLookup, error) {
+			return r.GetPriceSnapshot(ctx, benchSymbol, domain.DefaultQuote, ts, mode)
+		},
+		"legacy": func(ts time.Time, mode domain.LookupMode) (*domain.PriceLookup, error) {
+			return legacyGetPriceSnapshot(ctx, r, benchSymbol, domain.DefaultQuote, ts, mode)
+		},
+	}
+	for _, mode := range []domain.LookupMode{domain.LookupBefore, domain.LookupNearest} {
+		for _, name := range []string{"single", "legacy"} {
+			lookup := lookups[name]
+			b.Run(string(mode)+"/"+name, func(b *testing.B) {
+				// Same random times for both paths, off the seeded grid so both neighbours are probed
+				rnd := rand.New(rand.NewSource(1))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					ts := start.Add(time.Duration(rnd.Int63n(int64(span)))).Truncate(time.Second)
+					if _, err := lookup(ts, mode); err != nil {
+						b.Fatalf("lookup at %s: %v", ts, err)
+					}
+				}
+			})
+		}
+	}
+}
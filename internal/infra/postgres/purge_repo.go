@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Lifecycle columns of a currency row, read while locked by the purge
+type purgeStateRow struct {
+	Status              string
+	PurgeToken          *string
+	PurgeTokenExpiresAt *time.Time
+}
+
+func (r *GormRepo) CountCurrencyData(ctx context.Context, currencyID uuid.UUID) (domain.PurgeCounts, error) {
+	var counts domain.PurgeCounts
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+		  (SELECT COUNT(*) FROM currency_prices WHERE currency_id = @id) AS prices,
+		  (SELECT COUNT(*) FROM currency_price_rollups WHERE currency_id = @id) AS rollups,
+		  (SELECT COUNT(*) FROM backfill_jobs WHERE currency_id = @id) AS backfill_jobs`,
+		map[string]interface{}{"id": currencyID},
+	).Scan(&counts).Error
+	if err != nil {
+		return domain.PurgeCounts{}, fmt.Errorf("gorm CountCurrencyData: %w", err)
+	}
+	return counts, nil
+}
+
+// Replaces any earlier token of the currency, updated_at is left alone
+func (r *GormRepo) SavePurgeToken(ctx context.Context, currencyID uuid.UUID, token string, expiresAt time.Time) error {
+	res := r.db.WithContext(ctx).
+		Model(&CurrencyModel{}).
+		Where("id = ?", currencyID).
+		UpdateColumns(map[string]interface{}{
+			"purge_token":            token,
+			"purge_token_expires_at": expiresAt.UTC(),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("gorm SavePurgeToken: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotTracked
+	}
+	return nil
+}
+
+// Deletes an archived currency with its prices, rollups and backfill jobs in one transaction.
+// The token must match the saved one and be unexpired at `now`, running backfills block the purge
+func (r *GormRepo) PurgeCurrency(ctx context.Context, currencyID uuid.UUID, token string, now time.Time) (domain.PurgeCounts, error) {
+	var counts domain.PurgeCounts
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var state purgeStateRow
+		res := tx.Raw(`
+			SELECT status, purge_token, purge_token_expires_at
+			FROM currencies
+			WHERE id = ?
+			FOR UPDATE`, currencyID).Scan(&state)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return domain.ErrNotTracked
+		}
+		if domain.CurrencyStatus(state.Status) != domain.CurrencyArchived {
+			return domain.ErrNotArchived
+		}
+		if state.PurgeToken == nil || state.PurgeTokenExpiresAt == nil ||
+			!now.Before(*state.PurgeTokenExpiresAt) ||
+			subtle.ConstantTimeCompare([]byte(*state.PurgeToken), []byte(token)) != 1 {
+			return domain.ErrInvalidPurgeToken
+		}
+
+		var active int64
+		if err := tx.Model(&BackfillJobModel{}).
+			Where("currency_id = ? AND status IN ?", currencyID,
+				[]string{string(domain.BackfillPending), string(domain.BackfillRunning)}).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return domain.ErrBackfillActive
+		}
+
+		res = tx.Where("currency_id = ?", currencyID).Delete(&PriceSnapshotModel{})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts.Prices = res.RowsAffected
+
+		res = tx.Where("currency_id = ?", currencyID).Delete(&PriceRollupModel{})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts.Rollups = res.RowsAffected
+
+		res = tx.Where("currency_id = ?", currencyID).Delete(&BackfillJobModel{})
+		if res.Error != nil {
+			return res.Error
+		}
+		counts.BackfillJobs = res.RowsAffected
+
+		return tx.Where("id = ?", currencyID).Delete(&CurrencyModel{}).Error
+	})
+	if err != nil {
+		return domain.PurgeCounts{}, fmt.Errorf("gorm PurgeCurrency: %w", err)
+	}
+	return counts, nil
+}
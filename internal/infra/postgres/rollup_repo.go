@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neroframe/crypto-tracker/internal/domain"
+	"github.com/google/uuid"
+)
+
+// Aggregates every complete `resolution` bucket in [since, until) from the source tier
+// (raw snapshots when source is 0) into currency_price_rollups. Buckets that already
+// exist are recomputed, so rerunning over the same range is safe
+func (r *GormRepo) RollupPrices(ctx context.Context, source, resolution time.Duration, since, until time.Time) (int64, error) {
+	size := int64(resolution / time.Second)
+	if size <= 0 {
+		return 0, domain.ErrInvalidInterval
+	}
+
+	// Coarser tiers merge the finer buckets: first open, last close, extremes and total count
+	selectSQL := `
+		SELECT currency_id, quote, ?::int AS resolution, (timestamp / ?) * ? AS rolled,
+		       (array_agg(price ORDER BY timestamp ASC))[1],
+		       MAX(price),
+		       MIN(price),
+		       (array_agg(price ORDER BY timestamp DESC))[1],
+		       MAX(timestamp),
+		       COUNT(*)
+		FROM currency_prices
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY currency_id, quote, rolled`
+	args := []interface{}{size, size, size, since.Unix(), until.Unix()}
+	if source > 0 {
+		selectSQL = `
+		SELECT currency_id, quote, ?::int AS resolution, (bucket / ?) * ? AS rolled,
+		       (array_agg(open ORDER BY bucket ASC))[1],
+		       MAX(high),
+		       MIN(low),
+		       (array_agg(close ORDER BY bucket DESC))[1],
+		       MAX(close_at),
+		       SUM(count)
+		FROM currency_price_rollups
+		WHERE resolution = ? AND bucket >= ? AND bucket < ?
+		GROUP BY currency_id, quote, rolled`
+		args = []interface{}{size, size, size, int64(source / time.Second), since.Unix(), until.Unix()}
+	}
+
+	res := r.db.WithContext(ctx).Exec(`
+		INSERT INTO currency_price_rollups (currency_id, quote, resolution, bucket, open, high, low, close, close_at, count)
+		`+selectSQL+`
+		ON CONFLICT (currency_id, quote, resolution, bucket) DO UPDATE SET
+			open = EXCLUDED.open,
+			high = EXCLUDED.high,
+			low = EXCLUDED.low,
+			close = EXCLUDED.close,
+			close_at = EXCLUDED.close_at,
+			count = EXCLUDED.count,
+			updated_at = NOW()`,
+		args...,
+	)
+	if res.Error != nil {
+		return 0, fmt.Errorf("gorm RollupPrices: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// Start of the newest stored bucket of a tier, zero when the tier is empty
+func (r *GormRepo) LatestRollup(ctx context.Context, resolution time.Duration) (time.Time, error) {
+	var latest *int64
+	err := r.db.WithContext(ctx).
+		Model(&PriceRollupModel{}).
+		Select("MAX(bucket)").
+		Where("resolution = ?", int64(resolution/time.Second)).
+		Scan(&latest).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("gorm LatestRollup: %w", err)
+	}
+	if latest == nil {
+		return time.Time{}, nil
+	}
+	return time.Unix(*latest, 0).UTC(), nil
+}
+
+// Returns rollups whose close falls in [start, end] ordered by bucket, at most `limit` rows when limit > 0
+func (r *GormRepo) ListRollups(
+	ctx context.Context,
+	currencyID uuid.UUID,
+	quote string,
+	resolution time.Duration,
+	start, end time.Time,
+	limit int,
+) ([]*domain.Candle, error) {
+	var rows []PriceRollupModel
+
+	q := r.db.WithContext(ctx).
+		Where("currency_id = ? AND quote = ? AND resolution = ? AND close_at BETWEEN ? AND ?",
+			currencyID, quote, int64(resolution/time.Second), start.Unix(), end.Unix()).
+		Order("bucket ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("gorm ListRollups: %w", err)
+	}
+
+	candles := make([]*domain.Candle, len(rows))
+	for i, rm := range rows {
+		candles[i] = rm.toDomain()
+	}
+	return candles, nil
+}
+
+// Deletes the buckets of a tier that start before `before`
+func (r *GormRepo) PruneRollups(ctx context.Context, resolution time.Duration, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("resolution = ? AND bucket < ?", int64(resolution/time.Second), before.Unix()).
+		Delete(&PriceRollupModel{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("gorm PruneRollups: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// Deletes raw snapshots older than `before` row by row. Whole expired months are
+// removed as partitions by the service beforehand, according to the partition settings
+func (r *GormRepo) PrunePriceSnapshots(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Where("timestamp < ?", before.Unix()).
+		Delete(&PriceSnapshotModel{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("gorm PrunePriceSnapshots: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,12 @@
+DELETE FROM currency_prices WHERE quote <> 'USD';
+
+DROP INDEX IF EXISTS idx_currency_prices_currency_quote_timestamp;
+
+ALTER TABLE currency_prices
+  DROP CONSTRAINT currency_prices_currency_id_quote_timestamp_key,
+  ADD CONSTRAINT currency_prices_currency_id_timestamp_key UNIQUE (currency_id, timestamp);
+
+ALTER TABLE currency_prices DROP COLUMN quote;
+
+CREATE INDEX idx_currency_prices_currency_timestamp
+  ON currency_prices (currency_id, timestamp);
@@ -0,0 +1,11 @@
+ALTER TABLE currency_prices
+  ADD COLUMN quote VARCHAR(10) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE currency_prices
+  DROP CONSTRAINT currency_prices_currency_id_timestamp_key,
+  ADD CONSTRAINT currency_prices_currency_id_quote_timestamp_key UNIQUE (currency_id, quote, timestamp);
+
+DROP INDEX IF EXISTS idx_currency_prices_currency_timestamp;
+
+CREATE INDEX idx_currency_prices_currency_quote_timestamp
+  ON currency_prices (currency_id, quote, timestamp);
@@ -0,0 +1,8 @@
+ALTER TABLE currency_prices
+  DROP COLUMN IF EXISTS volume_24h,
+  DROP COLUMN IF EXISTS market_cap,
+  DROP COLUMN IF EXISTS percent_change_1h,
+  DROP COLUMN IF EXISTS percent_change_24h,
+  DROP COLUMN IF EXISTS percent_change_7d,
+  DROP COLUMN IF EXISTS circulating_supply,
+  DROP COLUMN IF EXISTS rank;
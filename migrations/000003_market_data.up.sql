@@ -0,0 +1,8 @@
+ALTER TABLE currency_prices
+  ADD COLUMN volume_24h NUMERIC,
+  ADD COLUMN market_cap NUMERIC,
+  ADD COLUMN percent_change_1h DOUBLE PRECISION,
+  ADD COLUMN percent_change_24h DOUBLE PRECISION,
+  ADD COLUMN percent_change_7d DOUBLE PRECISION,
+  ADD COLUMN circulating_supply NUMERIC,
+  ADD COLUMN rank INTEGER;
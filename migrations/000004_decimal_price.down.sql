@@ -0,0 +1,2 @@
+ALTER TABLE currency_prices
+  ALTER COLUMN price TYPE NUMERIC(20,10);
@@ -0,0 +1,3 @@
+-- 20 fractional digits so sub-cent tokens (1e-12 and below) are stored exactly
+ALTER TABLE currency_prices
+  ALTER COLUMN price TYPE NUMERIC(40,20);
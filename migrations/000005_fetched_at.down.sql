@@ -0,0 +1,2 @@
+ALTER TABLE currency_prices
+  DROP COLUMN IF EXISTS fetched_at;
@@ -0,0 +1,5 @@
+ALTER TABLE currency_prices
+  ADD COLUMN fetched_at TIMESTAMPTZ;
+
+-- Earlier rows were stamped with the fetch time
+UPDATE currency_prices SET fetched_at = to_timestamp(timestamp);
@@ -0,0 +1,19 @@
+-- Downsampled prices of the retention tiers, one row per (currency, quote, tier, bucket)
+CREATE TABLE currency_price_rollups (
+  currency_id UUID NOT NULL REFERENCES currencies(id) ON DELETE CASCADE,
+  quote VARCHAR(10) NOT NULL,
+  resolution INTEGER NOT NULL, -- bucket size in seconds
+  bucket BIGINT NOT NULL, -- bucket start, Unix seconds
+  open NUMERIC(40,20) NOT NULL,
+  high NUMERIC(40,20) NOT NULL,
+  low NUMERIC(40,20) NOT NULL,
+  close NUMERIC(40,20) NOT NULL,
+  close_at BIGINT NOT NULL, -- timestamp of the last snapshot in the bucket
+  count INTEGER NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (currency_id, quote, resolution, bucket)
+);
+
+-- Rollup watermarks and pruning scan a whole tier
+CREATE INDEX idx_currency_price_rollups_resolution_bucket
+  ON currency_price_rollups (resolution, bucket);
@@ -0,0 +1,39 @@
+CREATE TABLE currency_prices_unpartitioned (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  currency_id UUID NOT NULL REFERENCES currencies(id) ON DELETE CASCADE,
+  quote VARCHAR(10) NOT NULL DEFAULT 'USD',
+  timestamp BIGINT NOT NULL, -- Unix seconds
+  price NUMERIC(40,20) NOT NULL,
+  volume_24h NUMERIC,
+  market_cap NUMERIC,
+  percent_change_1h DOUBLE PRECISION,
+  percent_change_24h DOUBLE PRECISION,
+  percent_change_7d DOUBLE PRECISION,
+  circulating_supply NUMERIC,
+  rank INTEGER,
+  fetched_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO currency_prices_unpartitioned (
+  id, currency_id, quote, timestamp, price,
+  volume_24h, market_cap, percent_change_1h, percent_change_24h, percent_change_7d,
+  circulating_supply, rank, fetched_at, created_at
+)
+SELECT
+  id, currency_id, quote, timestamp, price,
+  volume_24h, market_cap, percent_change_1h, percent_change_24h, percent_change_7d,
+  circulating_supply, rank, fetched_at, created_at
+FROM currency_prices;
+
+-- Drops every partition with it
+DROP TABLE currency_prices;
+
+ALTER TABLE currency_prices_unpartitioned RENAME TO currency_prices;
+ALTER TABLE currency_prices
+  RENAME CONSTRAINT currency_prices_unpartitioned_pkey TO currency_prices_pkey;
+ALTER TABLE currency_prices
+  ADD CONSTRAINT currency_prices_currency_id_quote_timestamp_key UNIQUE (currency_id, quote, timestamp);
+
+CREATE INDEX idx_currency_prices_currency_quote_timestamp
+  ON currency_prices (currency_id, quote, timestamp);
@@ -0,0 +1,69 @@
+-- Monthly range partitions of currency_prices on timestamp (Unix seconds).
+-- Partitions are named currency_prices_pYYYYMM, the app creates upcoming ones at runtime
+ALTER TABLE currency_prices RENAME TO currency_prices_unpartitioned;
+ALTER TABLE currency_prices_unpartitioned
+  RENAME CONSTRAINT currency_prices_pkey TO currency_prices_unpartitioned_pkey;
+ALTER TABLE currency_prices_unpartitioned
+  RENAME CONSTRAINT currency_prices_currency_id_quote_timestamp_key TO currency_prices_unpartitioned_currency_id_quote_timestamp_key;
+ALTER INDEX idx_currency_prices_currency_quote_timestamp
+  RENAME TO idx_currency_prices_unpartitioned_currency_quote_timestamp;
+
+-- Unique constraints of a partitioned table must include the partition key
+CREATE TABLE currency_prices (
+  id UUID NOT NULL DEFAULT gen_random_uuid(),
+  currency_id UUID NOT NULL REFERENCES currencies(id) ON DELETE CASCADE,
+  quote VARCHAR(10) NOT NULL DEFAULT 'USD',
+  timestamp BIGINT NOT NULL, -- Unix seconds
+  price NUMERIC(40,20) NOT NULL,
+  volume_24h NUMERIC,
+  market_cap NUMERIC,
+  percent_change_1h DOUBLE PRECISION,
+  percent_change_24h DOUBLE PRECISION,
+  percent_change_7d DOUBLE PRECISION,
+  circulating_supply NUMERIC,
+  rank INTEGER,
+  fetched_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT currency_prices_pkey PRIMARY KEY (id, timestamp),
+  CONSTRAINT currency_prices_currency_id_quote_timestamp_key UNIQUE (currency_id, quote, timestamp)
+) PARTITION BY RANGE (timestamp);
+
+CREATE INDEX idx_currency_prices_currency_quote_timestamp
+  ON currency_prices (currency_id, quote, timestamp);
+
+-- Catches rows outside every monthly partition, the app moves them out when it creates their month
+CREATE TABLE currency_prices_default PARTITION OF currency_prices DEFAULT;
+
+-- One partition per month since the oldest stored price, up to three months ahead
+DO $$
+DECLARE
+  m DATE;
+  last_month DATE := (date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '3 months')::date;
+BEGIN
+  SELECT date_trunc('month', to_timestamp(MIN(timestamp)) AT TIME ZONE 'UTC')::date
+    INTO m FROM currency_prices_unpartitioned;
+  m := LEAST(COALESCE(m, last_month), date_trunc('month', NOW() AT TIME ZONE 'UTC')::date);
+
+  WHILE m <= last_month LOOP
+    EXECUTE format(
+      'CREATE TABLE %I PARTITION OF currency_prices FOR VALUES FROM (%s) TO (%s)',
+      'currency_prices_p' || to_char(m, 'YYYYMM'),
+      extract(epoch FROM m::timestamp)::bigint,
+      extract(epoch FROM (m + INTERVAL '1 month')::timestamp)::bigint
+    );
+    m := (m + INTERVAL '1 month')::date;
+  END LOOP;
+END $$;
+
+INSERT INTO currency_prices (
+  id, currency_id, quote, timestamp, price,
+  volume_24h, market_cap, percent_change_1h, percent_change_24h, percent_change_7d,
+  circulating_supply, rank, fetched_at, created_at
+)
+SELECT
+  id, currency_id, quote, timestamp, price,
+  volume_24h, market_cap, percent_change_1h, percent_change_24h, percent_change_7d,
+  circulating_supply, rank, fetched_at, created_at
+FROM currency_prices_unpartitioned;
+
+DROP TABLE currency_prices_unpartitioned;
@@ -0,0 +1,18 @@
+-- Historical imports, cursor_ts is advanced after every stored page so jobs resume after a restart
+CREATE TABLE backfill_jobs (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  currency_id UUID NOT NULL REFERENCES currencies(id) ON DELETE CASCADE,
+  quote VARCHAR(10) NOT NULL,
+  start_ts BIGINT NOT NULL, -- Unix seconds
+  end_ts BIGINT NOT NULL, -- Unix seconds
+  interval_seconds INTEGER NOT NULL,
+  cursor_ts BIGINT NOT NULL, -- next timestamp to fetch
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  inserted BIGINT NOT NULL DEFAULT 0,
+  error TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_backfill_jobs_status
+  ON backfill_jobs (status);
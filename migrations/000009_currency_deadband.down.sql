@@ -0,0 +1,4 @@
+ALTER TABLE currencies
+  DROP CONSTRAINT IF EXISTS currencies_deadband_check,
+  DROP COLUMN IF EXISTS heartbeat_seconds,
+  DROP COLUMN IF EXISTS deadband_percent;
@@ -0,0 +1,9 @@
+-- Change-based storage: prices are only stored when they move by more than deadband_percent
+-- or heartbeat_seconds have passed. NULL stores every fetch
+ALTER TABLE currencies
+  ADD COLUMN deadband_percent NUMERIC,
+  ADD COLUMN heartbeat_seconds INTEGER,
+  ADD CONSTRAINT currencies_deadband_check CHECK (
+    (deadband_percent IS NULL AND heartbeat_seconds IS NULL)
+    OR (deadband_percent >= 0 AND heartbeat_seconds > 0)
+  );
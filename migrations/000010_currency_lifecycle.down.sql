@@ -0,0 +1,15 @@
+ALTER TABLE currency_price_rollups
+  DROP CONSTRAINT IF EXISTS currency_price_rollups_currency_id_fkey,
+  ADD CONSTRAINT currency_price_rollups_currency_id_fkey
+  FOREIGN KEY (currency_id) REFERENCES currencies(id) ON DELETE CASCADE;
+ALTER TABLE currency_prices
+  DROP CONSTRAINT IF EXISTS currency_prices_currency_id_fkey,
+  ADD CONSTRAINT currency_prices_currency_id_fkey
+  FOREIGN KEY (currency_id) REFERENCES currencies(id) ON DELETE CASCADE;
+
+-- Paused and archived currencies are fetched again after this
+ALTER TABLE currencies
+  DROP CONSTRAINT IF EXISTS currencies_status_check,
+  DROP COLUMN IF EXISTS purge_token_expires_at,
+  DROP COLUMN IF EXISTS purge_token,
+  DROP COLUMN IF EXISTS status;
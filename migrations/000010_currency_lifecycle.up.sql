@@ -0,0 +1,31 @@
+-- Currencies are paused or archived instead of deleted, archived ones keep their history
+-- until an explicit purge confirmed with purge_token
+ALTER TABLE currencies
+  ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active',
+  ADD COLUMN purge_token VARCHAR(64),
+  ADD COLUMN purge_token_expires_at TIMESTAMPTZ,
+  ADD CONSTRAINT currencies_status_check CHECK (status IN ('active', 'paused', 'archived'));
+
+-- Deleting a currency row no longer cascades into its prices, the purge removes them first
+DO $$
+DECLARE
+  fk RECORD;
+BEGIN
+  FOR fk IN
+    SELECT conrelid::regclass AS tbl, conname
+    FROM pg_constraint
+    WHERE contype = 'f'
+      AND confrelid = 'currencies'::regclass
+      AND conrelid IN ('currency_prices'::regclass, 'currency_price_rollups'::regclass)
+      AND conparentid = 0
+  LOOP
+    EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', fk.tbl, fk.conname);
+  END LOOP;
+END $$;
+
+ALTER TABLE currency_prices
+  ADD CONSTRAINT currency_prices_currency_id_fkey
+  FOREIGN KEY (currency_id) REFERENCES currencies(id) ON DELETE RESTRICT;
+ALTER TABLE currency_price_rollups
+  ADD CONSTRAINT currency_price_rollups_currency_id_fkey
+  FOREIGN KEY (currency_id) REFERENCES currencies(id) ON DELETE RESTRICT;
@@ -0,0 +1,4 @@
+ALTER TABLE currencies
+  DROP CONSTRAINT IF EXISTS currencies_schedule_check,
+  DROP COLUMN IF EXISTS priority,
+  DROP COLUMN IF EXISTS fetch_interval_seconds;
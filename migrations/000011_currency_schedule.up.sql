@@ -0,0 +1,9 @@
+-- Per-currency fetch schedule, a NULL interval uses the global fetchInterval.
+-- Higher priorities are fetched first when fetches queue up behind the rate limit
+ALTER TABLE currencies
+  ADD COLUMN fetch_interval_seconds INTEGER,
+  ADD COLUMN priority INTEGER NOT NULL DEFAULT 0,
+  ADD CONSTRAINT currencies_schedule_check CHECK (
+    (fetch_interval_seconds IS NULL OR fetch_interval_seconds BETWEEN 1 AND 86400)
+    AND priority BETWEEN 0 AND 100
+  );
@@ -0,0 +1,3 @@
+ALTER TABLE currencies
+  DROP CONSTRAINT IF EXISTS currencies_provider_id_key,
+  DROP COLUMN IF EXISTS provider_id;
@@ -0,0 +1,5 @@
+-- Provider coin ID identifies what a currency tracks, several coins can share a symbol.
+-- Rows from before get a placeholder in 000013, resolved by the service when it starts
+ALTER TABLE currencies
+  ADD COLUMN provider_id VARCHAR(100),
+  ADD CONSTRAINT currencies_provider_id_key UNIQUE (provider_id);
@@ -0,0 +1,8 @@
+-- Fails while several currencies share a symbol
+DROP INDEX IF EXISTS idx_currencies_symbol_provider_id;
+
+ALTER TABLE currencies
+  ADD CONSTRAINT currencies_symbol_key UNIQUE (symbol),
+  ALTER COLUMN provider_id DROP NOT NULL;
+
+UPDATE currencies SET provider_id = NULL WHERE provider_id LIKE 'legacy:%';
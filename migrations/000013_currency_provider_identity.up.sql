@@ -0,0 +1,12 @@
+-- The provider coin ID becomes the identity of a currency, several tracked coins can share a symbol.
+-- Currencies added before IDs were stored get a 'legacy:<symbol>' placeholder, the service replaces
+-- it with the coin their symbol resolved to when it starts, whatever their status
+UPDATE currencies SET provider_id = 'legacy:' || lower(symbol) WHERE provider_id IS NULL;
+
+ALTER TABLE currencies
+  ALTER COLUMN provider_id SET NOT NULL,
+  DROP CONSTRAINT IF EXISTS currencies_symbol_key;
+
+-- Symbol lookups and the keyset order of listings
+CREATE INDEX idx_currencies_symbol_provider_id
+  ON currencies (symbol, provider_id);